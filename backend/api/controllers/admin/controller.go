@@ -0,0 +1,334 @@
+package admin
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/api/serializers"
+	"doctor-manager-api/common/configure"
+	"doctor-manager-api/common/constants"
+	"doctor-manager-api/common/logging"
+	"doctor-manager-api/common/request"
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/hasher/password"
+	"doctor-manager-api/utilities/hasher/sha256"
+	"doctor-manager-api/utilities/local"
+	"doctor-manager-api/utilities/tool"
+)
+
+const invitationTokenLength = 32
+
+var (
+	cfg    = configure.GetConfig()
+	logger = logging.GetLogger()
+)
+
+type Controller interface {
+	ListAccounts(ctx *fiber.Ctx) error
+	DisableAccount(ctx *fiber.Ctx) error
+	EnableAccount(ctx *fiber.Ctx) error
+	ResetAccountPassword(ctx *fiber.Ctx) error
+	RevokeAccountSessions(ctx *fiber.Ctx) error
+	UnlockAccount(ctx *fiber.Ctx) error
+	ListLoginAttempts(ctx *fiber.Ctx) error
+	CreateInvitation(ctx *fiber.Ctx) error
+	ListInvitations(ctx *fiber.Ctx) error
+	DeleteInvitation(ctx *fiber.Ctx) error
+}
+
+type controller struct {
+}
+
+func New() Controller {
+	return &controller{}
+}
+
+func (ctrl *controller) ListAccounts(ctx *fiber.Ctx) error {
+	var requestBody serializers.AdminListAccountsBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	var (
+		errorChan    = make(chan error, 1)
+		totalChan    = make(chan int64, 1)
+		queryOption  = queries.NewOptions()
+		accountQuery = queries.NewAccount(ctx.Context())
+		pagination   = request.NewPagination(requestBody.Limit, requestBody.Page)
+	)
+	go func() {
+		total, err := accountQuery.GetTotalByQuery(requestBody.Query)
+		errorChan <- err
+		totalChan <- total
+	}()
+	queryOption.SetPagination(pagination)
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	queryOption.SetOnlyFields("created_at", "updated_at", "username", "email", "first_name", "last_name", "role", "is_disabled", "_id")
+	accounts, err := accountQuery.GetByQuery(requestBody.Query, queryOption)
+	if err != nil {
+		return err
+	}
+	if err = <-errorChan; err != nil {
+		return err
+	}
+	result := make([]serializers.AdminListAccountsResponseItem, len(accounts))
+	for i, account := range accounts {
+		result[i].CreatedAt = account.CreatedAt
+		result[i].UpdatedAt = account.UpdatedAt
+		result[i].Username = account.Username
+		result[i].Email = account.Email
+		result[i].FirstName = account.FirstName
+		result[i].LastName = account.LastName
+		result[i].Role = account.Role
+		result[i].Id = account.Id
+		result[i].IsDisabled = account.IsDisabled
+	}
+	pagination.SetTotal(<-totalChan)
+	return response.NewArrayWithPagination(ctx, result, pagination)
+}
+
+func (ctrl *controller) DisableAccount(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	if id == local.New(ctx).GetUser().Id {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: "Cannot disable your own account"})
+	}
+	if err = queries.NewAccount(ctx.Context()).UpdateIsDisabledById(id, true); err != nil {
+		return err
+	}
+	if err = queries.NewAuthToken(ctx.Context()).DeleteByAccountId(id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) EnableAccount(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	if err = queries.NewAccount(ctx.Context()).UpdateIsDisabledById(id, false); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) ResetAccountPassword(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	var requestBody serializers.AdminResetPasswordBodyValidate
+	if err = ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err = requestBody.Validate(); err != nil {
+		return err
+	}
+	passwordHash, err := password.New().Hash(requestBody.Password)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ResetAccountPassword").Str("functionInline", "password.New().Hash").Msg("admin-controller")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if err = queries.NewAccount(ctx.Context()).UpdatePasswordHashById(id, passwordHash); err != nil {
+		return err
+	}
+	if err = queries.NewAuthToken(ctx.Context()).DeleteByAccountId(id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) RevokeAccountSessions(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	if _, err = queries.NewAccount(ctx.Context()).GetById(id, queryOption); err != nil {
+		return err
+	}
+	if err = queries.NewAuthToken(ctx.Context()).DeleteByAccountId(id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) UnlockAccount(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("username", "email", "_id")
+	account, err := queries.NewAccount(ctx.Context()).GetById(id, queryOption)
+	if err != nil {
+		return err
+	}
+	// Failures against an unknown identity are keyed by the identity itself, clear those too.
+	if err = queries.NewLoginThrottle(ctx.Context()).DeleteByKeys(
+		constants.LoginThrottleKeyPrefixAccount+account.Id.Hex(),
+		constants.LoginThrottleKeyPrefixAccount+strings.ToLower(account.Username),
+		constants.LoginThrottleKeyPrefixAccount+strings.ToLower(account.Email),
+	); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) ListLoginAttempts(ctx *fiber.Ctx) error {
+	var requestBody serializers.AdminListLoginAttemptsBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	var (
+		errorChan    = make(chan error, 1)
+		totalChan    = make(chan int64, 1)
+		queryOption  = queries.NewOptions()
+		attemptQuery = queries.NewLoginAttempt(ctx.Context())
+		pagination   = request.NewPagination(requestBody.Limit, requestBody.Page)
+		filter       = queries.LoginAttemptFilterRequest{
+			AccountId:    requestBody.AccountId,
+			IpAddress:    requestBody.IpAddress,
+			IsFailedOnly: requestBody.IsFailedOnly,
+		}
+	)
+	go func() {
+		total, err := attemptQuery.GetTotalByFilter(filter)
+		errorChan <- err
+		totalChan <- total
+	}()
+	queryOption.SetPagination(pagination)
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	attempts, err := attemptQuery.GetByFilter(filter, queryOption)
+	if err != nil {
+		return err
+	}
+	if err = <-errorChan; err != nil {
+		return err
+	}
+	result := make([]serializers.AdminListLoginAttemptsResponseItem, len(attempts))
+	for i, attempt := range attempts {
+		result[i].CreatedAt = attempt.CreatedAt
+		result[i].AccountId = attempt.AccountId
+		result[i].Identity = attempt.Identity
+		result[i].IpAddress = attempt.IpAddress
+		result[i].UserAgent = attempt.UserAgent
+		result[i].Reason = attempt.Reason
+		result[i].Id = attempt.Id
+		result[i].IsSuccess = attempt.IsSuccess
+	}
+	pagination.SetTotal(<-totalChan)
+	return response.NewArrayWithPagination(ctx, result, pagination)
+}
+
+func (ctrl *controller) CreateInvitation(ctx *fiber.Ctx) error {
+	var requestBody serializers.AdminCreateInvitationBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	expiredAt := time.Now().Add(cfg.InvitationTimeout)
+	if requestBody.ExpiredAt != nil {
+		if !requestBody.ExpiredAt.After(time.Now()) {
+			return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: "Expired at must be in the future"})
+		}
+		expiredAt = *requestBody.ExpiredAt
+	}
+	token := tool.New().GenerateRandomString(invitationTokenLength)
+	invitation, err := queries.NewInvitation(ctx.Context()).CreateOne(models.Invitation{
+		ExpiredAt: expiredAt,
+		Email:     requestBody.Email,
+		TokenHash: sha256.New().EncodeToHexString(token),
+		CreatedBy: local.New(ctx).GetUser().Id,
+	})
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{
+		Code: fiber.StatusCreated,
+		Data: serializers.AdminCreateInvitationResponse{
+			ExpiredAt: invitation.ExpiredAt,
+			Email:     invitation.Email,
+			Token:     token,
+			Id:        invitation.Id,
+		},
+	})
+}
+
+func (ctrl *controller) ListInvitations(ctx *fiber.Ctx) error {
+	var requestBody serializers.AdminListInvitationsBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	var (
+		errorChan       = make(chan error, 1)
+		totalChan       = make(chan int64, 1)
+		queryOption     = queries.NewOptions()
+		invitationQuery = queries.NewInvitation(ctx.Context())
+		pagination      = request.NewPagination(requestBody.Limit, requestBody.Page)
+	)
+	go func() {
+		total, err := invitationQuery.GetTotal()
+		errorChan <- err
+		totalChan <- total
+	}()
+	queryOption.SetPagination(pagination)
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	queryOption.SetOnlyFields("created_at", "expired_at", "used_at", "used_by", "email", "created_by", "_id")
+	invitations, err := invitationQuery.GetAll(queryOption)
+	if err != nil {
+		return err
+	}
+	if err = <-errorChan; err != nil {
+		return err
+	}
+	result := make([]serializers.AdminListInvitationsResponseItem, len(invitations))
+	for i, invitation := range invitations {
+		result[i].CreatedAt = invitation.CreatedAt
+		result[i].ExpiredAt = invitation.ExpiredAt
+		result[i].UsedAt = invitation.UsedAt
+		result[i].UsedBy = invitation.UsedBy
+		result[i].Email = invitation.Email
+		result[i].CreatedBy = invitation.CreatedBy
+		result[i].Id = invitation.Id
+	}
+	pagination.SetTotal(<-totalChan)
+	return response.NewArrayWithPagination(ctx, result, pagination)
+}
+
+func (ctrl *controller) DeleteInvitation(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	if err = queries.NewInvitation(ctx.Context()).DeleteById(id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
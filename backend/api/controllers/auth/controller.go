@@ -2,20 +2,34 @@ package auth
 
 import (
 	"errors"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"doctor-manager-api/api/serializers"
 	"doctor-manager-api/common/configure"
+	"doctor-manager-api/common/constants"
 	"doctor-manager-api/common/logging"
 	"doctor-manager-api/common/response"
 	respErr "doctor-manager-api/common/response/error"
 	"doctor-manager-api/database/mongo/models"
 	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/hasher/password"
+	"doctor-manager-api/utilities/hasher/sha256"
 	"doctor-manager-api/utilities/jwt"
 	"doctor-manager-api/utilities/local"
+	"doctor-manager-api/utilities/oidc"
+	"doctor-manager-api/utilities/tool"
+)
+
+const (
+	oidcStateLength        = 32
+	oidcNonceLength        = 32
+	oidcCodeVerifierLength = 64
 )
 
 var (
@@ -29,13 +43,24 @@ type Controller interface {
 	RefreshToken(ctx *fiber.Ctx) error
 	GetProfile(ctx *fiber.Ctx) error
 	UpdateProfile(ctx *fiber.Ctx) error
+	Logout(ctx *fiber.Ctx) error
+	LogoutAll(ctx *fiber.Ctx) error
+	ListSessions(ctx *fiber.Ctx) error
+	RevokeSession(ctx *fiber.Ctx) error
+	ChangePassword(ctx *fiber.Ctx) error
+	GetJWKS(ctx *fiber.Ctx) error
+	OidcLogin(ctx *fiber.Ctx) error
+	OidcCallback(ctx *fiber.Ctx) error
 }
 
 type controller struct {
+	service serviceInterface
 }
 
 func New() Controller {
-	return &controller{}
+	return &controller{
+		service: newService(),
+	}
 }
 
 func (ctrl *controller) Register(ctx *fiber.Ctx) error {
@@ -46,26 +71,82 @@ func (ctrl *controller) Register(ctx *fiber.Ctx) error {
 	if err := requestBody.Validate(); err != nil {
 		return err
 	}
+	var (
+		err              error
+		isBootstrap      bool
+		isInviteRequired bool
+		accountQuery     = queries.NewAccount(ctx.Context())
+	)
+	switch cfg.RegistrationMode {
+	case constants.RegistrationModeOpen:
+		// The very first account of an open instance bootstraps it as admin, otherwise the
+		// admin comes from ADMIN_USERNAME at startup.
+		total, err := accountQuery.GetTotal()
+		if err != nil {
+			return err
+		}
+		isBootstrap = total == 0
+	case constants.RegistrationModeInvite:
+		if requestBody.InviteToken == "" {
+			return response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrInvitationRequired})
+		}
+		isInviteRequired = true
+	default:
+		return response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrRegistrationDisabled})
+	}
 	queryOption := queries.NewOptions()
 	queryOption.SetOnlyFields("_id")
-	accountQuery := queries.NewAccount(ctx.Context())
-	if _, err := accountQuery.GetByUsernameOrEmail(requestBody.Username, requestBody.Email, queryOption); err != nil {
+	if _, err = accountQuery.GetByUsernameOrEmail(requestBody.Username, requestBody.Email, queryOption); err != nil {
 		if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
 			return err
 		}
 	} else {
 		return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
 	}
-	password, err := bcrypt.GenerateFromPassword([]byte(requestBody.Password), bcrypt.DefaultCost)
+	passwordHash, err := password.New().Hash(requestBody.Password)
 	if err != nil {
-		logger.Error().Err(err).Str("functionInline", "bcrypt.GenerateFromPassword").Str("function", "Register")
+		logger.Error().Err(err).Str("function", "Register").Str("functionInline", "password.New().Hash").Msg("auth-controller")
 		return response.NewError(fiber.StatusInternalServerError)
 	}
+	var (
+		accountId       = primitive.NewObjectID()
+		role            = constants.AccountRoleMember
+		invitation      *models.Invitation
+		invitationQuery = queries.NewInvitation(ctx.Context())
+		bootstrapQuery  = queries.NewBootstrap(ctx.Context())
+	)
+	if isInviteRequired {
+		invitation, err = invitationQuery.ConsumeByTokenHashAndEmail(sha256.New().EncodeToHexString(requestBody.InviteToken), requestBody.Email, accountId)
+		if err != nil {
+			return err
+		}
+	}
+	if isBootstrap {
+		// Of concurrent first registrations, only the one claiming the bootstrap becomes admin.
+		_, err = bootstrapQuery.CreateOne(models.Bootstrap{Id: constants.BootstrapIdAdmin, AccountId: accountId})
+		if err == nil {
+			role = constants.AccountRoleAdmin
+		} else if e := new(response.Error); !errors.As(err, &e) || e.Code != fiber.StatusConflict {
+			return err
+		}
+	}
 	if _, err = accountQuery.CreateOne(models.Account{
 		Username:     requestBody.Username,
 		Email:        requestBody.Email,
-		PasswordHash: string(password),
+		PasswordHash: passwordHash,
+		Role:         role,
+		Id:           accountId,
 	}); err != nil {
+		if invitation != nil {
+			if releaseErr := invitationQuery.ReleaseById(invitation.Id); releaseErr != nil {
+				logger.Error().Err(releaseErr).Str("function", "Register").Str("functionInline", "invitationQuery.ReleaseById").Msg("auth-controller")
+			}
+		}
+		if role == constants.AccountRoleAdmin {
+			if releaseErr := bootstrapQuery.DeleteById(constants.BootstrapIdAdmin); releaseErr != nil {
+				logger.Error().Err(releaseErr).Str("function", "Register").Str("functionInline", "bootstrapQuery.DeleteById").Msg("auth-controller")
+			}
+		}
 		return err
 	}
 	return response.New(ctx, response.Options{
@@ -84,26 +165,80 @@ func (ctrl *controller) Login(ctx *fiber.Ctx) error {
 	if err := requestBody.Validate(); err != nil {
 		return err
 	}
+	var (
+		attempt = models.LoginAttempt{
+			Identity:  requestBody.Identity,
+			IpAddress: ctx.IP(),
+			UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		}
+		accountKey = constants.LoginThrottleKeyPrefixAccount + strings.ToLower(requestBody.Identity)
+		ipKey      = constants.LoginThrottleKeyPrefixIp + attempt.IpAddress
+	)
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("password_hash", "_id")
+	queryOption.SetOnlyFields("password_hash", "is_disabled", "_id")
 	account, err := queries.NewAccount(ctx.Context()).GetByUsernameOrEmail(requestBody.Identity, requestBody.Identity, queryOption)
 	if err != nil {
+		if e := new(response.Error); !errors.As(err, &e) || e.Code != fiber.StatusNotFound {
+			return err
+		}
+	} else {
+		accountKey = constants.LoginThrottleKeyPrefixAccount + account.Id.Hex()
+		attempt.AccountId = &account.Id
+	}
+	retryAfter, throttleErr := ctrl.service.GetLoginRetryAfter(ctx.Context(), accountKey, ipKey)
+	if throttleErr != nil {
+		return throttleErr
+	}
+	if retryAfter > 0 {
+		return ctrl.throttleLogin(ctx, attempt, retryAfter)
+	}
+	// Counted before the password is verified, the failure is taken back on success.
+	isOverLimit, throttleErr := ctrl.service.IncreaseLoginFailures(ctx.Context(), accountKey, ipKey)
+	if throttleErr != nil {
+		return throttleErr
+	}
+	if isOverLimit {
+		if retryAfter, throttleErr = ctrl.service.GetLoginRetryAfter(ctx.Context(), accountKey, ipKey); throttleErr != nil {
+			return throttleErr
+		}
+		return ctrl.throttleLogin(ctx, attempt, max(retryAfter, cfg.LoginDelayBase))
+	}
+	if account == nil {
+		attempt.Reason = constants.LoginAttemptReasonAccountNotFound
+		ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
 		return err
 	}
-	if err = bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(requestBody.Password)); err != nil {
+	passwordService := password.New()
+	isMatch, isRehashNeeded, err := passwordService.Verify(requestBody.Password, account.PasswordHash)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "Login").Str("functionInline", "passwordService.Verify").Msg("auth-controller")
+	}
+	if !isMatch {
+		attempt.Reason = constants.LoginAttemptReasonInvalidPassword
+		ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
 		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: "Invalid password"})
 	}
-	token, err := queries.NewAuthToken(ctx.Context()).CreateOne(models.AuthToken{
-		ExpiredAt: time.Now().Add(cfg.RefreshTokenTimeout),
-		AccountId: account.Id,
-	})
-	if err != nil {
+	// Only the account counter is reset, otherwise a valid login would clear the failures of a shared IP.
+	if err = ctrl.service.ResetLoginFailures(ctx.Context(), accountKey); err != nil {
 		return err
 	}
-	accessToken, refreshToken, err := jwt.GetGlobal().GeneratePairToken(token.Id.Hex(), cfg.AccessTokenTimeout, cfg.RefreshTokenTimeout)
+	if err = ctrl.service.DecreaseLoginFailures(ctx.Context(), ipKey); err != nil {
+		return err
+	}
+	if account.IsDisabled {
+		attempt.Reason = constants.LoginAttemptReasonAccountDisabled
+		ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
+		return response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrAccountDisabled})
+	}
+	attempt.Reason = constants.LoginAttemptReasonSuccess
+	attempt.IsSuccess = true
+	ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
+	if isRehashNeeded {
+		ctrl.service.UpgradePasswordHash(ctx.Context(), account.Id, requestBody.Password)
+	}
+	accessToken, refreshToken, err := ctrl.service.CreateSession(ctx.Context(), account.Id, ctx.Get(fiber.HeaderUserAgent), ctx.IP())
 	if err != nil {
-		logger.Error().Err(err).Str("functionInline", "jwt.GetGlobal().GeneratePairToken").Str("function", "Login").Msg("auth-controller")
-		return response.New(ctx, response.Options{Code: fiber.StatusInternalServerError})
+		return err
 	}
 	return response.New(ctx, response.Options{
 		Data: serializers.AuthLoginResponse{
@@ -113,6 +248,13 @@ func (ctrl *controller) Login(ctx *fiber.Ctx) error {
 	})
 }
 
+func (ctrl *controller) throttleLogin(ctx *fiber.Ctx, attempt models.LoginAttempt, retryAfter time.Duration) error {
+	attempt.Reason = constants.LoginAttemptReasonThrottled
+	ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return response.NewError(fiber.StatusTooManyRequests, response.ErrorOptions{Data: respErr.ErrTooManyLoginAttempts})
+}
+
 func (ctrl *controller) RefreshToken(ctx *fiber.Ctx) error {
 	localService := local.New(ctx)
 	tokenQuery := queries.NewAuthToken(ctx.Context())
@@ -121,6 +263,8 @@ func (ctrl *controller) RefreshToken(ctx *fiber.Ctx) error {
 	}
 	token, err := tokenQuery.CreateOne(models.AuthToken{
 		ExpiredAt: time.Now().Add(cfg.RefreshTokenTimeout),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IpAddress: ctx.IP(),
 		AccountId: localService.GetUser().Id,
 	})
 	if err != nil {
@@ -148,6 +292,8 @@ func (ctrl *controller) GetProfile(ctx *fiber.Ctx) error {
 			FirstName: account.FirstName,
 			LastName:  account.LastName,
 			Avatar:    account.Avatar,
+			Role:      account.Role,
+			Groups:    account.Groups,
 		},
 	})
 }
@@ -171,3 +317,203 @@ func (ctrl *controller) UpdateProfile(ctx *fiber.Ctx) error {
 		"success": true,
 	}})
 }
+
+func (ctrl *controller) Logout(ctx *fiber.Ctx) error {
+	if err := queries.NewAuthToken(ctx.Context()).DeleteById(local.New(ctx).GetTokenId()); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{
+		"success": true,
+	}})
+}
+
+func (ctrl *controller) LogoutAll(ctx *fiber.Ctx) error {
+	if err := queries.NewAuthToken(ctx.Context()).DeleteByAccountId(local.New(ctx).GetUser().Id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{
+		"success": true,
+	}})
+}
+
+func (ctrl *controller) ListSessions(ctx *fiber.Ctx) error {
+	localService := local.New(ctx)
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("created_at", "expired_at", "user_agent", "ip_address", "_id")
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	tokens, err := queries.NewAuthToken(ctx.Context()).GetActiveByAccountId(localService.GetUser().Id, queryOption)
+	if err != nil {
+		return err
+	}
+	currentTokenId := localService.GetTokenId()
+	result := make([]serializers.AuthListSessionsResponseItem, len(tokens))
+	for i, token := range tokens {
+		result[i].CreatedAt = token.CreatedAt
+		result[i].ExpiredAt = token.ExpiredAt
+		result[i].UserAgent = token.UserAgent
+		result[i].IpAddress = token.IpAddress
+		result[i].Id = token.Id
+		result[i].IsCurrent = token.Id == currentTokenId
+	}
+	return response.New(ctx, response.Options{Data: result})
+}
+
+func (ctrl *controller) RevokeSession(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	if err = queries.NewAuthToken(ctx.Context()).DeleteByIdAndAccountId(id, local.New(ctx).GetUser().Id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{
+		"success": true,
+	}})
+}
+
+func (ctrl *controller) ChangePassword(ctx *fiber.Ctx) error {
+	var requestBody serializers.AuthChangePasswordBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	localService := local.New(ctx)
+	accountQuery := queries.NewAccount(ctx.Context())
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("password_hash", "_id")
+	account, err := accountQuery.GetById(localService.GetUser().Id, queryOption)
+	if err != nil {
+		return err
+	}
+	passwordService := password.New()
+	isMatch, _, err := passwordService.Verify(requestBody.CurrentPassword, account.PasswordHash)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ChangePassword").Str("functionInline", "passwordService.Verify").Msg("auth-controller")
+	}
+	if !isMatch {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: "Invalid password"})
+	}
+	passwordHash, err := passwordService.Hash(requestBody.NewPassword)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ChangePassword").Str("functionInline", "passwordService.Hash").Msg("auth-controller")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if err = accountQuery.UpdatePasswordHashById(account.Id, passwordHash); err != nil {
+		return err
+	}
+	if err = queries.NewAuthToken(ctx.Context()).DeleteByAccountIdExceptId(account.Id, localService.GetTokenId()); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{
+		"success": true,
+	}})
+}
+
+// GetJWKS answers with a bare JWK set instead of the usual response envelope, so
+// standard JWT verifiers can consume it.
+func (ctrl *controller) GetJWKS(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(jwt.GetGlobal().GetJWKS())
+}
+
+// OidcLogin starts a single sign-on flow. The client navigates to the returned URL and
+// posts the code and state it receives on OIDC_REDIRECT_URL to OidcCallback.
+func (ctrl *controller) OidcLogin(ctx *fiber.Ctx) error {
+	if !cfg.OidcEnable {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrOidcDisabled})
+	}
+	toolService := tool.New()
+	var (
+		state        = toolService.GenerateRandomString(oidcStateLength)
+		nonce        = toolService.GenerateRandomString(oidcNonceLength)
+		codeVerifier = toolService.GenerateRandomString(oidcCodeVerifierLength)
+	)
+	authorizationUrl, err := oidc.GetGlobal().AuthCodeURL(state, nonce, codeVerifier)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "OidcLogin").Str("functionInline", "oidc.GetGlobal().AuthCodeURL").Msg("auth-controller")
+		return response.NewError(fiber.StatusBadGateway, response.ErrorOptions{Data: respErr.ErrOidcProviderFailed})
+	}
+	if _, err = queries.NewOidcState(ctx.Context()).CreateOne(models.OidcState{
+		ExpiredAt:    time.Now().Add(cfg.OidcStateTimeout),
+		StateHash:    sha256.New().EncodeToHexString(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{
+		Data: serializers.AuthOidcLoginResponse{
+			AuthorizationUrl: authorizationUrl,
+		},
+	})
+}
+
+func (ctrl *controller) OidcCallback(ctx *fiber.Ctx) error {
+	if !cfg.OidcEnable {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrOidcDisabled})
+	}
+	var requestBody serializers.AuthOidcCallbackBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	state, err := queries.NewOidcState(ctx.Context()).ConsumeByStateHash(sha256.New().EncodeToHexString(requestBody.State))
+	if err != nil {
+		return err
+	}
+	oidcService := oidc.GetGlobal()
+	token, err := oidcService.Exchange(requestBody.Code, state.CodeVerifier)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "OidcCallback").Str("functionInline", "oidcService.Exchange").Msg("auth-controller")
+		return response.NewError(fiber.StatusBadGateway, response.ErrorOptions{Data: respErr.ErrOidcProviderFailed})
+	}
+	claims, err := oidcService.VerifyIdToken(token.IdToken, state.Nonce)
+	if err != nil {
+		logger.Warn().Err(err).Str("function", "OidcCallback").Str("functionInline", "oidcService.VerifyIdToken").Msg("auth-controller")
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrOidcTokenInvalid})
+	}
+	attempt := models.LoginAttempt{
+		Identity:  claims.Email,
+		IpAddress: ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	}
+	if attempt.Identity == "" {
+		attempt.Identity = claims.Subject
+	}
+	if !ctrl.service.IsOidcGroupAllowed(claims.Groups) {
+		attempt.Reason = constants.LoginAttemptReasonOidcDenied
+		ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
+		return response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrOidcGroupNotAllowed})
+	}
+	account, err := ctrl.service.ProvisionOidcAccount(ctx.Context(), claims)
+	if err != nil {
+		attempt.Reason = constants.LoginAttemptReasonOidcDenied
+		ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
+		return err
+	}
+	attempt.AccountId = &account.Id
+	if account.IsDisabled {
+		attempt.Reason = constants.LoginAttemptReasonAccountDisabled
+		ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
+		return response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrAccountDisabled})
+	}
+	attempt.Reason = constants.LoginAttemptReasonOidcSuccess
+	attempt.IsSuccess = true
+	ctrl.service.RecordLoginAttempt(ctx.Context(), attempt)
+	accessToken, refreshToken, err := ctrl.service.CreateSession(ctx.Context(), account.Id, attempt.UserAgent, attempt.IpAddress)
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{
+		Data: serializers.AuthLoginResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		},
+	})
+}
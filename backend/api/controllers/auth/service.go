@@ -1,6 +1,41 @@
 package auth
 
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/common/constants"
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/hasher/password"
+	"doctor-manager-api/utilities/jwt"
+	"doctor-manager-api/utilities/oidc"
+	"doctor-manager-api/utilities/tool"
+)
+
+const (
+	oidcUsernameMaxAttempts  = 3
+	oidcUsernameSuffixLength = 4
+)
+
 type serviceInterface interface {
+	GetLoginRetryAfter(ctx context.Context, keys ...string) (retryAfter time.Duration, err error)
+	IncreaseLoginFailures(ctx context.Context, accountKey, ipKey string) (isOverLimit bool, err error)
+	DecreaseLoginFailures(ctx context.Context, ipKey string) error
+	ResetLoginFailures(ctx context.Context, keys ...string) error
+	RecordLoginAttempt(ctx context.Context, attempt models.LoginAttempt)
+	UpgradePasswordHash(ctx context.Context, accountId primitive.ObjectID, plainPassword string)
+	CreateSession(ctx context.Context, accountId primitive.ObjectID, userAgent, ipAddress string) (accessToken, refreshToken string, err error)
+	IsOidcGroupAllowed(groups []string) bool
+	ProvisionOidcAccount(ctx context.Context, claims *oidc.Claims) (account *models.Account, err error)
 }
 
 type service struct{}
@@ -8,3 +43,229 @@ type service struct{}
 func newService() serviceInterface {
 	return &service{}
 }
+
+// GetLoginRetryAfter returns how long the caller must wait before trying again, the
+// longest of the lockouts and progressive delays among the given throttle keys.
+func (s *service) GetLoginRetryAfter(ctx context.Context, keys ...string) (time.Duration, error) {
+	throttles, err := queries.NewLoginThrottle(ctx).GetByKeys(keys)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		retryAfter  time.Duration
+		currentTime = time.Now()
+	)
+	for _, throttle := range throttles {
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(currentTime) {
+			retryAfter = max(retryAfter, throttle.LockedUntil.Sub(currentTime))
+			continue
+		}
+		if throttle.Failures <= 0 || currentTime.Sub(throttle.LastFailedAt) > cfg.LoginFailureWindow {
+			continue
+		}
+		delay := cfg.LoginDelayBase
+		for i := 1; i < throttle.Failures && delay < cfg.LoginDelayMax; i++ {
+			delay *= 2
+		}
+		delay = min(delay, cfg.LoginDelayMax)
+		if wait := throttle.LastFailedAt.Add(delay).Sub(currentTime); wait > 0 {
+			retryAfter = max(retryAfter, wait)
+		}
+	}
+	return retryAfter, nil
+}
+
+// IncreaseLoginFailures counts the attempt on both keys before its password is verified, and
+// tells whether either counter went over its maximum. The counters are read back from the
+// atomic increments, so parallel guesses beyond the limit are refused even though they all
+// passed GetLoginRetryAfter.
+func (s *service) IncreaseLoginFailures(ctx context.Context, accountKey, ipKey string) (bool, error) {
+	throttleQuery := queries.NewLoginThrottle(ctx)
+	accountThrottle, err := throttleQuery.IncreaseFailuresByKey(accountKey, queries.LoginThrottleIncreaseFailuresByKeyRequest{
+		Window:      cfg.LoginFailureWindow,
+		Lockout:     cfg.LoginLockoutDuration,
+		MaxFailures: cfg.LoginMaxFailures,
+	})
+	if err != nil {
+		return false, err
+	}
+	ipThrottle, err := throttleQuery.IncreaseFailuresByKey(ipKey, queries.LoginThrottleIncreaseFailuresByKeyRequest{
+		Window:      cfg.LoginFailureWindow,
+		Lockout:     cfg.LoginLockoutDuration,
+		MaxFailures: cfg.LoginMaxFailuresPerIp,
+	})
+	if err != nil {
+		return false, err
+	}
+	return accountThrottle.Failures > cfg.LoginMaxFailures || ipThrottle.Failures > cfg.LoginMaxFailuresPerIp, nil
+}
+
+// DecreaseLoginFailures takes back the failure counted on the IP for a successful attempt,
+// a valid login must not count against the other users of a shared IP.
+func (s *service) DecreaseLoginFailures(ctx context.Context, ipKey string) error {
+	return queries.NewLoginThrottle(ctx).DecreaseFailuresByKey(ipKey, cfg.LoginMaxFailuresPerIp)
+}
+
+func (s *service) ResetLoginFailures(ctx context.Context, keys ...string) error {
+	return queries.NewLoginThrottle(ctx).DeleteByKeys(keys...)
+}
+
+// RecordLoginAttempt only logs on failure, an audit write must never block a login.
+func (s *service) RecordLoginAttempt(ctx context.Context, attempt models.LoginAttempt) {
+	if _, err := queries.NewLoginAttempt(ctx).CreateOne(attempt); err != nil {
+		logger.Error().Err(err).Str("function", "RecordLoginAttempt").Str("functionInline", "queries.NewLoginAttempt(ctx).CreateOne").Msg("auth-service")
+	}
+}
+
+// UpgradePasswordHash rehashes a password stored with an outdated algorithm. It runs
+// after a successful login, so failures are only logged and retried on the next one.
+func (s *service) UpgradePasswordHash(ctx context.Context, accountId primitive.ObjectID, plainPassword string) {
+	passwordHash, err := password.New().Hash(plainPassword)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UpgradePasswordHash").Str("functionInline", "password.New().Hash").Msg("auth-service")
+		return
+	}
+	if err = queries.NewAccount(ctx).UpdatePasswordHashById(accountId, passwordHash); err != nil {
+		logger.Error().Err(err).Str("function", "UpgradePasswordHash").Str("functionInline", "queries.NewAccount(ctx).UpdatePasswordHashById").Msg("auth-service")
+	}
+}
+
+func (s *service) CreateSession(ctx context.Context, accountId primitive.ObjectID, userAgent, ipAddress string) (string, string, error) {
+	token, err := queries.NewAuthToken(ctx).CreateOne(models.AuthToken{
+		ExpiredAt: time.Now().Add(cfg.RefreshTokenTimeout),
+		UserAgent: userAgent,
+		IpAddress: ipAddress,
+		AccountId: accountId,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	accessToken, refreshToken, err := jwt.GetGlobal().GeneratePairToken(token.Id.Hex(), cfg.AccessTokenTimeout, cfg.RefreshTokenTimeout)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "CreateSession").Str("functionInline", "jwt.GetGlobal().GeneratePairToken").Msg("auth-service")
+		return "", "", response.NewError(fiber.StatusInternalServerError)
+	}
+	return accessToken, refreshToken, nil
+}
+
+// IsOidcGroupAllowed is always true when OIDC_ALLOWED_GROUPS is empty.
+func (s *service) IsOidcGroupAllowed(groups []string) bool {
+	if len(cfg.OidcAllowedGroups) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(groups, func(group string) bool {
+		return slices.Contains(cfg.OidcAllowedGroups, group)
+	})
+}
+
+// ProvisionOidcAccount resolves the account of a verified identity. It is found by
+// issuer and subject first, then linked by verified email, and created otherwise.
+// Groups and the mapped role are synchronised on every sign-in.
+func (s *service) ProvisionOidcAccount(ctx context.Context, claims *oidc.Claims) (*models.Account, error) {
+	accountQuery := queries.NewAccount(ctx)
+	identity := queries.AccountUpdateOidcIdentityByIdRequest{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Role:    oidc.Role(claims.Groups, cfg.OidcAdminGroups),
+		Groups:  claims.Groups,
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id", "role", "oidc_subject", "is_disabled")
+	account, err := accountQuery.GetByOidcIdentity(claims.Issuer, claims.Subject, queryOption)
+	if err == nil {
+		return s.syncOidcIdentity(ctx, account, identity)
+	}
+	if e := new(response.Error); !errors.As(err, &e) || e.Code != fiber.StatusNotFound {
+		return nil, err
+	}
+	if claims.Email == "" {
+		return nil, response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrOidcEmailMissing})
+	}
+	account, err = accountQuery.GetByEmail(claims.Email, queryOption)
+	if err == nil {
+		if !claims.IsEmailVerified {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrOidcEmailNotVerified})
+		}
+		if account.OidcSubject != "" {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrOidcAccountLinked})
+		}
+		return s.syncOidcIdentity(ctx, account, identity)
+	}
+	if e := new(response.Error); !errors.As(err, &e) || e.Code != fiber.StatusNotFound {
+		return nil, err
+	}
+	total, err := accountQuery.GetTotal()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		accountId      = primitive.NewObjectID()
+		role           = identity.Role
+		isBootstrap    = false
+		bootstrapQuery = queries.NewBootstrap(ctx)
+	)
+	if total == 0 {
+		// Of concurrent first sign-ins, only the one claiming the bootstrap becomes admin.
+		_, err = bootstrapQuery.CreateOne(models.Bootstrap{Id: constants.BootstrapIdAdmin, AccountId: accountId})
+		if err == nil {
+			role, isBootstrap = constants.AccountRoleAdmin, true
+		} else if e := new(response.Error); !errors.As(err, &e) || e.Code != fiber.StatusConflict {
+			return nil, err
+		}
+	}
+	if role == "" {
+		role = constants.AccountRoleMember
+	}
+	newAccount := models.Account{
+		Id:          accountId,
+		Username:    oidcUsername(claims),
+		Email:       claims.Email,
+		FirstName:   claims.GivenName,
+		LastName:    claims.FamilyName,
+		Avatar:      claims.Picture,
+		Role:        role,
+		OidcIssuer:  claims.Issuer,
+		OidcSubject: claims.Subject,
+		Groups:      claims.Groups,
+	}
+	// Usernames are free-form at the provider, so a clash is retried with a random suffix.
+	baseUsername := newAccount.Username
+	for i := 0; ; i++ {
+		account, err = accountQuery.CreateOne(newAccount)
+		if err == nil {
+			return account, nil
+		}
+		if e := new(response.Error); !errors.As(err, &e) || e.Code != fiber.StatusConflict || i+1 >= oidcUsernameMaxAttempts {
+			if isBootstrap {
+				if releaseErr := bootstrapQuery.DeleteById(constants.BootstrapIdAdmin); releaseErr != nil {
+					logger.Error().Err(releaseErr).Str("function", "ProvisionOidcAccount").Str("functionInline", "bootstrapQuery.DeleteById").Msg("auth-service")
+				}
+			}
+			return nil, err
+		}
+		newAccount.Username = baseUsername + "-" + strings.ToLower(tool.New().GenerateRandomString(oidcUsernameSuffixLength))
+	}
+}
+
+func (s *service) syncOidcIdentity(ctx context.Context, account *models.Account, identity queries.AccountUpdateOidcIdentityByIdRequest) (*models.Account, error) {
+	if err := queries.NewAccount(ctx).UpdateOidcIdentityById(account.Id, identity); err != nil {
+		return nil, err
+	}
+	account.OidcIssuer = identity.Issuer
+	account.OidcSubject = identity.Subject
+	account.Groups = identity.Groups
+	if identity.Role != "" {
+		account.Role = identity.Role
+	}
+	return account, nil
+}
+
+func oidcUsername(claims *oidc.Claims) string {
+	if claims.PreferredUsername != "" {
+		return claims.PreferredUsername
+	}
+	if localPart, _, ok := strings.Cut(claims.Email, "@"); ok && localPart != "" {
+		return localPart
+	}
+	return claims.Subject
+}
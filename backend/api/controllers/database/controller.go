@@ -2,11 +2,16 @@ package database
 
 import (
 	"errors"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"doctor-manager-api/api/serializers"
+	"doctor-manager-api/common/configure"
+	"doctor-manager-api/common/constants"
 	"doctor-manager-api/common/logging"
 	"doctor-manager-api/common/request"
 	"doctor-manager-api/common/response"
@@ -14,9 +19,13 @@ import (
 	"doctor-manager-api/database/mongo/models"
 	"doctor-manager-api/database/mongo/queries"
 	"doctor-manager-api/utilities/mongodb"
+	"doctor-manager-api/utilities/schema"
 )
 
-var logger = logging.GetLogger()
+var (
+	logger = logging.GetLogger()
+	cfg    = configure.GetConfig()
+)
 
 type Controller interface {
 	Get(ctx *fiber.Ctx) error
@@ -25,16 +34,29 @@ type Controller interface {
 	Update(ctx *fiber.Ctx) error
 	Delete(ctx *fiber.Ctx) error
 	ListCollections(ctx *fiber.Ctx) error
+	ListLiveCollections(ctx *fiber.Ctx) error
+	AdoptCollection(ctx *fiber.Ctx) error
+	SampleSchemas(ctx *fiber.Ctx) error
 	CreateCollection(ctx *fiber.Ctx) error
 	UpdateCollection(ctx *fiber.Ctx) error
 	DeleteCollection(ctx *fiber.Ctx) error
+	GetCluster(ctx *fiber.Ctx) error
+	CreateCluster(ctx *fiber.Ctx) error
+	ListClusters(ctx *fiber.Ctx) error
+	UpdateCluster(ctx *fiber.Ctx) error
+	DeleteCluster(ctx *fiber.Ctx) error
+	ListClusterDatabases(ctx *fiber.Ctx) error
+	ImportClusterDatabases(ctx *fiber.Ctx) error
 }
 
 type controller struct {
+	service serviceInterface
 }
 
 func New() Controller {
-	return &controller{}
+	return &controller{
+		service: newService(),
+	}
 }
 
 func (ctrl *controller) Get(ctx *fiber.Ctx) error {
@@ -43,7 +65,7 @@ func (ctrl *controller) Get(ctx *fiber.Ctx) error {
 		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
 	}
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("created_at", "updated_at", "name", "description", "uri", "db_name", "_id")
+	queryOption.SetOnlyFields("created_at", "updated_at", "name", "description", "uri", "db_name", "tls", "auth", "_id")
 	database, err := queries.NewDatabase(ctx.Context()).GetById(id, queryOption)
 	if err != nil {
 		return err
@@ -51,6 +73,9 @@ func (ctrl *controller) Get(ctx *fiber.Ctx) error {
 	return response.New(ctx, response.Options{Data: serializers.DatabaseGetResponse{
 		CreatedAt:   database.CreatedAt,
 		UpdatedAt:   database.UpdatedAt,
+		Tls:         newTlsResponse(database.Tls),
+		Auth:        newAuthResponse(database.Auth),
+		ClusterId:   database.ClusterId,
 		Name:        database.Name,
 		Description: database.Description,
 		Uri:         database.Uri,
@@ -77,13 +102,20 @@ func (ctrl *controller) Create(ctx *fiber.Ctx) error {
 	} else {
 		return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
 	}
+	connection, dialUri, connectOption, err := ctrl.service.BuildDatabaseConnection(ctx.Context(), requestBody.ClusterId, requestBody.Uri, requestBody.Tls, requestBody.Auth, nil)
+	if err != nil {
+		return err
+	}
 	var indexes []models.Index
 	if requestBody.IsTestConnection || requestBody.IsSyncIndex {
-		dbClient, err := mongodb.New(requestBody.Uri)
+		dbClient, err := mongodb.New(dialUri, connectOption)
 		if err != nil {
 			logger.Error().Err(err).Str("function", "Create").Str("functionInline", "mongodb.New").Msg("database-controller")
 			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
 		}
+		defer func() {
+			_ = dbClient.Disconnect()
+		}()
 		if requestBody.IsSyncIndex {
 			clientIndexes, err := dbClient.GetIndexesByDbName(requestBody.DBName)
 			if err != nil {
@@ -92,49 +124,18 @@ func (ctrl *controller) Create(ctx *fiber.Ctx) error {
 			}
 			indexes = make([]models.Index, 0, len(clientIndexes))
 			for _, index := range clientIndexes {
-				keys := make([]models.IndexKey, 0, len(index.Keys))
-				for _, key := range index.Keys {
-					keys = append(keys, models.IndexKey{
-						Field: key.Field,
-						Value: key.Value,
-					})
-				}
-				var collation *models.Collation
-				if index.Options.Collation != nil {
-					collation = &models.Collation{
-						Locale:          index.Options.Collation.Locale,
-						Strength:        index.Options.Collation.Strength,
-						CaseLevel:       index.Options.Collation.CaseLevel,
-						CaseFirst:       index.Options.Collation.CaseFirst,
-						NumericOrdering: index.Options.Collation.NumericOrdering,
-					}
-				}
-				defaultLanguage := index.Options.DefaultLanguage
-				if index.IsText && defaultLanguage == "" {
-					defaultLanguage = "none"
-				}
-				indexes = append(indexes, models.Index{
-					Options: models.IndexOption{
-						ExpireAfterSeconds: index.Options.ExpireAfterSeconds,
-						IsUnique:           index.Options.IsUnique,
-						Collation:          collation,
-						DefaultLanguage:    defaultLanguage,
-						Weights:            index.Options.Weights,
-					},
-					Collection:   index.Collection,
-					Name:         index.Name,
-					KeySignature: index.KeySignature,
-					Keys:         keys,
-					IsText:       index.IsText,
-				})
+				indexes = append(indexes, index.ToModel(primitive.NilObjectID))
 			}
 		}
 	}
 	database, err := databaseQuery.CreateOne(models.Database{
 		Name:        requestBody.Name,
 		Description: requestBody.Description,
-		Uri:         requestBody.Uri,
+		Uri:         connection.Uri,
 		DBName:      requestBody.DBName,
+		Tls:         connection.Tls,
+		Auth:        connection.Auth,
+		ClusterId:   connection.ClusterId,
 	})
 	if err != nil {
 		return err
@@ -175,7 +176,7 @@ func (ctrl *controller) List(ctx *fiber.Ctx) error {
 	queryOption.AddSortKey(map[string]int{
 		"_id": queries.SortTypeDesc,
 	})
-	queryOption.SetOnlyFields("created_at", "updated_at", "name", "description", "uri", "db_name", "_id")
+	queryOption.SetOnlyFields("created_at", "updated_at", "name", "description", "uri", "db_name", "tls", "auth", "cluster_id", "_id")
 	if requestBody.Query != "" {
 		if id, _ := primitive.ObjectIDFromHex(requestBody.Query); !id.IsZero() {
 			database, err := databaseQuery.GetById(id, queryOption)
@@ -188,6 +189,9 @@ func (ctrl *controller) List(ctx *fiber.Ctx) error {
 			return response.NewArrayWithPagination(ctx, []serializers.DatabaseListResponseItem{{
 				CreatedAt:   database.CreatedAt,
 				UpdatedAt:   database.UpdatedAt,
+				Tls:         newTlsResponse(database.Tls),
+				Auth:        newAuthResponse(database.Auth),
+				ClusterId:   database.ClusterId,
 				Name:        database.Name,
 				Description: database.Description,
 				Uri:         database.Uri,
@@ -219,6 +223,9 @@ func (ctrl *controller) List(ctx *fiber.Ctx) error {
 	for i, database := range databases {
 		result[i].CreatedAt = database.CreatedAt
 		result[i].UpdatedAt = database.UpdatedAt
+		result[i].Tls = newTlsResponse(database.Tls)
+		result[i].Auth = newAuthResponse(database.Auth)
+		result[i].ClusterId = database.ClusterId
 		result[i].Name = database.Name
 		result[i].Description = database.Description
 		result[i].Uri = database.Uri
@@ -243,15 +250,21 @@ func (ctrl *controller) Update(ctx *fiber.Ctx) error {
 	}
 	queryOption := queries.NewOptions()
 	databaseQuery := queries.NewDatabase(ctx.Context())
-	queryOption.SetOnlyFields("name", "uri", "db_name", "description")
+	queryOption.SetOnlyFields("name", "uri", "db_name", "description", "tls", "auth", "cluster_id")
 	database, err := databaseQuery.GetById(id, queryOption)
 	if err != nil {
 		return err
 	}
+	isConnectionSet := requestBody.Tls != nil || requestBody.Auth != nil || database.Tls != nil || database.Auth != nil
+	isClusterSet := requestBody.ClusterId != nil || database.ClusterId != nil
 	if database.Name == requestBody.Name && database.Description == requestBody.Description &&
-		database.Uri == requestBody.Uri && database.DBName == requestBody.DBName {
+		database.Uri == requestBody.Uri && database.DBName == requestBody.DBName && !isConnectionSet && !isClusterSet {
 		return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
 	}
+	connection, dialUri, connectOption, err := ctrl.service.BuildDatabaseConnection(ctx.Context(), requestBody.ClusterId, requestBody.Uri, requestBody.Tls, requestBody.Auth, database)
+	if err != nil {
+		return err
+	}
 	if database.Name != requestBody.Name {
 		if _, err = databaseQuery.GetByName(requestBody.Name, queryOption); err != nil {
 			if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
@@ -261,20 +274,26 @@ func (ctrl *controller) Update(ctx *fiber.Ctx) error {
 			return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
 		}
 	}
-	if database.Uri != requestBody.Uri && requestBody.IsTestConnection {
-		if _, err = mongodb.New(requestBody.Uri); err != nil {
+	if (database.Uri != dialUri || isConnectionSet) && requestBody.IsTestConnection {
+		dbClient, err := mongodb.New(dialUri, connectOption)
+		if err != nil {
 			logger.Error().Err(err).Str("function", "Update").Str("functionInline", "mongodb.New").Msg("database-controller")
 			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
 		}
+		_ = dbClient.Disconnect()
 	}
 	if err = databaseQuery.UpdateInfoById(id, queries.DatabaseUpdateInfoByIdRequest{
 		Name:        requestBody.Name,
 		Description: requestBody.Description,
-		Uri:         requestBody.Uri,
+		Uri:         connection.Uri,
 		DBName:      requestBody.DBName,
+		Tls:         connection.Tls,
+		Auth:        connection.Auth,
+		ClusterId:   connection.ClusterId,
 	}); err != nil {
 		return err
 	}
+	mongodb.GetManager().Invalidate(id.Hex())
 	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
 }
 
@@ -287,15 +306,17 @@ func (ctrl *controller) ListCollections(ctx *fiber.Ctx) error {
 		return err
 	}
 	var (
-		errorChan   = make(chan error, 1)
-		totalChan   = make(chan int, 1)
-		queryOption = queries.NewOptions()
-		indexQuery  = queries.NewIndex(ctx.Context())
-		pagination  = request.NewPagination(requestBody.Limit, requestBody.Page)
-		result      = make([]serializers.DatabaseListCollectionsResponseItem, 0)
+		errorChan    = make(chan error, 1)
+		totalChan    = make(chan int, 1)
+		queryOption  = queries.NewOptions()
+		indexQuery   = queries.NewIndex(ctx.Context())
+		pagination   = request.NewPagination(requestBody.Limit, requestBody.Page)
+		result       = make([]serializers.DatabaseListCollectionsResponseItem, 0)
+		isSortBySize = requestBody.SortBy != "" && requestBody.SortBy != constants.SortByCollection
 	)
-	queryOption.SetOnlyFields("_id")
-	if _, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption); err != nil {
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
 		return err
 	}
 	go func() {
@@ -303,7 +324,10 @@ func (ctrl *controller) ListCollections(ctx *fiber.Ctx) error {
 		errorChan <- err
 		totalChan <- total
 	}()
-	queryOption.SetPagination(pagination)
+	// Sizes only exist on the target cluster, sorting by them needs every collection.
+	if !isSortBySize {
+		queryOption.SetPagination(pagination)
+	}
 	queryOption.AddSortKey(map[string]int{
 		"_id": queries.SortTypeAsc,
 	})
@@ -314,10 +338,49 @@ func (ctrl *controller) ListCollections(ctx *fiber.Ctx) error {
 	if err = <-errorChan; err != nil {
 		return err
 	}
+	collectionNames := make([]string, len(collections))
+	for i, collection := range collections {
+		collectionNames[i] = collection.Collection
+	}
+	mapLive, err := ctrl.service.GetLiveCollections(database, collectionNames)
+	if err != nil {
+		if isSortBySize {
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get collection stats from database"})
+		}
+		logger.Warn().Err(err).Str("function", "ListCollections").Str("functionInline", "ctrl.service.GetLiveCollections").Msg("database-controller")
+	}
 	result = make([]serializers.DatabaseListCollectionsResponseItem, len(collections))
 	for i, collection := range collections {
 		result[i].Collection = collection.Collection
 		result[i].TotalIndexes = collection.TotalIndexes
+		live := mapLive[collection.Collection]
+		if live.Info != nil {
+			result[i].Type = live.Info.Type
+			result[i].IsCapped = live.Info.IsCapped
+			result[i].IsClustered = live.Info.IsClustered
+			if live.Info.Timeseries != nil {
+				result[i].Timeseries = &serializers.DatabaseTimeseriesResponse{
+					TimeField:   live.Info.Timeseries.TimeField,
+					MetaField:   live.Info.Timeseries.MetaField,
+					Granularity: live.Info.Timeseries.Granularity,
+				}
+			}
+		}
+		if live.Stat != nil {
+			result[i].Stats = &serializers.DatabaseCollectionStatsResponse{
+				Count:          live.Stat.Count,
+				Size:           live.Stat.Size,
+				StorageSize:    live.Stat.StorageSize,
+				TotalIndexSize: live.Stat.TotalIndexSize,
+			}
+		}
+		if live.ShardKey != nil {
+			result[i].ShardKey = newShardKeyResponse(*live.ShardKey)
+		}
+	}
+	if isSortBySize {
+		sortCollectionsByStat(result, requestBody.SortBy)
+		result = result[min(pagination.Skip, int64(len(result))):min(pagination.Skip+pagination.Limit, int64(len(result)))]
 	}
 	pagination.SetTotal(int64(<-totalChan))
 	return response.NewArrayWithPagination(ctx, result, pagination)
@@ -349,13 +412,16 @@ func (ctrl *controller) Delete(ctx *fiber.Ctx) error {
 	if err = databaseQuery.DeleteById(id); err != nil {
 		return err
 	}
+	mongodb.GetManager().Invalidate(id.Hex())
 	var (
-		totalTask = 1
+		totalTask = 2
 		errorChan = make(chan error, totalTask)
 	)
 	go func() {
-		err = queries.NewIndex(ctx.Context()).DeleteByDatabaseId(id)
-		errorChan <- err
+		errorChan <- queries.NewIndex(ctx.Context()).DeleteByDatabaseId(id)
+	}()
+	go func() {
+		errorChan <- queries.NewQueryShape(ctx.Context()).DeleteByDatabaseId(id)
 	}()
 	for range totalTask {
 		if err = <-errorChan; err != nil {
@@ -387,17 +453,7 @@ func (ctrl *controller) CreateCollection(ctx *fiber.Ctx) error {
 	} else {
 		return response.New(ctx, response.Options{Code: fiber.StatusConflict, Data: respErr.ErrResourceConflict})
 	}
-	index := models.Index{
-		DatabaseId: requestBody.DatabaseId,
-		Collection: requestBody.Collection,
-		Keys:       models.IndexDefaultKeys,
-		Options:    models.IndexDefaultOptions,
-		IsText:     false,
-		Name:       models.IndexDefaultName,
-		IsDefault:  true,
-	}
-	index.KeySignature = index.GetKeySignature()
-	if err := indexQuery.UpsertOneByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection, index); err != nil {
+	if err := indexQuery.UpsertOneByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection, newDefaultIndex(requestBody.DatabaseId, requestBody.Collection)); err != nil {
 		return err
 	}
 	return response.New(ctx, response.Options{
@@ -408,6 +464,228 @@ func (ctrl *controller) CreateCollection(ctx *fiber.Ctx) error {
 	})
 }
 
+// ListLiveCollections lists the collections found on the cluster, system namespaces aside, and
+// tells which ones have declared indexes. Live index counts are only read for the page.
+func (ctrl *controller) ListLiveCollections(ctx *fiber.Ctx) error {
+	var requestBody serializers.DatabaseListLiveCollectionsBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	pagination := request.NewPagination(requestBody.Limit, requestBody.Page)
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	managedCollections, err := queries.NewIndex(ctx.Context()).GetCollectionsByDatabaseIdAndQuery(requestBody.DatabaseId, requestBody.Query)
+	if err != nil {
+		return err
+	}
+	mapTotalIndexes := make(map[string]int, len(managedCollections))
+	for _, collection := range managedCollections {
+		mapTotalIndexes[collection.Collection] = collection.TotalIndexes
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ListLiveCollections").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	infos, err := dbClient.GetCollectionInfos(database.DBName, nil)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ListLiveCollections").Str("functionInline", "dbClient.GetCollectionInfos").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get collections from database"})
+	}
+	query := strings.ToLower(requestBody.Query)
+	infos = slices.DeleteFunc(infos, func(info mongodb.CollectionInfo) bool {
+		if query != "" && !strings.Contains(strings.ToLower(info.Name), query) {
+			return true
+		}
+		_, isManaged := mapTotalIndexes[info.Name]
+		return requestBody.IsManaged != nil && *requestBody.IsManaged != isManaged
+	})
+	pagination.SetTotal(int64(len(infos)))
+	infos = infos[min(pagination.Skip, int64(len(infos))):min(pagination.Skip+pagination.Limit, int64(len(infos)))]
+	collections := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if info.Type != mongodb.CollectionTypeView {
+			collections = append(collections, info.Name)
+		}
+	}
+	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, collections)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ListLiveCollections").Str("functionInline", "dbClient.GetIndexesByDbNameAndCollections").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from database"})
+	}
+	mapLiveIndexes := make(map[string]int, len(collections))
+	for _, index := range clientIndexes {
+		mapLiveIndexes[index.Collection]++
+	}
+	result := make([]serializers.DatabaseListLiveCollectionsResponseItem, len(infos))
+	for i, info := range infos {
+		totalIndexes, isManaged := mapTotalIndexes[info.Name]
+		result[i] = serializers.DatabaseListLiveCollectionsResponseItem{
+			Collection:   info.Name,
+			Type:         info.Type,
+			TotalIndexes: totalIndexes,
+			LiveIndexes:  mapLiveIndexes[info.Name],
+			IsCapped:     info.IsCapped,
+			IsClustered:  info.IsClustered,
+			IsManaged:    isManaged,
+		}
+		// Listing indexes leaves out the _id or clustered index every plain collection has.
+		if info.Type == mongodb.CollectionTypeCollection {
+			result[i].LiveIndexes++
+		}
+		if info.Timeseries != nil {
+			result[i].Timeseries = &serializers.DatabaseTimeseriesResponse{
+				TimeField:   info.Timeseries.TimeField,
+				MetaField:   info.Timeseries.MetaField,
+				Granularity: info.Timeseries.Granularity,
+			}
+		}
+	}
+	return response.NewArrayWithPagination(ctx, result, pagination)
+}
+
+// SampleSchemas infers the field paths, types and presence rates of collections from a $sample
+// of their documents.
+func (ctrl *controller) SampleSchemas(ctx *fiber.Ctx) error {
+	var requestBody serializers.DatabaseSampleSchemasBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	collections := requestBody.Collections
+	if len(collections) == 0 {
+		managedCollections, err := queries.NewIndex(ctx.Context()).GetCollectionsByDatabaseIdAndQuery(requestBody.DatabaseId, "")
+		if err != nil {
+			return err
+		}
+		collections = make([]string, len(managedCollections))
+		for i, collection := range managedCollections {
+			collections[i] = collection.Collection
+		}
+		sort.Strings(collections)
+	}
+	sampleSize := requestBody.SampleSize
+	if sampleSize == 0 {
+		sampleSize = cfg.SchemaSampleSize
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "SampleSchemas").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	result := make([]serializers.DatabaseSampleSchemasResponseItem, len(collections))
+	for i, collection := range collections {
+		collectionSchema, err := schema.Sample(dbClient, database.DBName, collection, sampleSize)
+		if err != nil {
+			logger.Error().Err(err).Str("function", "SampleSchemas").Str("functionInline", "schema.Sample").Msg("database-controller")
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot sample documents from database"})
+		}
+		result[i] = serializers.DatabaseSampleSchemasResponseItem{
+			Fields:     make([]serializers.DatabaseSchemaField, len(collectionSchema.Fields)),
+			Collection: collection,
+			SampleSize: collectionSchema.SampleSize,
+		}
+		for j, field := range collectionSchema.Fields {
+			types := make([]serializers.DatabaseSchemaFieldType, len(field.Types))
+			for k, fieldType := range field.Types {
+				types[k] = serializers.DatabaseSchemaFieldType{Type: fieldType.Type, Count: fieldType.Count}
+			}
+			result[i].Fields[j] = serializers.DatabaseSchemaField{
+				Types:          types,
+				Path:           field.Path,
+				Count:          field.Count,
+				PresenceRate:   field.PresenceRate,
+				IsArray:        field.IsArray,
+				HasNestedArray: field.HasNestedArray,
+			}
+		}
+	}
+	return response.NewArrayWithPagination(ctx, result, &request.Pagination{})
+}
+
+// AdoptCollection makes an unmanaged collection of the cluster managed, its live indexes become
+// declared ones. The indexes the server created along with a time-series collection are left out.
+func (ctrl *controller) AdoptCollection(ctx *fiber.Ctx) error {
+	var requestBody serializers.DatabaseAdoptCollectionBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	indexQuery := queries.NewIndex(ctx.Context())
+	queryOption.SetOnlyFields("_id")
+	if _, err = indexQuery.GetOneByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection, queryOption); err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
+			return err
+		}
+	} else {
+		return response.New(ctx, response.Options{Code: fiber.StatusConflict, Data: respErr.ErrResourceConflict})
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "AdoptCollection").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	infos, err := dbClient.GetCollectionInfos(database.DBName, []string{requestBody.Collection})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "AdoptCollection").Str("functionInline", "dbClient.GetCollectionInfos").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get collections from database"})
+	}
+	if len(infos) == 0 {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	if infos[0].Type == mongodb.CollectionTypeView {
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Views cannot have indexes"})
+	}
+	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, []string{requestBody.Collection})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "AdoptCollection").Str("functionInline", "dbClient.GetIndexesByDbNameAndCollections").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from database"})
+	}
+	indexes := make([]models.Index, 0, len(clientIndexes))
+	for i := range clientIndexes {
+		if !infos[0].IsBuiltinIndex(clientIndexes[i]) {
+			indexes = append(indexes, clientIndexes[i].ToModel(requestBody.DatabaseId))
+		}
+	}
+	if err = indexQuery.UpsertOneByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection, newDefaultIndex(requestBody.DatabaseId, requestBody.Collection)); err != nil {
+		return err
+	}
+	importedCount, skippedCount, err := indexQuery.CreateManyMissing(requestBody.DatabaseId, indexes)
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Code: fiber.StatusCreated, Data: serializers.DatabaseAdoptCollectionResponse{
+		ImportedCount: importedCount,
+		SkippedCount:  skippedCount,
+	}})
+}
+
 func (ctrl *controller) UpdateCollection(ctx *fiber.Ctx) error {
 	var requestBody serializers.DatabaseUpdateCollectionBodyValidate
 	if err := ctx.BodyParser(&requestBody); err != nil {
@@ -427,6 +705,9 @@ func (ctrl *controller) UpdateCollection(ctx *fiber.Ctx) error {
 	if err := queries.NewIndex(ctx.Context()).UpdateCollectionByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection, requestBody.NewCollection); err != nil {
 		return err
 	}
+	if err := queries.NewQueryShape(ctx.Context()).UpdateCollectionByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection, requestBody.NewCollection); err != nil {
+		return err
+	}
 	return response.New(ctx, response.Options{
 		Data: fiber.Map{
 			"success": true,
@@ -450,9 +731,444 @@ func (ctrl *controller) DeleteCollection(ctx *fiber.Ctx) error {
 	if err := queries.NewIndex(ctx.Context()).DeleteByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection); err != nil {
 		return err
 	}
+	if err := queries.NewQueryShape(ctx.Context()).DeleteByDatabaseIdAndCollection(requestBody.DatabaseId, requestBody.Collection); err != nil {
+		return err
+	}
 	return response.New(ctx, response.Options{
 		Data: fiber.Map{
 			"success": true,
 		},
 	})
 }
+
+func (ctrl *controller) GetCluster(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("created_at", "updated_at", "name", "description", "uri", "tls", "auth", "_id")
+	cluster, err := queries.NewCluster(ctx.Context()).GetById(id, queryOption)
+	if err != nil {
+		return err
+	}
+	totalDatabases, err := queries.NewDatabase(ctx.Context()).GetTotalByClusterId(id)
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: serializers.ClusterGetResponse{
+		CreatedAt:      cluster.CreatedAt,
+		UpdatedAt:      cluster.UpdatedAt,
+		Tls:            newTlsResponse(cluster.Tls),
+		Auth:           newAuthResponse(cluster.Auth),
+		Name:           cluster.Name,
+		Description:    cluster.Description,
+		Uri:            cluster.Uri,
+		TotalDatabases: totalDatabases,
+		Id:             cluster.Id,
+	}})
+}
+
+func (ctrl *controller) CreateCluster(ctx *fiber.Ctx) error {
+	var requestBody serializers.ClusterCreateBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	clusterQuery := queries.NewCluster(ctx.Context())
+	if _, err := clusterQuery.GetByName(requestBody.Name, queryOption); err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
+			return err
+		}
+	} else {
+		return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+	}
+	connection, connectOption, err := ctrl.service.BuildConnection(requestBody.Tls, requestBody.Auth, nil)
+	if err != nil {
+		return err
+	}
+	if requestBody.IsTestConnection {
+		dbClient, err := mongodb.New(requestBody.Uri, connectOption)
+		if err != nil {
+			logger.Error().Err(err).Str("function", "CreateCluster").Str("functionInline", "mongodb.New").Msg("database-controller")
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+		}
+		_ = dbClient.Disconnect()
+	}
+	cluster, err := clusterQuery.CreateOne(models.Cluster{
+		Name:        requestBody.Name,
+		Description: requestBody.Description,
+		Uri:         requestBody.Uri,
+		Tls:         connection.Tls,
+		Auth:        connection.Auth,
+	})
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{
+		Code: fiber.StatusCreated,
+		Data: fiber.Map{
+			"id": cluster.Id,
+		},
+	})
+}
+
+func (ctrl *controller) ListClusters(ctx *fiber.Ctx) error {
+	var requestBody serializers.ClusterListBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	var (
+		err          error
+		clusters     = make([]models.Cluster, 0)
+		errorChan    = make(chan error, 1)
+		totalChan    = make(chan int64, 1)
+		queryOption  = queries.NewOptions()
+		clusterQuery = queries.NewCluster(ctx.Context())
+		pagination   = request.NewPagination(requestBody.Limit, requestBody.Page)
+	)
+	queryOption.SetPagination(pagination)
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	queryOption.SetOnlyFields("created_at", "updated_at", "name", "description", "uri", "tls", "auth", "_id")
+	if requestBody.Query != "" {
+		go func() {
+			total, err := clusterQuery.GetTotalByQuery(requestBody.Query)
+			errorChan <- err
+			totalChan <- total
+		}()
+		clusters, err = clusterQuery.GetByQuery(requestBody.Query, queryOption)
+	} else {
+		go func() {
+			total, err := clusterQuery.GetTotal()
+			errorChan <- err
+			totalChan <- total
+		}()
+		clusters, err = clusterQuery.GetAll(queryOption)
+	}
+	if err != nil {
+		return err
+	}
+	if err = <-errorChan; err != nil {
+		return err
+	}
+	result := make([]serializers.ClusterListResponseItem, len(clusters))
+	for i, cluster := range clusters {
+		result[i].CreatedAt = cluster.CreatedAt
+		result[i].UpdatedAt = cluster.UpdatedAt
+		result[i].Tls = newTlsResponse(cluster.Tls)
+		result[i].Auth = newAuthResponse(cluster.Auth)
+		result[i].Name = cluster.Name
+		result[i].Description = cluster.Description
+		result[i].Uri = cluster.Uri
+		result[i].Id = cluster.Id
+	}
+	pagination.SetTotal(<-totalChan)
+	return response.NewArrayWithPagination(ctx, result, pagination)
+}
+
+// UpdateCluster drops the pooled client of the cluster, its databases reconnect with the new
+// settings on their next call.
+func (ctrl *controller) UpdateCluster(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	var requestBody serializers.ClusterUpdateBodyValidate
+	if err = ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err = requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	clusterQuery := queries.NewCluster(ctx.Context())
+	queryOption.SetOnlyFields("name", "uri", "description", "tls", "auth")
+	cluster, err := clusterQuery.GetById(id, queryOption)
+	if err != nil {
+		return err
+	}
+	isConnectionSet := requestBody.Tls != nil || requestBody.Auth != nil || cluster.Tls != nil || cluster.Auth != nil
+	if cluster.Name == requestBody.Name && cluster.Description == requestBody.Description &&
+		cluster.Uri == requestBody.Uri && !isConnectionSet {
+		return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+	}
+	connection, connectOption, err := ctrl.service.BuildConnection(requestBody.Tls, requestBody.Auth, &models.Database{Tls: cluster.Tls, Auth: cluster.Auth})
+	if err != nil {
+		return err
+	}
+	if cluster.Name != requestBody.Name {
+		if _, err = clusterQuery.GetByName(requestBody.Name, queryOption); err != nil {
+			if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
+				return err
+			}
+		} else {
+			return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+	}
+	if (cluster.Uri != requestBody.Uri || isConnectionSet) && requestBody.IsTestConnection {
+		dbClient, err := mongodb.New(requestBody.Uri, connectOption)
+		if err != nil {
+			logger.Error().Err(err).Str("function", "UpdateCluster").Str("functionInline", "mongodb.New").Msg("database-controller")
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+		}
+		_ = dbClient.Disconnect()
+	}
+	if err = clusterQuery.UpdateInfoById(id, queries.ClusterUpdateInfoByIdRequest{
+		Name:        requestBody.Name,
+		Description: requestBody.Description,
+		Uri:         requestBody.Uri,
+		Tls:         connection.Tls,
+		Auth:        connection.Auth,
+	}); err != nil {
+		return err
+	}
+	mongodb.GetManager().Invalidate(id.Hex())
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+// DeleteCluster refuses to delete a cluster still referenced by databases.
+func (ctrl *controller) DeleteCluster(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	queryOption := queries.NewOptions()
+	clusterQuery := queries.NewCluster(ctx.Context())
+	queryOption.SetOnlyFields("_id")
+	if _, err = clusterQuery.GetById(id, queryOption); err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code == fiber.StatusNotFound {
+			return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+		}
+		return err
+	}
+	totalDatabases, err := queries.NewDatabase(ctx.Context()).GetTotalByClusterId(id)
+	if err != nil {
+		return err
+	}
+	if totalDatabases > 0 {
+		return response.New(ctx, response.Options{Code: fiber.StatusConflict, Data: respErr.ErrClusterInUse})
+	}
+	if err = clusterQuery.DeleteById(id); err != nil {
+		return err
+	}
+	mongodb.GetManager().Invalidate(id.Hex())
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+// ListClusterDatabases lists the databases found on the cluster, system databases aside, with
+// the id of those already registered under it.
+func (ctrl *controller) ListClusterDatabases(ctx *fiber.Ctx) error {
+	var requestBody serializers.ClusterListDatabasesBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "tls", "auth")
+	cluster, err := queries.NewCluster(ctx.Context()).GetById(requestBody.ClusterId, queryOption)
+	if err != nil {
+		return err
+	}
+	queryOption.SetOnlyFields("_id", "db_name")
+	databases, err := queries.NewDatabase(ctx.Context()).GetByClusterId(requestBody.ClusterId, queryOption)
+	if err != nil {
+		return err
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(newClusterDatabase(cluster))
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ListClusterDatabases").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	clientDatabases, err := dbClient.ListDatabases()
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ListClusterDatabases").Str("functionInline", "dbClient.ListDatabases").Msg("database-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot list databases of the cluster"})
+	}
+	mapDatabaseId := make(map[string]primitive.ObjectID, len(databases))
+	for _, database := range databases {
+		mapDatabaseId[database.DBName] = database.Id
+	}
+	result := make([]serializers.ClusterListDatabasesResponseItem, len(clientDatabases))
+	for i, clientDatabase := range clientDatabases {
+		result[i] = serializers.ClusterListDatabasesResponseItem{
+			DBName:     clientDatabase.Name,
+			SizeOnDisk: clientDatabase.SizeOnDisk,
+			IsEmpty:    clientDatabase.IsEmpty,
+		}
+		if id, exists := mapDatabaseId[clientDatabase.Name]; exists {
+			result[i].DatabaseId = &id
+		}
+	}
+	return response.NewArrayWithPagination(ctx, result, &request.Pagination{})
+}
+
+// ImportClusterDatabases registers the requested databases under the cluster, reusing those
+// already registered, and imports their indexes as SyncFromDatabase does. A database that
+// fails is reported and the others go on.
+func (ctrl *controller) ImportClusterDatabases(ctx *fiber.Ctx) error {
+	var requestBody serializers.ClusterImportDatabasesBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "tls", "auth")
+	cluster, err := queries.NewCluster(ctx.Context()).GetById(requestBody.ClusterId, queryOption)
+	if err != nil {
+		return err
+	}
+	databaseQuery := queries.NewDatabase(ctx.Context())
+	queryOption.SetOnlyFields("_id", "name", "db_name")
+	databases, err := databaseQuery.GetByClusterId(requestBody.ClusterId, queryOption)
+	if err != nil {
+		return err
+	}
+	mapDatabase := make(map[string]models.Database, len(databases))
+	for _, database := range databases {
+		mapDatabase[database.DBName] = database
+	}
+	var dbClient mongodb.Service
+	if requestBody.IsSyncIndex {
+		var releaseClient func()
+		dbClient, releaseClient, err = mongodb.GetManager().GetByDatabase(newClusterDatabase(cluster))
+		if err != nil {
+			logger.Error().Err(err).Str("function", "ImportClusterDatabases").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("database-controller")
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+		}
+		defer releaseClient()
+	}
+	indexQuery := queries.NewIndex(ctx.Context())
+	result := make([]serializers.ClusterImportDatabasesResponseItem, len(requestBody.Databases))
+	for i, item := range requestBody.Databases {
+		result[i].DBName = item.DBName
+		database, exists := mapDatabase[item.DBName]
+		if !exists {
+			database = models.Database{
+				Name:      item.Name,
+				DBName:    item.DBName,
+				ClusterId: &requestBody.ClusterId,
+			}
+			if database.Name == "" {
+				database.Name = item.DBName
+			}
+			newDatabase, err := databaseQuery.CreateOne(database)
+			if err != nil {
+				result[i].Name = database.Name
+				result[i].Error = err.Error()
+				continue
+			}
+			database = *newDatabase
+			mapDatabase[item.DBName] = database
+			result[i].IsCreated = true
+		}
+		result[i].DatabaseId = &database.Id
+		result[i].Name = database.Name
+		if !requestBody.IsSyncIndex {
+			continue
+		}
+		clientIndexes, err := dbClient.GetIndexesByDbName(item.DBName)
+		if err != nil {
+			logger.Error().Err(err).Str("function", "ImportClusterDatabases").Str("functionInline", "dbClient.GetIndexesByDbName").Str("dbName", item.DBName).Msg("database-controller")
+			result[i].Error = "Cannot get indexes from database"
+			continue
+		}
+		indexes := make([]models.Index, len(clientIndexes))
+		for j := range clientIndexes {
+			indexes[j] = clientIndexes[j].ToModel(database.Id)
+		}
+		if result[i].ImportedCount, result[i].SkippedCount, err = indexQuery.CreateManyMissing(database.Id, indexes); err != nil {
+			result[i].Error = err.Error()
+		}
+	}
+	return response.NewArrayWithPagination(ctx, result, &request.Pagination{})
+}
+
+// newDefaultIndex is the _id index a managed collection starts with, it is never synced.
+func newDefaultIndex(databaseId primitive.ObjectID, collection string) models.Index {
+	index := models.Index{
+		DatabaseId: databaseId,
+		Collection: collection,
+		Keys:       models.IndexDefaultKeys,
+		Options:    models.IndexDefaultOptions,
+		IsText:     false,
+		Name:       models.IndexDefaultName,
+		IsDefault:  true,
+	}
+	index.KeySignature = index.GetKeySignature()
+	return index
+}
+
+func newTlsResponse(tls *models.DatabaseTls) *serializers.DatabaseTlsResponse {
+	if tls == nil {
+		return nil
+	}
+	return &serializers.DatabaseTlsResponse{
+		IsEnabled:            tls.IsEnabled,
+		IsInsecureSkipVerify: tls.IsInsecureSkipVerify,
+		HasCaCertificate:     tls.CaCertificate != "",
+		HasClientCertificate: tls.ClientCertificate != "",
+		HasClientKey:         tls.ClientKey != "",
+	}
+}
+
+func newAuthResponse(auth *models.DatabaseAuth) *serializers.DatabaseAuthResponse {
+	if auth == nil {
+		return nil
+	}
+	return &serializers.DatabaseAuthResponse{
+		Mechanism:          auth.Mechanism,
+		Source:             auth.Source,
+		Username:           auth.Username,
+		HasPassword:        auth.Password != "",
+		HasAwsSessionToken: auth.AwsSessionToken != "",
+	}
+}
+
+// sortCollectionsByStat puts the heaviest collections first and those missing on the
+// cluster last.
+func sortCollectionsByStat(items []serializers.DatabaseListCollectionsResponseItem, sortBy string) {
+	value := func(stats *serializers.DatabaseCollectionStatsResponse) int64 {
+		if stats == nil {
+			return -1
+		}
+		switch sortBy {
+		case constants.SortByCount:
+			return stats.Count
+		case constants.SortByStorageSize:
+			return stats.StorageSize
+		case constants.SortByTotalIndexSize:
+			return stats.TotalIndexSize
+		default:
+			return stats.Size
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return value(items[i].Stats) > value(items[j].Stats)
+	})
+}
+
+func newShardKeyResponse(shardKey mongodb.ShardKey) *serializers.DatabaseShardKeyResponse {
+	keys := make([]serializers.DatabaseShardKeyField, len(shardKey.Keys))
+	for i, key := range shardKey.Keys {
+		keys[i] = serializers.DatabaseShardKeyField{Field: key.Field, Value: key.Value}
+	}
+	return &serializers.DatabaseShardKeyResponse{
+		Keys:       keys,
+		IndexNames: shardKey.IndexNames,
+		IsUnique:   shardKey.IsUnique,
+	}
+}
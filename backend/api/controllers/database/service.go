@@ -1,6 +1,33 @@
 package database
 
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/api/serializers"
+	"doctor-manager-api/common/constants"
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/encryption"
+	"doctor-manager-api/utilities/mongodb"
+)
+
 type serviceInterface interface {
+	BuildConnection(tlsBody *serializers.DatabaseTlsBodyValidate, authBody *serializers.DatabaseAuthBodyValidate, current *models.Database) (connection *models.Database, connectOption mongodb.ConnectOption, err error)
+	BuildDatabaseConnection(ctx context.Context, clusterId *primitive.ObjectID, uri string, tlsBody *serializers.DatabaseTlsBodyValidate, authBody *serializers.DatabaseAuthBodyValidate, current *models.Database) (connection *models.Database, dialUri string, connectOption mongodb.ConnectOption, err error)
+	GetLiveCollections(database *models.Database, collections []string) (mapCollection map[string]liveCollection, err error)
+}
+
+// liveCollection is what the cluster reports about a declared collection. Info and Stat are nil
+// when the collection is missing, Stat also for views, ShardKey when it is not sharded.
+type liveCollection struct {
+	Info     *mongodb.CollectionInfo
+	Stat     *mongodb.CollectionStat
+	ShardKey *mongodb.ShardKey
 }
 
 type service struct{}
@@ -8,3 +35,201 @@ type service struct{}
 func newService() serviceInterface {
 	return &service{}
 }
+
+// BuildConnection validates the TLS and auth settings of a request and returns them
+// encrypted in connection.Tls and connection.Auth, and in clear in connectOption to
+// test them. Empty secrets fall back to those of current, which is nil on create.
+func (s *service) BuildConnection(tlsBody *serializers.DatabaseTlsBodyValidate, authBody *serializers.DatabaseAuthBodyValidate, current *models.Database) (*models.Database, mongodb.ConnectOption, error) {
+	var (
+		connectOption mongodb.ConnectOption
+		currentOption mongodb.ConnectOption
+		connection    = new(models.Database)
+	)
+	if current != nil {
+		var err error
+		if currentOption, err = mongodb.ConnectOptionFromDatabase(current); err != nil {
+			logger.Error().Err(err).Str("function", "BuildConnection").Str("functionInline", "mongodb.ConnectOptionFromDatabase").Msg("database-service")
+			return nil, connectOption, response.NewError(fiber.StatusPreconditionFailed, response.ErrorOptions{Data: "Cannot decrypt stored connection secrets"})
+		}
+	}
+	if tlsBody != nil {
+		connectOption.Tls = &mongodb.TlsOption{
+			CaCertificate:        tlsBody.CaCertificate,
+			ClientCertificate:    tlsBody.ClientCertificate,
+			ClientKey:            tlsBody.ClientKey,
+			IsEnabled:            tlsBody.IsEnabled,
+			IsInsecureSkipVerify: tlsBody.IsInsecureSkipVerify,
+		}
+		if currentOption.Tls != nil {
+			connectOption.Tls.CaCertificate = fallback(connectOption.Tls.CaCertificate, currentOption.Tls.CaCertificate)
+			connectOption.Tls.ClientCertificate = fallback(connectOption.Tls.ClientCertificate, currentOption.Tls.ClientCertificate)
+			connectOption.Tls.ClientKey = fallback(connectOption.Tls.ClientKey, currentOption.Tls.ClientKey)
+		}
+	}
+	if authBody != nil {
+		connectOption.Auth = &mongodb.AuthOption{
+			Mechanism:       authBody.Mechanism,
+			Source:          authBody.Source,
+			Username:        authBody.Username,
+			Password:        authBody.Password,
+			AwsSessionToken: authBody.AwsSessionToken,
+		}
+		if currentOption.Auth != nil {
+			connectOption.Auth.Password = fallback(connectOption.Auth.Password, currentOption.Auth.Password)
+			connectOption.Auth.AwsSessionToken = fallback(connectOption.Auth.AwsSessionToken, currentOption.Auth.AwsSessionToken)
+		}
+		switch connectOption.Auth.Mechanism {
+		case constants.DatabaseAuthMechanismScramSha1, constants.DatabaseAuthMechanismScramSha256, constants.DatabaseAuthMechanismPlain:
+			if connectOption.Auth.Username == "" {
+				return nil, connectOption, response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: respErr.ErrAuthUsernameRequired})
+			}
+		case constants.DatabaseAuthMechanismX509:
+			if connectOption.Tls == nil || !connectOption.Tls.IsEnabled || connectOption.Tls.ClientCertificate == "" || connectOption.Tls.ClientKey == "" {
+				return nil, connectOption, response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: respErr.ErrAuthX509TlsRequired})
+			}
+		}
+	}
+	if err := connectOption.Validate(); err != nil {
+		return nil, connectOption, response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: err.Error()})
+	}
+	var err error
+	if connectOption.Tls != nil {
+		connection.Tls = &models.DatabaseTls{
+			IsEnabled:            connectOption.Tls.IsEnabled,
+			IsInsecureSkipVerify: connectOption.Tls.IsInsecureSkipVerify,
+		}
+		if connection.Tls.CaCertificate, err = encrypt(connectOption.Tls.CaCertificate); err != nil {
+			return nil, connectOption, err
+		}
+		if connection.Tls.ClientCertificate, err = encrypt(connectOption.Tls.ClientCertificate); err != nil {
+			return nil, connectOption, err
+		}
+		if connection.Tls.ClientKey, err = encrypt(connectOption.Tls.ClientKey); err != nil {
+			return nil, connectOption, err
+		}
+	}
+	if connectOption.Auth != nil {
+		connection.Auth = &models.DatabaseAuth{
+			Mechanism: connectOption.Auth.Mechanism,
+			Source:    connectOption.Auth.Source,
+			Username:  connectOption.Auth.Username,
+		}
+		if connection.Auth.Password, err = encrypt(connectOption.Auth.Password); err != nil {
+			return nil, connectOption, err
+		}
+		if connection.Auth.AwsSessionToken, err = encrypt(connectOption.Auth.AwsSessionToken); err != nil {
+			return nil, connectOption, err
+		}
+	}
+	return connection, connectOption, nil
+}
+
+// BuildDatabaseConnection returns the connection stored on a database, either its own uri, TLS
+// and auth settings or a reference to clusterId, with the uri and settings to dial it. current
+// is nil on create and its secrets are only reused when it does not reference a cluster.
+func (s *service) BuildDatabaseConnection(ctx context.Context, clusterId *primitive.ObjectID, uri string, tlsBody *serializers.DatabaseTlsBodyValidate, authBody *serializers.DatabaseAuthBodyValidate, current *models.Database) (*models.Database, string, mongodb.ConnectOption, error) {
+	if clusterId != nil {
+		if uri != "" || tlsBody != nil || authBody != nil {
+			return nil, "", mongodb.ConnectOption{}, response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: respErr.ErrDatabaseClusterMixed})
+		}
+		queryOption := queries.NewOptions()
+		queryOption.SetOnlyFields("uri", "tls", "auth")
+		cluster, err := queries.NewCluster(ctx).GetById(*clusterId, queryOption)
+		if err != nil {
+			return nil, "", mongodb.ConnectOption{}, err
+		}
+		connectOption, err := mongodb.ConnectOptionFromDatabase(newClusterDatabase(cluster))
+		if err != nil {
+			logger.Error().Err(err).Str("function", "BuildDatabaseConnection").Str("functionInline", "mongodb.ConnectOptionFromDatabase").Msg("database-service")
+			return nil, "", connectOption, response.NewError(fiber.StatusPreconditionFailed, response.ErrorOptions{Data: "Cannot decrypt stored connection secrets"})
+		}
+		return &models.Database{ClusterId: clusterId}, cluster.Uri, connectOption, nil
+	}
+	if uri == "" {
+		return nil, "", mongodb.ConnectOption{}, response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: respErr.ErrDatabaseUriRequired})
+	}
+	if current != nil && current.ClusterId != nil {
+		current = nil
+	}
+	connection, connectOption, err := s.BuildConnection(tlsBody, authBody, current)
+	if err != nil {
+		return nil, "", connectOption, err
+	}
+	connection.Uri = uri
+	return connection, uri, connectOption, nil
+}
+
+// GetLiveCollections returns the type, live stats and shard key of the collections keyed by
+// name, collections missing on the cluster have no entry. Shard keys are best
+// effort, reading config.collections may not be allowed.
+func (s *service) GetLiveCollections(database *models.Database, collections []string) (map[string]liveCollection, error) {
+	mapCollection := make(map[string]liveCollection, len(collections))
+	if len(collections) == 0 {
+		return mapCollection, nil
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseClient()
+	infos, err := dbClient.GetCollectionInfos(database.DBName, collections)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		mapCollection[info.Name] = liveCollection{Info: &info}
+	}
+	stats, err := dbClient.GetCollectionStats(database.DBName, collections)
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		collection := mapCollection[stat.Collection]
+		collection.Stat = &stat
+		mapCollection[stat.Collection] = collection
+	}
+	shardKeys, err := dbClient.GetShardKeys(database.DBName, collections)
+	if err != nil {
+		logger.Warn().Err(err).Str("function", "GetLiveCollections").Str("functionInline", "dbClient.GetShardKeys").Msg("database-service")
+	}
+	for _, shardKey := range shardKeys {
+		collection := mapCollection[shardKey.Collection]
+		collection.ShardKey = &shardKey
+		mapCollection[shardKey.Collection] = collection
+	}
+	return mapCollection, nil
+}
+
+// newClusterDatabase stands for the connection of a cluster, the manager pools it by cluster id
+// so every database of the cluster shares one client.
+func newClusterDatabase(cluster *models.Cluster) *models.Database {
+	return &models.Database{
+		ClusterId: &cluster.Id,
+		Uri:       cluster.Uri,
+		Tls:       cluster.Tls,
+		Auth:      cluster.Auth,
+	}
+}
+
+func encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	encryptionService := encryption.GetGlobal()
+	if encryptionService == nil {
+		return "", response.NewError(fiber.StatusPreconditionFailed, response.ErrorOptions{Data: respErr.ErrEncryptionKeyMissing})
+	}
+	ciphertext, err := encryptionService.Encrypt(plaintext)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "encrypt").Str("functionInline", "encryptionService.Encrypt").Msg("database-service")
+		return "", response.NewError(fiber.StatusInternalServerError)
+	}
+	return ciphertext, nil
+}
+
+func fallback(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
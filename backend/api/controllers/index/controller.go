@@ -1,9 +1,13 @@
 package index
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"reflect"
 	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -11,6 +15,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"doctor-manager-api/api/serializers"
+	"doctor-manager-api/common/configure"
 	"doctor-manager-api/common/constants"
 	"doctor-manager-api/common/logging"
 	"doctor-manager-api/common/request"
@@ -19,11 +24,19 @@ import (
 	"doctor-manager-api/database/mongo/models"
 	"doctor-manager-api/database/mongo/queries"
 	"doctor-manager-api/job"
+	"doctor-manager-api/utilities/advisor"
+	"doctor-manager-api/utilities/estimate"
+	"doctor-manager-api/utilities/indexspec"
+	"doctor-manager-api/utilities/lint"
 	"doctor-manager-api/utilities/mongodb"
+	"doctor-manager-api/utilities/schema"
 	"doctor-manager-api/utilities/taskqueue"
 )
 
-var logger = logging.GetLogger()
+var (
+	logger = logging.GetLogger()
+	cfg    = configure.GetConfig()
+)
 
 type Controller interface {
 	Create(ctx *fiber.Ctx) error
@@ -33,18 +46,29 @@ type Controller interface {
 	Delete(ctx *fiber.Ctx) error
 	CompareByCollections(ctx *fiber.Ctx) error
 	CompareByDatabase(ctx *fiber.Ctx) error
+	UsageByDatabase(ctx *fiber.Ctx) error
+	Lint(ctx *fiber.Ctx) error
+	CheckDuplicates(ctx *fiber.Ctx) error
+	ShardConsistency(ctx *fiber.Ctx) error
+	AdvisorFromProfile(ctx *fiber.Ctx) error
+	AdvisorFromLog(ctx *fiber.Ctx) error
+	AdvisorAccept(ctx *fiber.Ctx) error
 	SyncByCollections(ctx *fiber.Ctx) error
 	GetSyncStatus(ctx *fiber.Ctx) error
 	GetSyncStatusByDatabase(ctx *fiber.Ctx) error
+	UnhideSyncIndexes(ctx *fiber.Ctx) error
 	SyncFromDatabase(ctx *fiber.Ctx) error
 	SyncByDatabase(ctx *fiber.Ctx) error
 }
 
 type controller struct {
+	service serviceInterface
 }
 
 func New() Controller {
-	return &controller{}
+	return &controller{
+		service: newService(),
+	}
 }
 
 func (ctrl *controller) Create(ctx *fiber.Ctx) error {
@@ -56,8 +80,9 @@ func (ctrl *controller) Create(ctx *fiber.Ctx) error {
 		return err
 	}
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("uri")
-	if _, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption); err != nil {
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
 		return err
 	}
 	var collation *models.Collation
@@ -68,6 +93,9 @@ func (ctrl *controller) Create(ctx *fiber.Ctx) error {
 			CaseLevel:       requestBody.Options.Collation.CaseLevel,
 			CaseFirst:       requestBody.Options.Collation.CaseFirst,
 			NumericOrdering: requestBody.Options.Collation.NumericOrdering,
+			Backwards:       requestBody.Options.Collation.Backwards,
+			Alternate:       requestBody.Options.Collation.Alternate,
+			MaxVariable:     requestBody.Options.Collation.MaxVariable,
 		}
 	}
 	index := &models.Index{
@@ -86,22 +114,7 @@ func (ctrl *controller) Create(ctx *fiber.Ctx) error {
 	keyFields := make([]string, len(index.Keys))
 	for i, key := range requestBody.Keys {
 		index.Keys[i].Field = key.Field
-		switch v := key.Value.(type) {
-		case float64:
-			if v == 1 || v == -1 {
-				index.Keys[i].Value = int32(v)
-			} else {
-				index.Keys[i].Value = key.Value
-			}
-		case int:
-			if v == 1 || v == -1 {
-				index.Keys[i].Value = int32(v)
-			} else {
-				index.Keys[i].Value = key.Value
-			}
-		default:
-			index.Keys[i].Value = key.Value
-		}
+		index.Keys[i].Value = indexspec.NormalizeValue(key.Value)
 		keyFields[i] = key.Field
 	}
 	index.IsText = models.IsTextIndex(index.Keys)
@@ -130,12 +143,21 @@ func (ctrl *controller) Create(ctx *fiber.Ctx) error {
 			return response.New(ctx, response.Options{Code: fiber.StatusConflict, Data: respErr.ErrResourceConflict})
 		}
 	}
+	if index.IsText {
+		if err := checkSingleTextIndex(indexQuery, requestBody.DatabaseId, requestBody.Collection, primitive.NilObjectID); err != nil {
+			return err
+		}
+	}
 	newIndex, err := indexQuery.CreateOne(*index)
 	if err != nil {
 		return err
 	}
 	return response.New(ctx, response.Options{Code: fiber.StatusCreated, Data: fiber.Map{
 		"id": newIndex.Id,
+		"warnings": append(
+			ctrl.lintWarnings(indexQuery, newIndex.DatabaseId, newIndex.Collection, newIndex.Name),
+			ctrl.schemaWarnings(database, newIndex.Collection, newIndex.Name, newIndex.Keys)...,
+		),
 	}})
 }
 
@@ -163,6 +185,9 @@ func (ctrl *controller) Get(ctx *fiber.Ctx) error {
 			CaseLevel:       index.Options.Collation.CaseLevel,
 			CaseFirst:       index.Options.Collation.CaseFirst,
 			NumericOrdering: index.Options.Collation.NumericOrdering,
+			Backwards:       index.Options.Collation.Backwards,
+			Alternate:       index.Options.Collation.Alternate,
+			MaxVariable:     index.Options.Collation.MaxVariable,
 		}
 	}
 	return response.New(ctx, response.Options{Data: serializers.IndexGetResponse{
@@ -193,7 +218,6 @@ func (ctrl *controller) ListByCollection(ctx *fiber.Ctx) error {
 		return err
 	}
 	var (
-		err         error
 		indexes     = make([]models.Index, 0)
 		errorChan   = make(chan error, 1)
 		totalChan   = make(chan int64, 1)
@@ -202,7 +226,15 @@ func (ctrl *controller) ListByCollection(ctx *fiber.Ctx) error {
 		pagination  = request.NewPagination(requestBody.Limit, requestBody.Page)
 		result      = make([]serializers.IndexListByCollectionResponseItem, 0)
 	)
-	queryOption.SetPagination(pagination)
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	// Sizes only exist on the target cluster, sorting by them needs every index.
+	if requestBody.SortBy != constants.SortBySize {
+		queryOption.SetPagination(pagination)
+	}
 	queryOption.AddSortKey(map[string]int{
 		"_id": queries.SortTypeDesc,
 	})
@@ -216,6 +248,13 @@ func (ctrl *controller) ListByCollection(ctx *fiber.Ctx) error {
 				}
 				return err
 			}
+			var size *int64
+			mapSize, err := ctrl.service.GetIndexSizes(database, index.Collection, []models.Index{*index})
+			if err != nil {
+				logger.Warn().Err(err).Str("function", "ListByCollection").Str("functionInline", "ctrl.service.GetIndexSizes").Msg("index-controller")
+			} else if value, exists := mapSize[index.Id]; exists {
+				size = &value
+			}
 			keys := make([]serializers.IndexListByCollectionResponseKey, len(index.Keys))
 			for i, key := range index.Keys {
 				keys[i].Field = key.Field
@@ -229,11 +268,15 @@ func (ctrl *controller) ListByCollection(ctx *fiber.Ctx) error {
 					CaseLevel:       index.Options.Collation.CaseLevel,
 					CaseFirst:       index.Options.Collation.CaseFirst,
 					NumericOrdering: index.Options.Collation.NumericOrdering,
+					Backwards:       index.Options.Collation.Backwards,
+					Alternate:       index.Options.Collation.Alternate,
+					MaxVariable:     index.Options.Collation.MaxVariable,
 				}
 			}
 			return response.NewArrayWithPagination(ctx, []serializers.IndexListByCollectionResponseItem{{
 				CreatedAt: index.CreatedAt,
 				UpdatedAt: index.UpdatedAt,
+				Size:      size,
 				Options: serializers.IndexListByCollectionResponseOption{
 					ExpireAfterSeconds: index.Options.ExpireAfterSeconds,
 					IsUnique:           index.Options.IsUnique,
@@ -269,8 +312,18 @@ func (ctrl *controller) ListByCollection(ctx *fiber.Ctx) error {
 	if err = <-errorChan; err != nil {
 		return err
 	}
+	mapSize, err := ctrl.service.GetIndexSizes(database, requestBody.Collection, indexes)
+	if err != nil {
+		if requestBody.SortBy == constants.SortBySize {
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get collection stats from database"})
+		}
+		logger.Warn().Err(err).Str("function", "ListByCollection").Str("functionInline", "ctrl.service.GetIndexSizes").Msg("index-controller")
+	}
 	result = make([]serializers.IndexListByCollectionResponseItem, len(indexes))
 	for i, index := range indexes {
+		if size, exists := mapSize[index.Id]; exists {
+			result[i].Size = &size
+		}
 		keys := make([]serializers.IndexListByCollectionResponseKey, len(index.Keys))
 		for idx, key := range index.Keys {
 			keys[idx].Field = key.Field
@@ -284,6 +337,9 @@ func (ctrl *controller) ListByCollection(ctx *fiber.Ctx) error {
 				CaseLevel:       index.Options.Collation.CaseLevel,
 				CaseFirst:       index.Options.Collation.CaseFirst,
 				NumericOrdering: index.Options.Collation.NumericOrdering,
+				Backwards:       index.Options.Collation.Backwards,
+				Alternate:       index.Options.Collation.Alternate,
+				MaxVariable:     index.Options.Collation.MaxVariable,
 			}
 		}
 		result[i].CreatedAt = index.CreatedAt
@@ -301,6 +357,16 @@ func (ctrl *controller) ListByCollection(ctx *fiber.Ctx) error {
 		result[i].DatabaseId = index.DatabaseId
 		result[i].IsDefault = index.IsDefault
 	}
+	if requestBody.SortBy == constants.SortBySize {
+		// Heaviest first, indexes missing on the cluster last.
+		sort.SliceStable(result, func(i, j int) bool {
+			if result[i].Size == nil || result[j].Size == nil {
+				return result[j].Size == nil && result[i].Size != nil
+			}
+			return *result[i].Size > *result[j].Size
+		})
+		result = result[min(pagination.Skip, int64(len(result))):min(pagination.Skip+pagination.Limit, int64(len(result)))]
+	}
 	pagination.SetTotal(<-totalChan)
 	return response.NewArrayWithPagination(ctx, result, pagination)
 }
@@ -324,6 +390,11 @@ func (ctrl *controller) Update(ctx *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(index.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
 	var collation *models.Collation
 	if requestBody.Options.Collation != nil {
 		collation = &models.Collation{
@@ -332,6 +403,9 @@ func (ctrl *controller) Update(ctx *fiber.Ctx) error {
 			CaseLevel:       requestBody.Options.Collation.CaseLevel,
 			CaseFirst:       requestBody.Options.Collation.CaseFirst,
 			NumericOrdering: requestBody.Options.Collation.NumericOrdering,
+			Backwards:       requestBody.Options.Collation.Backwards,
+			Alternate:       requestBody.Options.Collation.Alternate,
+			MaxVariable:     requestBody.Options.Collation.MaxVariable,
 		}
 	}
 	indexUpdate := models.Index{
@@ -353,22 +427,7 @@ func (ctrl *controller) Update(ctx *fiber.Ctx) error {
 	}
 	for i, key := range requestBody.Keys {
 		indexUpdate.Keys[i].Field = key.Field
-		switch v := key.Value.(type) {
-		case float64:
-			if v == 1 || v == -1 {
-				indexUpdate.Keys[i].Value = int32(v)
-			} else {
-				indexUpdate.Keys[i].Value = key.Value
-			}
-		case int:
-			if v == 1 || v == -1 {
-				indexUpdate.Keys[i].Value = int32(v)
-			} else {
-				indexUpdate.Keys[i].Value = key.Value
-			}
-		default:
-			indexUpdate.Keys[i].Value = key.Value
-		}
+		indexUpdate.Keys[i].Value = indexspec.NormalizeValue(key.Value)
 		listKeyFieldsUpdate[i] = key.Field
 		if _, exists := mapKeyField[key.Field]; !exists {
 			isSameKeyFields = false
@@ -413,10 +472,286 @@ func (ctrl *controller) Update(ctx *fiber.Ctx) error {
 			return response.New(ctx, response.Options{Code: fiber.StatusConflict, Data: respErr.ErrResourceConflict})
 		}
 	}
+	if indexUpdate.IsText {
+		if err = checkSingleTextIndex(indexQuery, index.DatabaseId, index.Collection, id); err != nil {
+			return err
+		}
+	}
 	if err = indexQuery.UpdateNameKeySignatureOptionsKeysById(id, indexUpdate.Name, indexUpdate.KeySignature, indexUpdate.Options, indexUpdate.Keys); err != nil {
 		return err
 	}
-	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+	return response.New(ctx, response.Options{Data: fiber.Map{
+		"success": true,
+		"warnings": append(
+			ctrl.lintWarnings(indexQuery, index.DatabaseId, index.Collection, indexUpdate.Name),
+			ctrl.schemaWarnings(database, index.Collection, indexUpdate.Name, indexUpdate.Keys)...,
+		),
+	}})
+}
+
+func (ctrl *controller) Lint(ctx *fiber.Ctx) error {
+	var requestBody serializers.IndexLintValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	if _, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption); err != nil {
+		return err
+	}
+	indexQuery := queries.NewIndex(ctx.Context())
+	queryOption.SetOnlyFields("options", "keys", "collection", "name", "is_default", "is_text")
+	var (
+		indexes []models.Index
+		err     error
+	)
+	if len(requestBody.Collections) > 0 {
+		indexes, err = indexQuery.GetByDatabaseIdAndCollections(requestBody.DatabaseId, requestBody.Collections, queryOption)
+	} else {
+		indexes, err = indexQuery.GetByDatabaseId(requestBody.DatabaseId, queryOption)
+	}
+	if err != nil {
+		return err
+	}
+	collections := make([]string, 0)
+	mapIndexByCollection := make(map[string][]models.Index)
+	for _, index := range indexes {
+		if _, exists := mapIndexByCollection[index.Collection]; !exists {
+			collections = append(collections, index.Collection)
+		}
+		mapIndexByCollection[index.Collection] = append(mapIndexByCollection[index.Collection], index)
+	}
+	sort.Strings(collections)
+	results := make([]serializers.IndexLintResponseItem, len(collections))
+	for i, collection := range collections {
+		results[i] = serializers.IndexLintResponseItem{
+			Collection: collection,
+			Issues:     newIndexLintIssues(lint.GetGlobal().Lint(mapIndexByCollection[collection]), ""),
+		}
+	}
+	return response.NewArrayWithPagination(ctx, results, &request.Pagination{})
+}
+
+func (ctrl *controller) CheckDuplicates(ctx *fiber.Ctx) error {
+	var requestBody serializers.IndexCheckDuplicatesValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	limit := cfg.DuplicateCheckLimit
+	if requestBody.Limit > 0 {
+		limit = requestBody.Limit
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	indexQuery := queries.NewIndex(ctx.Context())
+	queryOption.SetOnlyFields("options", "keys", "key_signature", "collection", "name")
+	var indexes []models.Index
+	if len(requestBody.Collections) > 0 {
+		indexes, err = indexQuery.GetByDatabaseIdAndCollections(requestBody.DatabaseId, requestBody.Collections, queryOption)
+	} else {
+		indexes, err = indexQuery.GetByDatabaseId(requestBody.DatabaseId, queryOption)
+	}
+	if err != nil {
+		return err
+	}
+	collections := make([]string, 0)
+	mapCollection := make(map[string]struct{})
+	for _, index := range indexes {
+		if _, exists := mapCollection[index.Collection]; !exists && index.Options.IsUnique {
+			collections = append(collections, index.Collection)
+			mapCollection[index.Collection] = struct{}{}
+		}
+	}
+	result := serializers.IndexCheckDuplicatesResponse{
+		Violations: make([]serializers.IndexCheckDuplicatesViolation, 0),
+	}
+	if len(collections) == 0 {
+		return response.New(ctx, response.Options{Data: result})
+	}
+	checked, violations, err := ctrl.service.FindUniqueViolations(database, collections, indexes, limit)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "CheckDuplicates").Str("functionInline", "ctrl.service.FindUniqueViolations").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot check duplicates on database"})
+	}
+	result.Checked = checked
+	for _, violation := range violations {
+		duplicates := make([]serializers.IndexCheckDuplicatesDuplicate, len(violation.Duplicates))
+		for i, duplicate := range violation.Duplicates {
+			values := make([]serializers.IndexCompareByDatabaseIndexKey, len(duplicate.Values))
+			for j, value := range duplicate.Values {
+				values[j] = serializers.IndexCompareByDatabaseIndexKey{Field: value.Key, Value: value.Value}
+			}
+			duplicates[i] = serializers.IndexCheckDuplicatesDuplicate{
+				Values: values,
+				Count:  duplicate.Count,
+			}
+		}
+		result.Violations = append(result.Violations, serializers.IndexCheckDuplicatesViolation{
+			Collection: violation.Collection,
+			Name:       violation.Name,
+			Duplicates: duplicates,
+		})
+	}
+	return response.New(ctx, response.Options{Data: result})
+}
+
+// ShardConsistency compares the indexes each shard reports for a collection with the declared
+// ones, by key signature, and with the other shards. Mongos merges listIndexes across shards,
+// which hides a shard missing or carrying an index.
+func (ctrl *controller) ShardConsistency(ctx *fiber.Ctx) error {
+	var requestBody serializers.IndexShardConsistencyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	indexQuery := queries.NewIndex(ctx.Context())
+	queryOption.SetOnlyFields("key_signature", "collection", "name")
+	var indexes []models.Index
+	if len(requestBody.Collections) > 0 {
+		indexes, err = indexQuery.GetByDatabaseIdAndCollections(requestBody.DatabaseId, requestBody.Collections, queryOption)
+	} else {
+		indexes, err = indexQuery.GetByDatabaseId(requestBody.DatabaseId, queryOption)
+	}
+	if err != nil {
+		return err
+	}
+	mapDeclared := make(map[string]map[string]string)
+	for _, index := range indexes {
+		if _, exists := mapDeclared[index.Collection]; !exists {
+			mapDeclared[index.Collection] = make(map[string]string)
+		}
+		mapDeclared[index.Collection][index.KeySignature] = index.Name
+	}
+
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ShardConsistency").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	shardIndexes, err := dbClient.GetShardIndexes(database.DBName, requestBody.Collections)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrNotSharded) {
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Database is not a sharded cluster"})
+		}
+		logger.Error().Err(err).Str("function", "ShardConsistency").Str("functionInline", "dbClient.GetShardIndexes").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from shards"})
+	}
+	collections := make([]string, 0)
+	mapShardIndexes := make(map[string][]mongodb.ShardIndexes)
+	for _, item := range shardIndexes {
+		if _, exists := mapShardIndexes[item.Collection]; !exists {
+			collections = append(collections, item.Collection)
+		}
+		mapShardIndexes[item.Collection] = append(mapShardIndexes[item.Collection], item)
+	}
+	sort.Strings(collections)
+	results := make([]serializers.IndexShardConsistencyResponseItem, len(collections))
+	for i, collection := range collections {
+		items := mapShardIndexes[collection]
+		sort.Slice(items, func(a, b int) bool {
+			return items[a].Shard < items[b].Shard
+		})
+		result := serializers.IndexShardConsistencyResponseItem{
+			Collection:   collection,
+			Shards:       make([]serializers.IndexShardConsistencyShard, len(items)),
+			Inconsistent: make([]serializers.IndexShardConsistencyIndex, 0),
+			IsConsistent: true,
+		}
+		signatures := make([]string, 0)
+		mapName := make(map[string]string)
+		mapPresent := make(map[string][]string)
+		for j, item := range items {
+			shard := serializers.IndexShardConsistencyShard{
+				Shard:   item.Shard,
+				Missing: make([]string, 0),
+				Extra:   make([]string, 0),
+			}
+			mapLive := make(map[string]struct{}, len(item.Indexes))
+			for _, index := range item.Indexes {
+				mapLive[index.KeySignature] = struct{}{}
+				if _, exists := mapPresent[index.KeySignature]; !exists {
+					signatures = append(signatures, index.KeySignature)
+					mapName[index.KeySignature] = index.Name
+				}
+				mapPresent[index.KeySignature] = append(mapPresent[index.KeySignature], item.Shard)
+				if _, exists := mapDeclared[collection][index.KeySignature]; !exists {
+					shard.Extra = append(shard.Extra, index.Name)
+				}
+			}
+			for signature, name := range mapDeclared[collection] {
+				if _, exists := mapLive[signature]; !exists {
+					shard.Missing = append(shard.Missing, name)
+				}
+			}
+			sort.Strings(shard.Missing)
+			sort.Strings(shard.Extra)
+			result.IsConsistent = result.IsConsistent && len(shard.Missing) == 0 && len(shard.Extra) == 0
+			result.Shards[j] = shard
+		}
+		for _, signature := range signatures {
+			if len(mapPresent[signature]) == len(items) {
+				continue
+			}
+			index := serializers.IndexShardConsistencyIndex{
+				Name:         mapName[signature],
+				KeySignature: signature,
+				PresentOn:    mapPresent[signature],
+				MissingOn:    make([]string, 0),
+			}
+			for _, item := range items {
+				if !slices.Contains(index.PresentOn, item.Shard) {
+					index.MissingOn = append(index.MissingOn, item.Shard)
+				}
+			}
+			result.Inconsistent = append(result.Inconsistent, index)
+			result.IsConsistent = false
+		}
+		results[i] = result
+	}
+	return response.NewArrayWithPagination(ctx, results, &request.Pagination{})
+}
+
+// lintWarnings lints the collection of a saved index and keeps the issues involving it.
+// Linting never fails the write, errors are logged and give no warnings.
+func (ctrl *controller) lintWarnings(indexQuery queries.IndexQuery, databaseId primitive.ObjectID, collection, name string) []serializers.IndexLintIssue {
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("options", "keys", "collection", "name", "is_default", "is_text")
+	indexes, err := indexQuery.GetByDatabaseIdAndCollection(databaseId, collection, queryOption)
+	if err != nil {
+		logger.Warn().Err(err).Str("function", "lintWarnings").Str("functionInline", "indexQuery.GetByDatabaseIdAndCollection").Msg("index-controller")
+		return []serializers.IndexLintIssue{}
+	}
+	return newIndexLintIssues(lint.GetGlobal().Lint(indexes), name)
+}
+
+// schemaWarnings checks the keys of a saved index against a sample of its collection.
+// Sampling never fails the write, errors are logged and give no warnings.
+func (ctrl *controller) schemaWarnings(database *models.Database, collection, name string, keys []models.IndexKey) []serializers.IndexLintIssue {
+	mapSchema, err := ctrl.service.GetSchemas(database, []string{collection}, cfg.SchemaSampleSize)
+	if err != nil {
+		logger.Warn().Err(err).Str("function", "schemaWarnings").Str("functionInline", "ctrl.service.GetSchemas").Msg("index-controller")
+		return []serializers.IndexLintIssue{}
+	}
+	return newIndexSchemaIssues(mapSchema[collection].CheckKeys(keys), name)
 }
 
 func (ctrl *controller) Delete(ctx *fiber.Ctx) error {
@@ -450,7 +785,7 @@ func (ctrl *controller) CompareByCollections(ctx *fiber.Ctx) error {
 		return err
 	}
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("uri", "db_name")
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
 	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
 	if err != nil {
 		return err
@@ -469,16 +804,36 @@ func (ctrl *controller) CompareByCollections(ctx *fiber.Ctx) error {
 		mapIndexManager[index.Collection][index.KeySignature] = index
 	}
 
-	dbClient, err := mongodb.New(database.Uri)
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
 	if err != nil {
-		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "mongodb.New").Msg("index-controller")
+		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
 	}
+	defer releaseClient()
 	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, requestBody.Collections)
 	if err != nil {
 		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "dbClient.GetIndexesByDbNameAndCollections").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from database"})
 	}
+	infos, err := dbClient.GetCollectionInfos(database.DBName, requestBody.Collections)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "dbClient.GetCollectionInfos").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get collections from database"})
+	}
+	mapInfo := make(map[string]mongodb.CollectionInfo, len(infos))
+	for _, info := range infos {
+		mapInfo[info.Name] = info
+	}
+	sampledCollections := make([]string, 0, len(requestBody.Collections))
+	for _, collection := range requestBody.Collections {
+		if mapInfo[collection].Type != "" && len(mapIndexManager[collection]) > 0 {
+			sampledCollections = append(sampledCollections, collection)
+		}
+	}
+	mapDocuments := sampleDocuments(dbClient, database.DBName, sampledCollections, cfg.SchemaSampleSize)
+	mapSchema := inferSchemas(mapDocuments)
+	estimator := newBuildEstimator(dbClient, database.DBName, sampledCollections, mapDocuments)
+	var totalBuild estimate.Build
 	mapIndexClient := make(map[string]map[string]mongodb.Index)
 	for _, index := range clientIndexes {
 		if _, exists := mapIndexClient[index.Collection]; !exists {
@@ -488,12 +843,20 @@ func (ctrl *controller) CompareByCollections(ctx *fiber.Ctx) error {
 	}
 	result := make([]serializers.IndexCompareByCollectionsResponseItem, 0, len(requestBody.Collections))
 	for _, collection := range requestBody.Collections {
+		info := mapInfo[collection]
 		compareItem := serializers.IndexCompareByCollectionsResponseItem{
+			Type:             info.Type,
+			IsCapped:         info.IsCapped,
+			IsClustered:      info.IsClustered,
 			Collection:       collection,
 			MissingIndexes:   make([]serializers.IndexCompareByCollectionsIndex, 0),
 			MatchedIndexes:   make([]serializers.IndexCompareByCollectionsIndex, 0),
 			RedundantIndexes: make([]serializers.IndexCompareByCollectionsIndex, 0),
 		}
+		var (
+			collectionBuild estimate.Build
+			hasEstimate     bool
+		)
 		for _, index := range mapIndexManager[collection] {
 			keys := make([]serializers.IndexCompareByCollectionsIndexKey, len(index.Keys))
 			for i, key := range index.Keys {
@@ -508,6 +871,9 @@ func (ctrl *controller) CompareByCollections(ctx *fiber.Ctx) error {
 					CaseLevel:       index.Options.Collation.CaseLevel,
 					CaseFirst:       index.Options.Collation.CaseFirst,
 					NumericOrdering: index.Options.Collation.NumericOrdering,
+					Backwards:       index.Options.Collation.Backwards,
+					Alternate:       index.Options.Collation.Alternate,
+					MaxVariable:     index.Options.Collation.MaxVariable,
 				}
 			}
 			indexItem := serializers.IndexCompareByCollectionsIndex{
@@ -522,14 +888,24 @@ func (ctrl *controller) CompareByCollections(ctx *fiber.Ctx) error {
 				Keys:         keys,
 				KeySignature: index.KeySignature,
 			}
+			indexItem.Warnings = newIndexSchemaIssues(mapSchema[collection].CheckKeys(index.Keys), index.Name)
 			if _, exists := mapIndexClient[collection][index.KeySignature]; exists {
 				compareItem.MatchedIndexes = append(compareItem.MatchedIndexes, indexItem)
 				delete(mapIndexClient[collection], index.KeySignature)
 			} else {
+				indexItem.Unsupported = info.CheckIndex(mongodb.NewIndexFromModel(index))
+				if build := estimator.estimate(index); build != nil && indexItem.Unsupported == "" {
+					indexItem.BuildEstimate = newIndexBuildEstimate(*build, estimator.exceedsCache(build.IndexSize))
+					collectionBuild = collectionBuild.Add(*build)
+					hasEstimate = true
+				}
 				compareItem.MissingIndexes = append(compareItem.MissingIndexes, indexItem)
 			}
 		}
 		for _, index := range mapIndexClient[collection] {
+			if info.IsBuiltinIndex(index) {
+				continue
+			}
 			keys := make([]serializers.IndexCompareByCollectionsIndexKey, len(index.Keys))
 			for i, key := range index.Keys {
 				keys[i].Field = key.Field
@@ -543,6 +919,9 @@ func (ctrl *controller) CompareByCollections(ctx *fiber.Ctx) error {
 					CaseLevel:       index.Options.Collation.CaseLevel,
 					CaseFirst:       index.Options.Collation.CaseFirst,
 					NumericOrdering: index.Options.Collation.NumericOrdering,
+					Backwards:       index.Options.Collation.Backwards,
+					Alternate:       index.Options.Collation.Alternate,
+					MaxVariable:     index.Options.Collation.MaxVariable,
 				}
 			}
 			compareItem.RedundantIndexes = append(compareItem.RedundantIndexes, serializers.IndexCompareByCollectionsIndex{
@@ -558,9 +937,17 @@ func (ctrl *controller) CompareByCollections(ctx *fiber.Ctx) error {
 				KeySignature: index.KeySignature,
 			})
 		}
+		if hasEstimate {
+			compareItem.BuildEstimate = newIndexBuildEstimate(collectionBuild, estimator.exceedsCache(collectionBuild.IndexSize))
+			totalBuild = totalBuild.Add(collectionBuild)
+		}
 		result = append(result, compareItem)
 	}
-	return response.NewArrayWithPagination(ctx, result, &request.Pagination{})
+	return response.New(ctx, response.Options{Data: result, Extra: fiber.Map{
+		"limit":          0,
+		"page":           0,
+		"build_estimate": newIndexBuildEstimateTotal(totalBuild, estimator),
+	}})
 }
 
 func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
@@ -572,7 +959,7 @@ func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
 		return err
 	}
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("uri", "db_name")
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
 	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
 	if err != nil {
 		return err
@@ -597,16 +984,36 @@ func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
 		mapIndexManager[index.Collection][index.KeySignature] = index
 	}
 
-	dbClient, err := mongodb.New(database.Uri)
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
 	if err != nil {
-		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "mongodb.New").Msg("index-controller")
+		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
 	}
+	defer releaseClient()
 	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, collections)
 	if err != nil {
 		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "dbClient.GetIndexesByDbNameAndCollections").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from database"})
 	}
+	infos, err := dbClient.GetCollectionInfos(database.DBName, collections)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "CompareByDatabase").Str("functionInline", "dbClient.GetCollectionInfos").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get collections from database"})
+	}
+	mapInfo := make(map[string]mongodb.CollectionInfo, len(infos))
+	for _, info := range infos {
+		mapInfo[info.Name] = info
+	}
+	sampledCollections := make([]string, 0, len(collections))
+	for _, collection := range collections {
+		if mapInfo[collection].Type != "" && len(mapIndexManager[collection]) > 0 {
+			sampledCollections = append(sampledCollections, collection)
+		}
+	}
+	mapDocuments := sampleDocuments(dbClient, database.DBName, sampledCollections, cfg.SchemaSampleSize)
+	mapSchema := inferSchemas(mapDocuments)
+	estimator := newBuildEstimator(dbClient, database.DBName, sampledCollections, mapDocuments)
+	var totalBuild estimate.Build
 	mapIndexClient := make(map[string]map[string]mongodb.Index)
 	for _, index := range clientIndexes {
 		if _, exists := mapIndexClient[index.Collection]; !exists {
@@ -616,12 +1023,20 @@ func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
 	}
 	result := make([]serializers.IndexCompareByDatabaseResponseItem, 0, len(collections))
 	for _, collection := range collections {
+		info := mapInfo[collection]
 		compareItem := serializers.IndexCompareByDatabaseResponseItem{
+			Type:             info.Type,
+			IsCapped:         info.IsCapped,
+			IsClustered:      info.IsClustered,
 			Collection:       collection,
 			MissingIndexes:   make([]serializers.IndexCompareByDatabaseIndex, 0),
 			MatchedIndexes:   make([]serializers.IndexCompareByDatabaseIndex, 0),
 			RedundantIndexes: make([]serializers.IndexCompareByDatabaseIndex, 0),
 		}
+		var (
+			collectionBuild estimate.Build
+			hasEstimate     bool
+		)
 		for _, index := range mapIndexManager[collection] {
 			keys := make([]serializers.IndexCompareByDatabaseIndexKey, len(index.Keys))
 			for i, key := range index.Keys {
@@ -636,6 +1051,9 @@ func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
 					CaseLevel:       index.Options.Collation.CaseLevel,
 					CaseFirst:       index.Options.Collation.CaseFirst,
 					NumericOrdering: index.Options.Collation.NumericOrdering,
+					Backwards:       index.Options.Collation.Backwards,
+					Alternate:       index.Options.Collation.Alternate,
+					MaxVariable:     index.Options.Collation.MaxVariable,
 				}
 			}
 			indexItem := serializers.IndexCompareByDatabaseIndex{
@@ -650,14 +1068,24 @@ func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
 				Keys:         keys,
 				KeySignature: index.KeySignature,
 			}
+			indexItem.Warnings = newIndexSchemaIssues(mapSchema[collection].CheckKeys(index.Keys), index.Name)
 			if _, exists := mapIndexClient[collection][index.KeySignature]; exists {
 				compareItem.MatchedIndexes = append(compareItem.MatchedIndexes, indexItem)
 				delete(mapIndexClient[collection], index.KeySignature)
 			} else {
+				indexItem.Unsupported = info.CheckIndex(mongodb.NewIndexFromModel(index))
+				if build := estimator.estimate(index); build != nil && indexItem.Unsupported == "" {
+					indexItem.BuildEstimate = newIndexBuildEstimate(*build, estimator.exceedsCache(build.IndexSize))
+					collectionBuild = collectionBuild.Add(*build)
+					hasEstimate = true
+				}
 				compareItem.MissingIndexes = append(compareItem.MissingIndexes, indexItem)
 			}
 		}
 		for _, index := range mapIndexClient[collection] {
+			if info.IsBuiltinIndex(index) {
+				continue
+			}
 			keys := make([]serializers.IndexCompareByDatabaseIndexKey, len(index.Keys))
 			for i, key := range index.Keys {
 				keys[i].Field = key.Field
@@ -671,6 +1099,9 @@ func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
 					CaseLevel:       index.Options.Collation.CaseLevel,
 					CaseFirst:       index.Options.Collation.CaseFirst,
 					NumericOrdering: index.Options.Collation.NumericOrdering,
+					Backwards:       index.Options.Collation.Backwards,
+					Alternate:       index.Options.Collation.Alternate,
+					MaxVariable:     index.Options.Collation.MaxVariable,
 				}
 			}
 			compareItem.RedundantIndexes = append(compareItem.RedundantIndexes, serializers.IndexCompareByDatabaseIndex{
@@ -686,13 +1117,355 @@ func (ctrl *controller) CompareByDatabase(ctx *fiber.Ctx) error {
 				KeySignature: index.KeySignature,
 			})
 		}
+		if hasEstimate {
+			compareItem.BuildEstimate = newIndexBuildEstimate(collectionBuild, estimator.exceedsCache(collectionBuild.IndexSize))
+			totalBuild = totalBuild.Add(collectionBuild)
+		}
 		result = append(result, compareItem)
 	}
-	return response.NewArrayWithPagination(ctx, result, &request.Pagination{})
+	return response.New(ctx, response.Options{Data: result, Extra: fiber.Map{
+		"limit":          0,
+		"page":           0,
+		"build_estimate": newIndexBuildEstimateTotal(totalBuild, estimator),
+	}})
+}
+
+// UsageByDatabase reports $indexStats next to the declared indexes. An index is unused when
+// it has no operation and has been counted on every member for the whole window.
+func (ctrl *controller) UsageByDatabase(ctx *fiber.Ctx) error {
+	var requestBody serializers.IndexUsageByDatabaseValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	unusedWindow := cfg.IndexUnusedWindow
+	if requestBody.UnusedWindowHours > 0 {
+		unusedWindow = time.Duration(requestBody.UnusedWindowHours) * time.Hour
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	indexQuery := queries.NewIndex(ctx.Context())
+	queryOption.SetOnlyFields("options", "keys", "key_signature", "collection", "name", "is_default")
+	var indexes []models.Index
+	if len(requestBody.Collections) > 0 {
+		indexes, err = indexQuery.GetByDatabaseIdAndCollections(requestBody.DatabaseId, requestBody.Collections, queryOption)
+	} else {
+		indexes, err = indexQuery.GetByDatabaseId(requestBody.DatabaseId, queryOption)
+	}
+	if err != nil {
+		return err
+	}
+	collections := make([]string, 0)
+	mapCollection := make(map[string]struct{})
+	for _, collection := range requestBody.Collections {
+		collections = append(collections, collection)
+		mapCollection[collection] = struct{}{}
+	}
+	for _, index := range indexes {
+		if _, exists := mapCollection[index.Collection]; !exists {
+			collections = append(collections, index.Collection)
+			mapCollection[index.Collection] = struct{}{}
+		}
+	}
+	if len(collections) == 0 {
+		return response.NewArrayWithPagination(ctx, []serializers.IndexUsageByDatabaseResponseItem{}, &request.Pagination{})
+	}
+
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UsageByDatabase").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, collections)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UsageByDatabase").Str("functionInline", "dbClient.GetIndexesByDbNameAndCollections").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from database"})
+	}
+	stats, unavailableMembers, err := dbClient.GetIndexStats(database.DBName, collections)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UsageByDatabase").Str("functionInline", "dbClient.GetIndexStats").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get index stats from database"})
+	}
+	mapStat := make(map[string]map[string]mongodb.IndexStat)
+	for _, stat := range stats {
+		if _, exists := mapStat[stat.Collection]; !exists {
+			mapStat[stat.Collection] = make(map[string]mongodb.IndexStat)
+		}
+		mapStat[stat.Collection][stat.Name] = stat
+	}
+	mapIndexClient := make(map[string]map[string]mongodb.Index)
+	for _, index := range clientIndexes {
+		if _, exists := mapIndexClient[index.Collection]; !exists {
+			mapIndexClient[index.Collection] = make(map[string]mongodb.Index)
+		}
+		mapIndexClient[index.Collection][index.KeySignature] = index
+	}
+	mapIndexManager := make(map[string][]models.Index)
+	for _, index := range indexes {
+		mapIndexManager[index.Collection] = append(mapIndexManager[index.Collection], index)
+	}
+	unusedBefore := time.Now().Add(-unusedWindow)
+	// Without the ops of every member an index cannot be told unused.
+	isComplete := len(unavailableMembers) == 0
+	result := make([]serializers.IndexUsageByDatabaseResponseItem, 0, len(collections))
+	for _, collection := range collections {
+		usageItem := serializers.IndexUsageByDatabaseResponseItem{
+			Collection: collection,
+			Indexes:    make([]serializers.IndexUsageByDatabaseIndex, 0),
+		}
+		for _, index := range mapIndexManager[collection] {
+			id := index.Id
+			indexItem := serializers.IndexUsageByDatabaseIndex{
+				Id:           &id,
+				Options:      newIndexCompareOption(index.Options.ExpireAfterSeconds, index.Options.IsUnique, index.Options.DefaultLanguage, index.Options.Weights, index.Options.Collation),
+				Name:         index.Name,
+				Keys:         make([]serializers.IndexCompareByDatabaseIndexKey, len(index.Keys)),
+				KeySignature: index.KeySignature,
+				IsDeclared:   true,
+			}
+			for i, key := range index.Keys {
+				indexItem.Keys[i].Field = key.Field
+				indexItem.Keys[i].Value = key.Value
+			}
+			clientName := index.Name
+			if !index.IsDefault {
+				clientIndex, exists := mapIndexClient[collection][index.KeySignature]
+				if !exists {
+					usageItem.Indexes = append(usageItem.Indexes, indexItem)
+					continue
+				}
+				clientName = clientIndex.Name
+				delete(mapIndexClient[collection], index.KeySignature)
+			}
+			if stat, exists := mapStat[collection][clientName]; exists {
+				indexItem.Usage = newIndexUsage(stat)
+				indexItem.IsUnused = isComplete && stat.Ops == 0 && !stat.Since.After(unusedBefore)
+			}
+			usageItem.Indexes = append(usageItem.Indexes, indexItem)
+		}
+		for _, index := range mapIndexClient[collection] {
+			var collation *models.Collation
+			if index.Options.Collation != nil {
+				collation = (*models.Collation)(index.Options.Collation)
+			}
+			indexItem := serializers.IndexUsageByDatabaseIndex{
+				Options:      newIndexCompareOption(index.Options.ExpireAfterSeconds, index.Options.IsUnique, index.Options.DefaultLanguage, index.Options.Weights, collation),
+				Name:         index.Name,
+				Keys:         make([]serializers.IndexCompareByDatabaseIndexKey, len(index.Keys)),
+				KeySignature: index.KeySignature,
+			}
+			for i, key := range index.Keys {
+				indexItem.Keys[i].Field = key.Field
+				indexItem.Keys[i].Value = key.Value
+			}
+			if stat, exists := mapStat[collection][index.Name]; exists {
+				indexItem.Usage = newIndexUsage(stat)
+				indexItem.IsUnused = isComplete && stat.Ops == 0 && !stat.Since.After(unusedBefore)
+			}
+			usageItem.Indexes = append(usageItem.Indexes, indexItem)
+		}
+		result = append(result, usageItem)
+	}
+	unavailable := make([]serializers.IndexUsageUnavailableMember, len(unavailableMembers))
+	for i, member := range unavailableMembers {
+		unavailable[i] = serializers.IndexUsageUnavailableMember{
+			Host:  member.Host,
+			Error: member.Error,
+		}
+	}
+	pagination := request.Pagination{}
+	return response.New(ctx, response.Options{Data: result, Extra: fiber.Map{
+		"limit":               pagination.Limit,
+		"page":                pagination.Page,
+		"unavailable_members": unavailable,
+	}})
+}
+
+// AdvisorFromProfile proposes indexes from the slow operations recorded by the profiler
+// of the target database.
+func (ctrl *controller) AdvisorFromProfile(ctx *fiber.Ctx) error {
+	var requestBody serializers.IndexAdvisorProfileValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	profileOption := mongodb.ProfileOption{
+		Collections: requestBody.Collections,
+		MinMillis:   requestBody.MinMillis,
+		Limit:       cfg.AdvisorProfileLimit,
+	}
+	if requestBody.Since != nil {
+		profileOption.Since = *requestBody.Since
+	}
+	if requestBody.Limit > 0 {
+		profileOption.Limit = requestBody.Limit
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "AdvisorFromProfile").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	entries, err := dbClient.GetProfileEntries(database.DBName, profileOption)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "AdvisorFromProfile").Str("functionInline", "dbClient.GetProfileEntries").Msg("index-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot read system.profile from database"})
+	}
+	advisorService := advisor.New(advisor.Option{MinExaminedRatio: ctrl.minExaminedRatio(requestBody.MinExaminedRatio)})
+	operations := make([]advisor.Operation, 0, len(entries))
+	for _, entry := range entries {
+		if operation, ok := advisorService.ParseProfileEntry(entry); ok {
+			operations = append(operations, operation)
+		}
+	}
+	return ctrl.advise(ctx, advisorService, requestBody.DatabaseId, operations)
+}
+
+// AdvisorFromLog proposes indexes from "Slow query" lines of the structured server log.
+// Lines of other databases or messages are ignored.
+func (ctrl *controller) AdvisorFromLog(ctx *fiber.Ctx) error {
+	var requestBody serializers.IndexAdvisorLogValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("db_name")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	advisorService := advisor.New(advisor.Option{MinExaminedRatio: ctrl.minExaminedRatio(requestBody.MinExaminedRatio)})
+	operations := make([]advisor.Operation, 0)
+	for _, line := range strings.Split(requestBody.Log, "\n") {
+		operation, ok := advisorService.ParseLogLine(line)
+		if !ok || (operation.Database != "" && operation.Database != database.DBName) {
+			continue
+		}
+		operations = append(operations, operation)
+	}
+	return ctrl.advise(ctx, advisorService, requestBody.DatabaseId, operations)
+}
+
+func (ctrl *controller) minExaminedRatio(value float64) float64 {
+	if value > 0 {
+		return value
+	}
+	return cfg.AdvisorMinExaminedRatio
+}
+
+func (ctrl *controller) advise(ctx *fiber.Ctx, advisorService advisor.Service, databaseId primitive.ObjectID, operations []advisor.Operation) error {
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("keys", "collection", "name")
+	indexes, err := queries.NewIndex(ctx.Context()).GetByDatabaseId(databaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	result := advisorService.Analyze(operations, indexes)
+	data := serializers.IndexAdvisorResponse{
+		Shapes:    make([]serializers.IndexAdvisorShape, len(result.Shapes)),
+		Proposals: make([]serializers.IndexAdvisorProposal, len(result.Proposals)),
+		Analyzed:  len(operations),
+	}
+	for i, shape := range result.Shapes {
+		data.Shapes[i] = serializers.IndexAdvisorShape{
+			PlanSummaries: shape.PlanSummaries,
+			Collection:    shape.Collection,
+			Shape:         shape.Shape,
+			Reason:        shape.Reason,
+			Count:         shape.Count,
+			TotalMillis:   shape.TotalMillis,
+			MaxMillis:     shape.MaxMillis,
+			KeysExamined:  shape.KeysExamined,
+			DocsExamined:  shape.DocsExamined,
+			Returned:      shape.Returned,
+			CollScans:     shape.CollScans,
+			IsSlow:        shape.IsSlow,
+		}
+	}
+	for i, proposal := range result.Proposals {
+		keys := make([]serializers.IndexCompareByDatabaseIndexKey, len(proposal.Index.Keys))
+		for idx, key := range proposal.Index.Keys {
+			keys[idx].Field = key.Field
+			keys[idx].Value = key.Value
+		}
+		data.Proposals[i] = serializers.IndexAdvisorProposal{
+			CoveredBy:    proposal.CoveredBy,
+			Collection:   proposal.Index.Collection,
+			Name:         proposal.Index.Name,
+			KeySignature: proposal.Index.KeySignature,
+			Keys:         keys,
+			Shapes:       proposal.Shapes,
+		}
+	}
+	return response.New(ctx, response.Options{Data: data})
+}
+
+// AdvisorAccept stores a proposal as a declared index, with the same conflict checks as
+// Create.
+func (ctrl *controller) AdvisorAccept(ctx *fiber.Ctx) error {
+	var requestBody serializers.IndexAdvisorAcceptValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	if _, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption); err != nil {
+		return err
+	}
+	index := models.Index{
+		Collection: requestBody.Collection,
+		Name:       requestBody.Name,
+		Keys:       make([]models.IndexKey, len(requestBody.Keys)),
+		DatabaseId: requestBody.DatabaseId,
+	}
+	for i, key := range requestBody.Keys {
+		index.Keys[i] = models.IndexKey{Field: key.Field, Value: indexspec.NormalizeValue(key.Value)}
+	}
+	index.KeySignature = index.GetKeySignature()
+	if index.Name == "" {
+		index.Name = index.KeySignature
+	}
+	indexQuery := queries.NewIndex(ctx.Context())
+	if _, err := indexQuery.GetByDatabaseIdCollectionWithNameOrSignature(requestBody.DatabaseId, requestBody.Collection, index.KeySignature, index.Name, queryOption); err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
+			return err
+		}
+	} else {
+		return response.New(ctx, response.Options{Code: fiber.StatusConflict, Data: respErr.ErrResourceConflict})
+	}
+	newIndex, err := indexQuery.CreateOne(index)
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Code: fiber.StatusCreated, Data: fiber.Map{
+		"id":       newIndex.Id,
+		"warnings": ctrl.lintWarnings(indexQuery, newIndex.DatabaseId, newIndex.Collection, newIndex.Name),
+	}})
 }
 
 func (ctrl *controller) SyncByCollections(ctx *fiber.Ctx) error {
-	var requestBody serializers.IndexCompareByCollectionsValidate
+	var requestBody serializers.IndexSyncByCollectionsValidate
 	if err := ctx.BodyParser(&requestBody); err != nil {
 		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
 	}
@@ -700,7 +1473,7 @@ func (ctrl *controller) SyncByCollections(ctx *fiber.Ctx) error {
 		return err
 	}
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("uri", "db_name")
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
 	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
 	if err != nil {
 		return err
@@ -729,11 +1502,12 @@ func (ctrl *controller) SyncByCollections(ctx *fiber.Ctx) error {
 		mapIndexManager[index.Collection][index.KeySignature] = index
 	}
 
-	dbClient, err := mongodb.New(database.Uri)
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
 	if err != nil {
-		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "mongodb.New").Msg("index-controller")
+		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Can't connect to database"})
 	}
+	defer releaseClient()
 	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, requestBody.Collections)
 	if err != nil {
 		logger.Error().Err(err).Str("function", "CompareByCollections").Str("functionInline", "dbClient.GetIndexesByDbNameAndCollections").Msg("index-controller")
@@ -742,12 +1516,14 @@ func (ctrl *controller) SyncByCollections(ctx *fiber.Ctx) error {
 	if len(clientIndexes)+len(indexes) == 0 {
 		return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
 	}
+	removalMode, gracePeriod := syncRemoval(requestBody.RemovalMode, requestBody.GracePeriodSeconds)
 	sync, err := syncQuery.CreateOne(models.Sync{
 		Error:       "",
 		Collections: requestBody.Collections,
 		DatabaseID:  requestBody.DatabaseId,
 		IsFinished:  false,
 		Status:      constants.SyncStatusPending,
+		RemovalMode: removalMode,
 		Progress:    0,
 		StartedAt:   time.Now(),
 	})
@@ -760,7 +1536,10 @@ func (ctrl *controller) SyncByCollections(ctx *fiber.Ctx) error {
 		ServerIndexes: indexes,
 		Uri:           database.Uri,
 		DBName:        database.DBName,
+		DatabaseId:    requestBody.DatabaseId,
 		SyncId:        sync.Id,
+		RemovalMode:   removalMode,
+		GracePeriod:   gracePeriod,
 	})
 	taskQueue := taskqueue.GetGlobal()
 	if _, err = taskQueue.EnqueueTask(taskQueue.NewTask(taskqueue.TaskTypeSyncIndexByCollection, payloadData)); err != nil {
@@ -781,18 +1560,38 @@ func (ctrl *controller) GetSyncStatus(ctx *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	indexBuilds := make([]serializers.IndexSyncBuild, len(sync.IndexBuilds))
+	for i, build := range sync.IndexBuilds {
+		indexBuilds[i] = serializers.IndexSyncBuild{
+			UpdatedAt:   build.UpdatedAt,
+			Collection:  build.Collection,
+			Host:        build.Host,
+			Shard:       build.Shard,
+			Phase:       build.Phase,
+			Indexes:     build.Indexes,
+			Done:        build.Done,
+			Total:       build.Total,
+			SecsRunning: build.SecsRunning,
+		}
+	}
 	return response.New(ctx, response.Options{Data: serializers.IndexSyncStatusResponse{
-		Id:          sync.Id,
-		DatabaseId:  sync.DatabaseID,
-		Status:      sync.Status,
-		Progress:    sync.Progress,
-		Error:       sync.Error,
-		Collections: sync.Collections,
-		IsFinished:  sync.IsFinished,
-		StartedAt:   sync.StartedAt,
-		CompletedAt: sync.CompletedAt,
-		CreatedAt:   sync.CreatedAt,
-		UpdatedAt:   sync.UpdatedAt,
+		Id:                sync.Id,
+		DatabaseId:        sync.DatabaseID,
+		Status:            sync.Status,
+		Progress:          sync.Progress,
+		Error:             sync.Error,
+		RemovalMode:       cmp.Or(sync.RemovalMode, constants.SyncRemovalModeDrop),
+		RemovalStatus:     sync.RemovalStatus,
+		RemovalError:      sync.RemovalError,
+		Collections:       sync.Collections,
+		IndexBuilds:       indexBuilds,
+		HiddenIndexes:     newIndexSyncHiddenIndexes(sync.HiddenIndexes),
+		IsFinished:        sync.IsFinished,
+		StartedAt:         sync.StartedAt,
+		CompletedAt:       sync.CompletedAt,
+		GracePeriodEndsAt: sync.GracePeriodEndsAt,
+		CreatedAt:         sync.CreatedAt,
+		UpdatedAt:         sync.UpdatedAt,
 	}})
 }
 
@@ -810,7 +1609,7 @@ func (ctrl *controller) GetSyncStatusByDatabase(ctx *fiber.Ctx) error {
 	queryOption.AddSortKey(map[string]int{
 		"created_at": queries.SortTypeDesc,
 	})
-	queryOption.SetOnlyFields("created_at", "updated_at", "started_at", "completed_at", "error", "status", "collections", "progress", "_id", "database_id", "is_finished")
+	queryOption.SetOnlyFields("created_at", "updated_at", "started_at", "completed_at", "grace_period_ends_at", "error", "status", "removal_mode", "removal_status", "collections", "progress", "_id", "database_id", "is_finished")
 	syncs, err := queries.NewSync(ctx.Context()).GetByDatabaseId(databaseId, queryOption)
 	if err != nil {
 		return err
@@ -818,16 +1617,19 @@ func (ctrl *controller) GetSyncStatusByDatabase(ctx *fiber.Ctx) error {
 	result := make([]serializers.IndexSyncStatusListResponseItem, len(syncs))
 	for i, sync := range syncs {
 		result[i] = serializers.IndexSyncStatusListResponseItem{
-			Id:          sync.Id,
-			Status:      sync.Status,
-			Progress:    sync.Progress,
-			Error:       sync.Error,
-			IsFinished:  sync.IsFinished,
-			StartedAt:   sync.StartedAt,
-			CompletedAt: sync.CompletedAt,
-			CreatedAt:   sync.CreatedAt,
-			UpdatedAt:   sync.UpdatedAt,
-			Collections: sync.Collections,
+			Id:                sync.Id,
+			Status:            sync.Status,
+			Progress:          sync.Progress,
+			Error:             sync.Error,
+			RemovalMode:       cmp.Or(sync.RemovalMode, constants.SyncRemovalModeDrop),
+			RemovalStatus:     sync.RemovalStatus,
+			IsFinished:        sync.IsFinished,
+			StartedAt:         sync.StartedAt,
+			CompletedAt:       sync.CompletedAt,
+			GracePeriodEndsAt: sync.GracePeriodEndsAt,
+			CreatedAt:         sync.CreatedAt,
+			UpdatedAt:         sync.UpdatedAt,
+			Collections:       sync.Collections,
 			Database: serializers.IndexSyncStatusListResponseDatabase{
 				Id:     sync.DatabaseID,
 				Name:   database.Name,
@@ -838,6 +1640,46 @@ func (ctrl *controller) GetSyncStatusByDatabase(ctx *fiber.Ctx) error {
 	return response.NewArrayWithPagination(ctx, result, &request.Pagination{})
 }
 
+// UnhideSyncIndexes rolls back a sync run in the hide removal mode, its hidden indexes are
+// restored at once and will not be dropped. Calling it again retries unhiding them.
+func (ctrl *controller) UnhideSyncIndexes(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("sync_id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	syncQuery := queries.NewSync(ctx.Context())
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("database_id")
+	sync, err := syncQuery.GetById(id, queryOption)
+	if err != nil {
+		return err
+	}
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(sync.DatabaseID, queryOption)
+	if err != nil {
+		return err
+	}
+	if sync, err = syncQuery.RollbackRemovalById(id); err != nil {
+		return err
+	}
+	indexes, err := ctrl.service.UnhideIndexes(database, sync.HiddenIndexes)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UnhideSyncIndexes").Str("functionInline", "ctrl.service.UnhideIndexes").Msg("index-controller")
+		if updateErr := syncQuery.UpdateRemovalStatusById(id, constants.SyncRemovalStatusRolledBack, err.Error()); updateErr != nil {
+			logger.Error().Err(updateErr).Str("function", "UnhideSyncIndexes").Str("functionInline", "syncQuery.UpdateRemovalStatusById").Msg("index-controller")
+		}
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot unhide indexes"})
+	}
+	unhidden := make([]string, len(indexes))
+	for i, index := range indexes {
+		unhidden[i] = index.Collection + "." + index.Name
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{
+		"success":  true,
+		"unhidden": unhidden,
+	}})
+}
+
 func (ctrl *controller) SyncFromDatabase(ctx *fiber.Ctx) error {
 	var requestBody serializers.IndexSyncFromDatabaseValidate
 	if err := ctx.BodyParser(&requestBody); err != nil {
@@ -847,97 +1689,30 @@ func (ctrl *controller) SyncFromDatabase(ctx *fiber.Ctx) error {
 		return err
 	}
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("uri", "db_name")
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
 	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
 	if err != nil {
 		return err
 	}
-	dbClient, err := mongodb.New(database.Uri)
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
 	if err != nil {
-		logger.Error().Err(err).Str("function", "SyncFromDatabase").Str("functionInline", "mongodb.New").Msg("index-controller")
+		logger.Error().Err(err).Str("function", "SyncFromDatabase").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
 	}
+	defer releaseClient()
 	clientIndexes, err := dbClient.GetIndexesByDbName(database.DBName)
 	if err != nil {
 		logger.Error().Err(err).Str("function", "SyncFromDatabase").Str("functionInline", "dbClient.GetIndexesByDbName").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from database"})
 	}
-	indexQuery := queries.NewIndex(ctx.Context())
-	queryOption.SetOnlyFields("_id", "key_signature", "collection", "name")
-	existingIndexes, err := indexQuery.GetByDatabaseId(requestBody.DatabaseId, queryOption)
+	indexes := make([]models.Index, len(clientIndexes))
+	for i := range clientIndexes {
+		indexes[i] = clientIndexes[i].ToModel(requestBody.DatabaseId)
+	}
+	importedCount, skippedCount, err := queries.NewIndex(ctx.Context()).CreateManyMissing(requestBody.DatabaseId, indexes)
 	if err != nil {
 		return err
 	}
-	existingIndexMap := make(map[string]struct{})
-	for _, idx := range existingIndexes {
-		key := idx.Collection + ":" + idx.KeySignature
-		existingIndexMap[key] = struct{}{}
-		if idx.Name != "" {
-			keyByName := idx.Collection + ":" + idx.Name
-			existingIndexMap[keyByName] = struct{}{}
-		}
-	}
-	importedCount := 0
-	skippedCount := 0
-	for _, clientIndex := range clientIndexes {
-		keys := make([]models.IndexKey, len(clientIndex.Keys))
-		for i, key := range clientIndex.Keys {
-			keys[i] = models.IndexKey{
-				Field: key.Field,
-				Value: key.Value,
-			}
-		}
-		var collation *models.Collation
-		if clientIndex.Options.Collation != nil {
-			collation = &models.Collation{
-				Locale:          clientIndex.Options.Collation.Locale,
-				Strength:        clientIndex.Options.Collation.Strength,
-				CaseLevel:       clientIndex.Options.Collation.CaseLevel,
-				CaseFirst:       clientIndex.Options.Collation.CaseFirst,
-				NumericOrdering: clientIndex.Options.Collation.NumericOrdering,
-			}
-		}
-		indexModel := models.Index{
-			Options: models.IndexOption{
-				ExpireAfterSeconds: clientIndex.Options.ExpireAfterSeconds,
-				IsUnique:           clientIndex.Options.IsUnique,
-				Collation:          collation,
-				DefaultLanguage:    clientIndex.Options.DefaultLanguage,
-				Weights:            clientIndex.Options.Weights,
-			},
-			Collection: clientIndex.Collection,
-			Name:       clientIndex.Name,
-			Keys:       keys,
-			DatabaseId: requestBody.DatabaseId,
-		}
-		indexModel.IsText = clientIndex.IsText
-		if indexModel.IsText && indexModel.Options.DefaultLanguage == "" {
-			indexModel.Options.DefaultLanguage = "none"
-		}
-		indexModel.KeySignature = indexModel.GetKeySignature()
-		if indexModel.Name == "" {
-			indexModel.Name = indexModel.KeySignature
-		}
-		keyBySignature := indexModel.Collection + ":" + indexModel.KeySignature
-		keyByName := indexModel.Collection + ":" + indexModel.Name
-		if _, exists := existingIndexMap[keyBySignature]; exists {
-			skippedCount++
-			continue
-		}
-		if _, exists := existingIndexMap[keyByName]; exists {
-			skippedCount++
-			continue
-		}
-		if _, err := indexQuery.CreateOne(indexModel); err != nil {
-			logger.Error().Err(err).Str("function", "SyncFromDatabase").Str("functionInline", "indexQuery.CreateOne").Str("collection", clientIndex.Collection).Str("name", indexModel.Name).Msg("index-controller")
-			continue
-		}
-		importedCount++
-		existingIndexMap[keyBySignature] = struct{}{}
-		if indexModel.Name != "" {
-			existingIndexMap[keyByName] = struct{}{}
-		}
-	}
 	return response.New(ctx, response.Options{Data: serializers.IndexSyncFromDatabaseResponse{
 		ImportedCount: importedCount,
 		SkippedCount:  skippedCount,
@@ -953,7 +1728,7 @@ func (ctrl *controller) SyncByDatabase(ctx *fiber.Ctx) error {
 		return err
 	}
 	queryOption := queries.NewOptions()
-	queryOption.SetOnlyFields("uri", "db_name")
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
 	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
 	if err != nil {
 		return err
@@ -988,22 +1763,25 @@ func (ctrl *controller) SyncByDatabase(ctx *fiber.Ctx) error {
 	if len(collections) == 0 {
 		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: "No collections with indexes found"})
 	}
-	dbClient, err := mongodb.New(database.Uri)
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
 	if err != nil {
-		logger.Error().Err(err).Str("function", "SyncByDatabase").Str("functionInline", "mongodb.New").Msg("index-controller")
+		logger.Error().Err(err).Str("function", "SyncByDatabase").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Can't connect to database"})
 	}
+	defer releaseClient()
 	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, collections)
 	if err != nil {
 		logger.Error().Err(err).Str("function", "SyncByDatabase").Str("functionInline", "dbClient.GetIndexesByDbNameAndCollections").Msg("index-controller")
 		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Can't get indexes from database"})
 	}
+	removalMode, gracePeriod := syncRemoval(requestBody.RemovalMode, requestBody.GracePeriodSeconds)
 	sync, err := syncQuery.CreateOne(models.Sync{
 		Error:       "",
 		Collections: collections,
 		DatabaseID:  requestBody.DatabaseId,
 		IsFinished:  false,
 		Status:      constants.SyncStatusPending,
+		RemovalMode: removalMode,
 		Progress:    0,
 		StartedAt:   time.Now(),
 	})
@@ -1016,7 +1794,10 @@ func (ctrl *controller) SyncByDatabase(ctx *fiber.Ctx) error {
 		ServerIndexes: indexes,
 		Uri:           database.Uri,
 		DBName:        database.DBName,
+		DatabaseId:    requestBody.DatabaseId,
 		SyncId:        sync.Id,
+		RemovalMode:   removalMode,
+		GracePeriod:   gracePeriod,
 	})
 	taskQueue := taskqueue.GetGlobal()
 	if _, err = taskQueue.EnqueueTask(taskQueue.NewTask(taskqueue.TaskTypeSyncIndexByCollection, payloadData)); err != nil {
@@ -1026,3 +1807,141 @@ func (ctrl *controller) SyncByDatabase(ctx *fiber.Ctx) error {
 	}
 	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
 }
+
+func newIndexCompareOption(expireAfterSeconds *int32, isUnique bool, defaultLanguage string, weights map[string]interface{}, collation *models.Collation) serializers.IndexCompareByDatabaseIndexOption {
+	option := serializers.IndexCompareByDatabaseIndexOption{
+		ExpireAfterSeconds: expireAfterSeconds,
+		IsUnique:           isUnique,
+		DefaultLanguage:    defaultLanguage,
+		Weights:            weights,
+	}
+	if collation != nil {
+		option.Collation = &serializers.CollationGetResponse{
+			Locale:          collation.Locale,
+			Strength:        collation.Strength,
+			CaseLevel:       collation.CaseLevel,
+			CaseFirst:       collation.CaseFirst,
+			NumericOrdering: collation.NumericOrdering,
+			Backwards:       collation.Backwards,
+			Alternate:       collation.Alternate,
+			MaxVariable:     collation.MaxVariable,
+		}
+	}
+	return option
+}
+
+func newIndexUsage(stat mongodb.IndexStat) *serializers.IndexUsageByDatabaseUsage {
+	usage := &serializers.IndexUsageByDatabaseUsage{
+		Since:   stat.Since,
+		Members: make([]serializers.IndexUsageByDatabaseMember, len(stat.Members)),
+		Ops:     stat.Ops,
+	}
+	for i, member := range stat.Members {
+		usage.Members[i] = serializers.IndexUsageByDatabaseMember{
+			Since: member.Since,
+			Host:  member.Host,
+			Ops:   member.Ops,
+		}
+	}
+	return usage
+}
+
+// newIndexLintIssues converts lint issues, keeping only those involving the named index when
+// name is set.
+func newIndexLintIssues(issues []lint.Issue, name string) []serializers.IndexLintIssue {
+	results := make([]serializers.IndexLintIssue, 0, len(issues))
+	for _, issue := range issues {
+		if name != "" && issue.Index != "" && !issue.Involves(name) {
+			continue
+		}
+		related := issue.Related
+		if related == nil {
+			related = []string{}
+		}
+		results = append(results, serializers.IndexLintIssue{
+			Related:  related,
+			Rule:     issue.Rule,
+			Severity: issue.Severity,
+			Index:    issue.Index,
+			Message:  issue.Message,
+		})
+	}
+	return results
+}
+
+// newIndexSchemaIssues converts schema warnings on the keys of the named index into lint
+// issues, with the warning severity.
+func newIndexSchemaIssues(warnings []schema.Warning, name string) []serializers.IndexLintIssue {
+	results := make([]serializers.IndexLintIssue, len(warnings))
+	for i, warning := range warnings {
+		results[i] = serializers.IndexLintIssue{
+			Related:  []string{},
+			Rule:     warning.Rule,
+			Severity: lint.SeverityWarning,
+			Index:    name,
+			Message:  warning.Message,
+		}
+	}
+	return results
+}
+
+func newIndexBuildEstimate(build estimate.Build, exceedsCache bool) *serializers.IndexBuildEstimate {
+	return &serializers.IndexBuildEstimate{
+		IndexSize:      build.IndexSize,
+		AdditionalDisk: build.AdditionalDisk,
+		Entries:        build.Entries,
+		BuildSeconds:   build.Duration.Seconds(),
+		ExceedsCache:   exceedsCache,
+	}
+}
+
+func newIndexBuildEstimateTotal(build estimate.Build, estimator *buildEstimator) serializers.IndexBuildEstimateTotal {
+	return serializers.IndexBuildEstimateTotal{
+		IndexBuildEstimate: *newIndexBuildEstimate(build, estimator.exceedsCache(build.IndexSize)),
+		CacheSize:          estimator.storage.CacheMaxBytes,
+		CurrentIndexSize:   estimator.storage.IndexSize,
+	}
+}
+
+// syncRemoval resolves the removal mode of a sync request, dropping by default, and the grace
+// period the hide mode waits before dropping.
+func syncRemoval(removalMode string, gracePeriodSeconds int64) (string, time.Duration) {
+	if removalMode == "" {
+		removalMode = constants.SyncRemovalModeDrop
+	}
+	gracePeriod := cfg.SyncGracePeriod
+	if gracePeriodSeconds > 0 {
+		gracePeriod = time.Duration(gracePeriodSeconds) * time.Second
+	}
+	return removalMode, gracePeriod
+}
+
+func newIndexSyncHiddenIndexes(hiddenIndexes []models.SyncHiddenIndex) []serializers.IndexSyncHiddenIndex {
+	result := make([]serializers.IndexSyncHiddenIndex, len(hiddenIndexes))
+	for i, index := range hiddenIndexes {
+		result[i] = serializers.IndexSyncHiddenIndex{
+			Collection:   index.Collection,
+			Name:         index.Name,
+			KeySignature: index.KeySignature,
+		}
+	}
+	return result
+}
+
+// checkSingleTextIndex fails with 409 when the collection already declares a text index other
+// than the one with excludeId, the server allows a single text index per collection.
+func checkSingleTextIndex(indexQuery queries.IndexQuery, databaseId primitive.ObjectID, collection string, excludeId primitive.ObjectID) error {
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id", "name")
+	textIndex, err := indexQuery.GetByDatabaseIdCollectionAndIsText(databaseId, collection, true, queryOption)
+	if err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code == fiber.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	if textIndex.Id == excludeId {
+		return nil
+	}
+	return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: fiber.Map{"keys": fmt.Sprintf("collection already has the text index %q", textIndex.Name)}})
+}
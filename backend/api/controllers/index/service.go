@@ -1,6 +1,22 @@
 package index
 
+import (
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/utilities/estimate"
+	"doctor-manager-api/utilities/mongodb"
+	"doctor-manager-api/utilities/schema"
+)
+
 type serviceInterface interface {
+	GetIndexSizes(database *models.Database, collection string, indexes []models.Index) (mapSize map[primitive.ObjectID]int64, err error)
+	FindUniqueViolations(database *models.Database, collections []string, indexes []models.Index, limit int64) (checked int, violations []mongodb.UniqueViolation, err error)
+	GetSchemas(database *models.Database, collections []string, sampleSize int64) (mapSchema map[string]schema.Schema, err error)
+	UnhideIndexes(database *models.Database, hiddenIndexes []models.SyncHiddenIndex) (indexes []mongodb.Index, err error)
 }
 
 type service struct{}
@@ -8,3 +24,199 @@ type service struct{}
 func newService() serviceInterface {
 	return &service{}
 }
+
+// GetIndexSizes returns the live size of the declared indexes keyed by id. Declared and live
+// indexes are matched by key signature since their names may differ, indexes missing on the
+// cluster have no entry.
+func (s *service) GetIndexSizes(database *models.Database, collection string, indexes []models.Index) (map[primitive.ObjectID]int64, error) {
+	mapSize := make(map[primitive.ObjectID]int64, len(indexes))
+	if len(indexes) == 0 {
+		return mapSize, nil
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseClient()
+	stats, err := dbClient.GetCollectionStats(database.DBName, []string{collection})
+	if err != nil || len(stats) == 0 {
+		return mapSize, err
+	}
+	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, []string{collection})
+	if err != nil {
+		return nil, err
+	}
+	mapName := make(map[string]string, len(clientIndexes))
+	for _, index := range clientIndexes {
+		mapName[index.KeySignature] = index.Name
+	}
+	for _, index := range indexes {
+		name := index.Name
+		if !index.IsDefault {
+			if name = mapName[index.KeySignature]; name == "" {
+				continue
+			}
+		}
+		if size, exists := stats[0].IndexSizes[name]; exists {
+			mapSize[index.Id] = size
+		}
+	}
+	return mapSize, nil
+}
+
+// FindUniqueViolations checks the declared unique indexes missing on the cluster, the ones a
+// sync would build, against the data of their collection.
+func (s *service) FindUniqueViolations(database *models.Database, collections []string, indexes []models.Index, limit int64) (int, []mongodb.UniqueViolation, error) {
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer releaseClient()
+	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, collections)
+	if err != nil {
+		return 0, nil, err
+	}
+	mapSignature := make(map[string]struct{}, len(clientIndexes))
+	for _, index := range clientIndexes {
+		mapSignature[index.Collection+"."+index.KeySignature] = struct{}{}
+	}
+	pendingIndexes := make([]mongodb.Index, 0)
+	for _, index := range indexes {
+		if !index.Options.IsUnique {
+			continue
+		}
+		if _, exists := mapSignature[index.Collection+"."+index.KeySignature]; exists {
+			continue
+		}
+		pendingIndexes = append(pendingIndexes, mongodb.NewIndexFromModel(index))
+	}
+	violations, err := dbClient.FindUniqueViolations(database.DBName, pendingIndexes, limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(pendingIndexes), violations, nil
+}
+
+// GetSchemas infers the schema of the collections from sampleSize of their documents.
+func (s *service) GetSchemas(database *models.Database, collections []string, sampleSize int64) (map[string]schema.Schema, error) {
+	if sampleSize <= 0 || len(collections) == 0 {
+		return map[string]schema.Schema{}, nil
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseClient()
+	return inferSchemas(sampleDocuments(dbClient, database.DBName, collections, sampleSize)), nil
+}
+
+// UnhideIndexes makes the indexes a sync hid visible to the query planner again. Indexes
+// dropped since are skipped, unhiding them would fail every retry.
+func (s *service) UnhideIndexes(database *models.Database, hiddenIndexes []models.SyncHiddenIndex) ([]mongodb.Index, error) {
+	indexes := make([]mongodb.Index, 0, len(hiddenIndexes))
+	if len(hiddenIndexes) == 0 {
+		return indexes, nil
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseClient()
+	collections := make([]string, 0)
+	for _, index := range hiddenIndexes {
+		if !slices.Contains(collections, index.Collection) {
+			collections = append(collections, index.Collection)
+		}
+	}
+	clientIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, collections)
+	if err != nil {
+		return nil, err
+	}
+	mapLive := make(map[string]struct{}, len(clientIndexes))
+	for _, index := range clientIndexes {
+		mapLive[index.Collection+"."+index.Name] = struct{}{}
+	}
+	for _, index := range hiddenIndexes {
+		if _, exists := mapLive[index.Collection+"."+index.Name]; exists {
+			indexes = append(indexes, mongodb.Index{Collection: index.Collection, Name: index.Name})
+		}
+	}
+	if err = dbClient.SetIndexesHidden(database.DBName, indexes, false); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// sampleDocuments reads sampleSize random documents of each collection. Sampling is a hint, a
+// collection failing to sample is logged and left out, and nothing is sampled when sampleSize
+// is not positive.
+func sampleDocuments(dbClient mongodb.Service, dbName string, collections []string, sampleSize int64) map[string][]bson.Raw {
+	mapDocuments := make(map[string][]bson.Raw, len(collections))
+	if sampleSize <= 0 {
+		return mapDocuments
+	}
+	for _, collection := range collections {
+		documents, err := dbClient.SampleDocuments(dbName, collection, sampleSize)
+		if err != nil {
+			logger.Warn().Err(err).Str("collection", collection).Str("function", "sampleDocuments").Str("functionInline", "dbClient.SampleDocuments").Msg("index-controller")
+			continue
+		}
+		mapDocuments[collection] = documents
+	}
+	return mapDocuments
+}
+
+func inferSchemas(mapDocuments map[string][]bson.Raw) map[string]schema.Schema {
+	mapSchema := make(map[string]schema.Schema, len(mapDocuments))
+	for collection, documents := range mapDocuments {
+		mapSchema[collection] = schema.Infer(collection, documents)
+	}
+	return mapSchema
+}
+
+// buildEstimator estimates the builds of missing indexes from the statistics of their
+// collection, the sampled documents and the storage of the target. Statistics failing to load
+// are logged and give no estimates.
+type buildEstimator struct {
+	mapStat      map[string]mongodb.CollectionStat
+	mapDocuments map[string][]bson.Raw
+	storage      mongodb.StorageStat
+}
+
+func newBuildEstimator(dbClient mongodb.Service, dbName string, collections []string, mapDocuments map[string][]bson.Raw) *buildEstimator {
+	estimator := &buildEstimator{
+		mapStat:      make(map[string]mongodb.CollectionStat, len(collections)),
+		mapDocuments: mapDocuments,
+	}
+	if len(collections) == 0 {
+		return estimator
+	}
+	stats, err := dbClient.GetCollectionStats(dbName, collections)
+	if err != nil {
+		logger.Warn().Err(err).Str("function", "newBuildEstimator").Str("functionInline", "dbClient.GetCollectionStats").Msg("index-controller")
+		return estimator
+	}
+	for _, stat := range stats {
+		estimator.mapStat[stat.Collection] = stat
+	}
+	if estimator.storage, err = dbClient.GetStorageStat(dbName); err != nil {
+		logger.Warn().Err(err).Str("function", "newBuildEstimator").Str("functionInline", "dbClient.GetStorageStat").Msg("index-controller")
+	}
+	return estimator
+}
+
+// estimate returns nil when the collection has no statistics, as when it does not exist yet.
+func (e *buildEstimator) estimate(index models.Index) *estimate.Build {
+	stat, exists := e.mapStat[index.Collection]
+	if !exists {
+		return nil
+	}
+	build := estimate.Index(estimate.Collection{Count: stat.Count, Size: stat.Size}, e.mapDocuments[index.Collection], index.Spec())
+	return &build
+}
+
+// exceedsCache tells whether the indexes of the database and indexSize more bytes of indexes
+// would outgrow the WiredTiger cache of the target, when it is known.
+func (e *buildEstimator) exceedsCache(indexSize int64) bool {
+	return e.storage.CacheMaxBytes > 0 && e.storage.IndexSize+indexSize > e.storage.CacheMaxBytes
+}
@@ -0,0 +1,331 @@
+package queryshape
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/api/serializers"
+	"doctor-manager-api/common/logging"
+	"doctor-manager-api/common/request"
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/mongodb"
+)
+
+var logger = logging.GetLogger()
+
+type Controller interface {
+	Create(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	List(ctx *fiber.Ctx) error
+	Update(ctx *fiber.Ctx) error
+	Delete(ctx *fiber.Ctx) error
+	Explain(ctx *fiber.Ctx) error
+	ExplainAfterSync(ctx *fiber.Ctx) error
+}
+
+type controller struct {
+	service serviceInterface
+}
+
+func New() Controller {
+	return &controller{
+		service: newService(),
+	}
+}
+
+func (ctrl *controller) Create(ctx *fiber.Ctx) error {
+	var requestBody serializers.QueryShapeCreateBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	if _, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption); err != nil {
+		return err
+	}
+	queryShape := models.QueryShape{
+		Collection: requestBody.Collection,
+		Name:       requestBody.Name,
+		DatabaseId: requestBody.DatabaseId,
+	}
+	var err error
+	if queryShape.Filter, err = newDocument(requestBody.Filter); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: fiber.Map{"filter": "extJsonDocument"}})
+	}
+	if queryShape.Sort, err = newDocument(requestBody.Sort); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: fiber.Map{"sort": "extJsonDocument"}})
+	}
+	if queryShape.Projection, err = newDocument(requestBody.Projection); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: fiber.Map{"projection": "extJsonDocument"}})
+	}
+	newQueryShape, err := queries.NewQueryShape(ctx.Context()).CreateOne(queryShape)
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Code: fiber.StatusCreated, Data: fiber.Map{"id": newQueryShape.Id}})
+}
+
+func (ctrl *controller) Get(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	queryShape, err := queries.NewQueryShape(ctx.Context()).GetById(id)
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: newQueryShapeGetResponse(*queryShape)})
+}
+
+func (ctrl *controller) List(ctx *fiber.Ctx) error {
+	var requestBody serializers.QueryShapeListBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	var (
+		errorChan       = make(chan error, 1)
+		totalChan       = make(chan int64, 1)
+		queryOption     = queries.NewOptions()
+		queryShapeQuery = queries.NewQueryShape(ctx.Context())
+		pagination      = request.NewPagination(requestBody.Limit, requestBody.Page)
+	)
+	queryOption.SetPagination(pagination)
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	go func() {
+		total, err := queryShapeQuery.GetTotalByDatabaseIdCollectionAndQuery(requestBody.DatabaseId, requestBody.Collection, requestBody.Query)
+		errorChan <- err
+		totalChan <- total
+	}()
+	queryShapes, err := queryShapeQuery.GetByDatabaseIdCollectionAndQuery(requestBody.DatabaseId, requestBody.Collection, requestBody.Query, queryOption)
+	if err != nil {
+		return err
+	}
+	if err = <-errorChan; err != nil {
+		return err
+	}
+	result := make([]serializers.QueryShapeGetResponse, len(queryShapes))
+	for i, queryShape := range queryShapes {
+		result[i] = newQueryShapeGetResponse(queryShape)
+	}
+	pagination.SetTotal(<-totalChan)
+	return response.NewArrayWithPagination(ctx, result, pagination)
+}
+
+func (ctrl *controller) Update(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	var requestBody serializers.QueryShapeUpdateBodyValidate
+	if err = ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err = requestBody.Validate(); err != nil {
+		return err
+	}
+	queryShapeUpdate := queries.QueryShapeUpdateInfoByIdRequest{Name: requestBody.Name}
+	if queryShapeUpdate.Filter, err = newDocument(requestBody.Filter); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: fiber.Map{"filter": "extJsonDocument"}})
+	}
+	if queryShapeUpdate.Sort, err = newDocument(requestBody.Sort); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: fiber.Map{"sort": "extJsonDocument"}})
+	}
+	if queryShapeUpdate.Projection, err = newDocument(requestBody.Projection); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: fiber.Map{"projection": "extJsonDocument"}})
+	}
+	if err = queries.NewQueryShape(ctx.Context()).UpdateInfoById(id, queryShapeUpdate); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) Delete(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	if err = queries.NewQueryShape(ctx.Context()).DeleteById(id); err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
+			return err
+		}
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) Explain(ctx *fiber.Ctx) error {
+	return ctrl.explain(ctx, false)
+}
+
+// ExplainAfterSync hides the indexes a sync would drop while the query shapes are explained,
+// so the plans are those the queries get once the sync ran. Hidden indexes are still
+// maintained, hiding and unhiding them builds nothing. The queries are planned without being
+// run, which keeps the indexes hidden from the live traffic for the planning time only.
+func (ctrl *controller) ExplainAfterSync(ctx *fiber.Ctx) error {
+	return ctrl.explain(ctx, true)
+}
+
+func (ctrl *controller) explain(ctx *fiber.Ctx, isHidingPendingDrops bool) error {
+	var requestBody serializers.QueryShapeExplainValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx.Context()).GetById(requestBody.DatabaseId, queryOption)
+	if err != nil {
+		return err
+	}
+	queryShapes, err := queries.NewQueryShape(ctx.Context()).GetByDatabaseIdAndCollections(requestBody.DatabaseId, requestBody.Collections)
+	if err != nil {
+		return err
+	}
+	collections := make([]string, 0)
+	for _, queryShape := range queryShapes {
+		if !slices.Contains(collections, queryShape.Collection) {
+			collections = append(collections, queryShape.Collection)
+		}
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "explain").Str("functionInline", "mongodb.GetManager().GetByDatabase").Msg("queryshape-controller")
+		return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot connect to database"})
+	}
+	defer releaseClient()
+	hiddenIndexes := make([]mongodb.Index, 0)
+	isRestored := false
+	if isHidingPendingDrops {
+		// Overlapping explains and the hiding of a sync would otherwise unhide each other's
+		// indexes.
+		unlock := mongodb.GetManager().LockIndexVisibility(requestBody.DatabaseId.Hex())
+		defer unlock()
+		queryOption.SetOnlyFields("key_signature", "collection")
+		declared, err := queries.NewIndex(ctx.Context()).GetByDatabaseIdAndCollections(requestBody.DatabaseId, collections, queryOption)
+		if err != nil {
+			return err
+		}
+		syncOption := queries.NewOptions()
+		syncOption.SetOnlyFields("hidden_indexes")
+		pendingRemovals, err := queries.NewSync(ctx.Context()).GetPendingRemovalsByDatabaseId(requestBody.DatabaseId, syncOption)
+		if err != nil {
+			return err
+		}
+		if hiddenIndexes, err = ctrl.service.GetPendingDrops(dbClient, database.DBName, collections, declared, pendingRemovals); err != nil {
+			logger.Error().Err(err).Str("function", "explain").Str("functionInline", "ctrl.service.GetPendingDrops").Msg("queryshape-controller")
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot get indexes from database"})
+		}
+		if err = dbClient.SetIndexesHidden(database.DBName, hiddenIndexes, true); err != nil {
+			logger.Error().Err(err).Str("function", "explain").Str("functionInline", "dbClient.SetIndexesHidden").Msg("queryshape-controller")
+			if unhideErr := dbClient.SetIndexesHidden(database.DBName, hiddenIndexes, false); unhideErr != nil {
+				logger.Error().Err(unhideErr).Str("function", "explain").Str("functionInline", "dbClient.SetIndexesHidden").Msg("queryshape-controller")
+			}
+			return response.New(ctx, response.Options{Code: fiber.StatusPreconditionFailed, Data: "Cannot hide indexes"})
+		}
+		defer func() {
+			if isRestored {
+				return
+			}
+			if err := dbClient.SetIndexesHidden(database.DBName, hiddenIndexes, false); err != nil {
+				logger.Error().Err(err).Str("function", "explain").Str("functionInline", "dbClient.SetIndexesHidden").Msg("queryshape-controller")
+			}
+		}()
+	}
+	result := make([]serializers.QueryShapeExplainResponseItem, len(queryShapes))
+	for i, queryShape := range queryShapes {
+		result[i] = serializers.QueryShapeExplainResponseItem{
+			Collection: queryShape.Collection,
+			Name:       queryShape.Name,
+			Id:         queryShape.Id,
+		}
+		plan, err := ctrl.service.Explain(dbClient, database.DBName, queryShape, isHidingPendingDrops)
+		if err != nil {
+			result[i].Error = err.Error()
+			continue
+		}
+		result[i].Plan = newQueryShapeExplainPlan(plan)
+	}
+	verbosity := mongodb.VerbosityExecutionStats
+	if isHidingPendingDrops {
+		verbosity = mongodb.VerbosityQueryPlanner
+	}
+	extra := fiber.Map{
+		"limit":          0,
+		"page":           0,
+		"verbosity":      verbosity,
+		"hidden_indexes": newQueryShapeHiddenIndexes(hiddenIndexes),
+	}
+	// The indexes are unhidden before answering, a failure leaves them hidden on the cluster and
+	// is reported rather than hidden behind the plans. The deferred unhide only runs when an
+	// explain panicked.
+	isRestored = true
+	if err = dbClient.SetIndexesHidden(database.DBName, hiddenIndexes, false); err != nil {
+		logger.Error().Err(err).Str("function", "explain").Str("functionInline", "dbClient.SetIndexesHidden").Msg("queryshape-controller")
+		extra["unhide_error"] = err.Error()
+	}
+	return response.New(ctx, response.Options{Data: result, Extra: extra})
+}
+
+func newQueryShapeGetResponse(queryShape models.QueryShape) serializers.QueryShapeGetResponse {
+	return serializers.QueryShapeGetResponse{
+		CreatedAt:  queryShape.CreatedAt,
+		UpdatedAt:  queryShape.UpdatedAt,
+		Collection: queryShape.Collection,
+		Name:       queryShape.Name,
+		Filter:     newRawDocument(queryShape.Filter),
+		Sort:       newRawDocument(queryShape.Sort),
+		Projection: newRawDocument(queryShape.Projection),
+		Id:         queryShape.Id,
+		DatabaseId: queryShape.DatabaseId,
+	}
+}
+
+func newRawDocument(text string) []byte {
+	if text == "" {
+		return []byte("{}")
+	}
+	return []byte(text)
+}
+
+func newQueryShapeExplainPlan(result mongodb.ExplainResult) *serializers.QueryShapeExplainPlan {
+	plan := &serializers.QueryShapeExplainPlan{
+		IndexNames:      result.IndexNames,
+		Stages:          result.Stages,
+		KeysExamined:    result.KeysExamined,
+		DocsExamined:    result.DocsExamined,
+		Returned:        result.Returned,
+		ExecutionMillis: result.Millis,
+		IsCollScan:      result.IsCollScan,
+		HasBlockingSort: result.HasBlockingSort,
+	}
+	if len(result.IndexNames) > 0 {
+		plan.WinningIndex = result.IndexNames[0]
+	}
+	return plan
+}
+
+func newQueryShapeHiddenIndexes(indexes []mongodb.Index) []serializers.QueryShapeHiddenIndex {
+	result := make([]serializers.QueryShapeHiddenIndex, len(indexes))
+	for i, index := range indexes {
+		result[i] = serializers.QueryShapeHiddenIndex{
+			Collection: index.Collection,
+			Name:       index.Name,
+		}
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+package queryshape
+
+import (
+	"encoding/json"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/utilities/mongodb"
+)
+
+type serviceInterface interface {
+	GetPendingDrops(dbClient mongodb.Service, dbName string, collections []string, declared []models.Index, pendingRemovals []models.Sync) (indexes []mongodb.Index, err error)
+	Explain(dbClient mongodb.Service, dbName string, queryShape models.QueryShape, isPlanOnly bool) (result mongodb.ExplainResult, err error)
+}
+
+type service struct{}
+
+func newService() serviceInterface {
+	return &service{}
+}
+
+// GetPendingDrops returns the live indexes of the collections a sync would drop: those no
+// declared index matches by key signature, save the indexes the sync keeps, built along with a
+// time-series collection or supporting a shard key. Indexes already hidden are left out, they
+// are not ours to unhide, and so are those a sync hid and still has to drop: hidden again, the
+// removal job would drop them though someone unhid them.
+func (s *service) GetPendingDrops(dbClient mongodb.Service, dbName string, collections []string, declared []models.Index, pendingRemovals []models.Sync) ([]mongodb.Index, error) {
+	indexes := make([]mongodb.Index, 0)
+	if len(collections) == 0 {
+		return indexes, nil
+	}
+	live, err := dbClient.GetIndexesByDbNameAndCollections(dbName, collections)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := dbClient.GetCollectionInfos(dbName, collections)
+	if err != nil {
+		return nil, err
+	}
+	shardKeys, err := dbClient.GetShardKeys(dbName, collections)
+	if err != nil {
+		return nil, err
+	}
+	mapDeclared := make(map[string]struct{}, len(declared))
+	for _, index := range declared {
+		mapDeclared[index.Collection+"."+index.KeySignature] = struct{}{}
+	}
+	mapInfo := make(map[string]mongodb.CollectionInfo, len(infos))
+	for _, info := range infos {
+		mapInfo[info.Name] = info
+	}
+	mapPendingRemoval := make(map[string]struct{})
+	for _, syncItem := range pendingRemovals {
+		for _, hidden := range syncItem.HiddenIndexes {
+			mapPendingRemoval[hidden.Collection+"."+hidden.Name] = struct{}{}
+		}
+	}
+	mapShardKeyIndex := make(map[string]struct{})
+	for _, shardKey := range shardKeys {
+		for _, name := range shardKey.IndexNames {
+			mapShardKeyIndex[shardKey.Collection+"."+name] = struct{}{}
+		}
+	}
+	for _, index := range live {
+		if _, exists := mapDeclared[index.Collection+"."+index.KeySignature]; exists {
+			continue
+		}
+		if _, exists := mapShardKeyIndex[index.Collection+"."+index.Name]; exists {
+			continue
+		}
+		if _, exists := mapPendingRemoval[index.Collection+"."+index.Name]; exists {
+			continue
+		}
+		if index.IsHidden || mapInfo[index.Collection].IsBuiltinIndex(index) {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+func (s *service) Explain(dbClient mongodb.Service, dbName string, queryShape models.QueryShape, isPlanOnly bool) (mongodb.ExplainResult, error) {
+	var (
+		query = mongodb.ExplainQuery{IsPlanOnly: isPlanOnly}
+		err   error
+	)
+	if query.Filter, err = parseDocument(queryShape.Filter); err != nil {
+		return mongodb.ExplainResult{}, err
+	}
+	if query.Sort, err = parseDocument(queryShape.Sort); err != nil {
+		return mongodb.ExplainResult{}, err
+	}
+	if query.Projection, err = parseDocument(queryShape.Projection); err != nil {
+		return mongodb.ExplainResult{}, err
+	}
+	return dbClient.Explain(dbName, queryShape.Collection, query)
+}
+
+// newDocument turns a request document into the relaxed extended JSON it is stored as, a
+// missing or null document being empty.
+func newDocument(raw json.RawMessage) (string, error) {
+	var document bson.D
+	if len(raw) > 0 {
+		if err := bson.UnmarshalExtJSON(raw, false, &document); err != nil {
+			return "", err
+		}
+	}
+	if document == nil {
+		document = bson.D{}
+	}
+	text, err := bson.MarshalExtJSON(document, false, false)
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
+
+// parseDocument reads a stored document back in order, as a sort needs.
+func parseDocument(text string) (bson.D, error) {
+	document := bson.D{}
+	if text == "" {
+		return document, nil
+	}
+	if err := bson.UnmarshalExtJSON([]byte(text), false, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
@@ -0,0 +1,254 @@
+package serviceaccount
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/api/serializers"
+	"doctor-manager-api/common/configure"
+	"doctor-manager-api/common/request"
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/apikey"
+	"doctor-manager-api/utilities/local"
+)
+
+var cfg = configure.GetConfig()
+
+type Controller interface {
+	Create(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	List(ctx *fiber.Ctx) error
+	Delete(ctx *fiber.Ctx) error
+	CreateApiKey(ctx *fiber.Ctx) error
+	ListApiKeys(ctx *fiber.Ctx) error
+	RevokeApiKey(ctx *fiber.Ctx) error
+}
+
+type controller struct {
+}
+
+func New() Controller {
+	return &controller{}
+}
+
+func (ctrl *controller) Create(ctx *fiber.Ctx) error {
+	var requestBody serializers.ServiceAccountCreateBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	serviceAccountQuery := queries.NewServiceAccount(ctx.Context())
+	if _, err := serviceAccountQuery.GetByName(requestBody.Name, queryOption); err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code != fiber.StatusNotFound {
+			return err
+		}
+	} else {
+		return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+	}
+	serviceAccount, err := serviceAccountQuery.CreateOne(models.ServiceAccount{
+		Name:        requestBody.Name,
+		Description: requestBody.Description,
+		CreatedBy:   local.New(ctx).GetUser().Id,
+	})
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{
+		Code: fiber.StatusCreated,
+		Data: fiber.Map{
+			"id": serviceAccount.Id,
+		},
+	})
+}
+
+func (ctrl *controller) Get(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	serviceAccount, err := queries.NewServiceAccount(ctx.Context()).GetByIdAndCreatedBy(id, local.New(ctx).GetUser().Id)
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: serializers.ServiceAccountGetResponse{
+		CreatedAt:   serviceAccount.CreatedAt,
+		UpdatedAt:   serviceAccount.UpdatedAt,
+		Name:        serviceAccount.Name,
+		Description: serviceAccount.Description,
+		CreatedBy:   serviceAccount.CreatedBy,
+		Id:          serviceAccount.Id,
+	}})
+}
+
+func (ctrl *controller) List(ctx *fiber.Ctx) error {
+	var requestBody serializers.ServiceAccountListBodyValidate
+	if err := ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err := requestBody.Validate(); err != nil {
+		return err
+	}
+	var (
+		errorChan           = make(chan error, 1)
+		totalChan           = make(chan int64, 1)
+		queryOption         = queries.NewOptions()
+		serviceAccountQuery = queries.NewServiceAccount(ctx.Context())
+		pagination          = request.NewPagination(requestBody.Limit, requestBody.Page)
+		accountId           = local.New(ctx).GetUser().Id
+	)
+	go func() {
+		total, err := serviceAccountQuery.GetTotalByQueryAndCreatedBy(requestBody.Query, accountId)
+		errorChan <- err
+		totalChan <- total
+	}()
+	queryOption.SetPagination(pagination)
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	serviceAccounts, err := serviceAccountQuery.GetByQueryAndCreatedBy(requestBody.Query, accountId, queryOption)
+	if err != nil {
+		return err
+	}
+	if err = <-errorChan; err != nil {
+		return err
+	}
+	result := make([]serializers.ServiceAccountListResponseItem, len(serviceAccounts))
+	for i, serviceAccount := range serviceAccounts {
+		result[i].CreatedAt = serviceAccount.CreatedAt
+		result[i].UpdatedAt = serviceAccount.UpdatedAt
+		result[i].Name = serviceAccount.Name
+		result[i].Description = serviceAccount.Description
+		result[i].CreatedBy = serviceAccount.CreatedBy
+		result[i].Id = serviceAccount.Id
+	}
+	pagination.SetTotal(<-totalChan)
+	return response.NewArrayWithPagination(ctx, result, pagination)
+}
+
+func (ctrl *controller) Delete(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	if err = queries.NewServiceAccount(ctx.Context()).DeleteByIdAndCreatedBy(id, local.New(ctx).GetUser().Id); err != nil {
+		if e := new(response.Error); errors.As(err, &e) && e.Code == fiber.StatusNotFound {
+			return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+		}
+		return err
+	}
+	if err = queries.NewApiKey(ctx.Context()).DeleteByServiceAccountId(id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
+
+func (ctrl *controller) CreateApiKey(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	var requestBody serializers.ServiceAccountCreateApiKeyBodyValidate
+	if err = ctx.BodyParser(&requestBody); err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: respErr.ErrFieldWrongType})
+	}
+	if err = requestBody.Validate(); err != nil {
+		return err
+	}
+	expiredAt := time.Now().Add(cfg.ApiKeyDefaultTimeout)
+	if requestBody.ExpiredAt != nil {
+		if !requestBody.ExpiredAt.After(time.Now()) {
+			return response.New(ctx, response.Options{Code: fiber.StatusBadRequest, Data: "Expired at must be in the future"})
+		}
+		expiredAt = *requestBody.ExpiredAt
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	if _, err = queries.NewServiceAccount(ctx.Context()).GetByIdAndCreatedBy(id, local.New(ctx).GetUser().Id, queryOption); err != nil {
+		return err
+	}
+	rawKey, prefix, keyHash := apikey.Generate()
+	key, err := queries.NewApiKey(ctx.Context()).CreateOne(models.ApiKey{
+		ExpiredAt:        expiredAt,
+		Name:             requestBody.Name,
+		Prefix:           prefix,
+		KeyHash:          keyHash,
+		Scopes:           requestBody.Scopes,
+		ServiceAccountId: id,
+		CreatedBy:        local.New(ctx).GetUser().Id,
+	})
+	if err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{
+		Code: fiber.StatusCreated,
+		Data: serializers.ServiceAccountCreateApiKeyResponse{
+			ExpiredAt: key.ExpiredAt,
+			Key:       rawKey,
+			Prefix:    key.Prefix,
+			Scopes:    key.Scopes,
+			Id:        key.Id,
+		},
+	})
+}
+
+func (ctrl *controller) ListApiKeys(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	if _, err = queries.NewServiceAccount(ctx.Context()).GetByIdAndCreatedBy(id, local.New(ctx).GetUser().Id, queryOption); err != nil {
+		return err
+	}
+	queryOption.SetOnlyFields("created_at", "expired_at", "last_used_at", "revoked_at", "name", "prefix", "scopes", "_id")
+	queryOption.AddSortKey(map[string]int{
+		"_id": queries.SortTypeDesc,
+	})
+	keys, err := queries.NewApiKey(ctx.Context()).GetByServiceAccountId(id, queryOption)
+	if err != nil {
+		return err
+	}
+	result := make([]serializers.ServiceAccountListApiKeysResponseItem, len(keys))
+	for i, key := range keys {
+		result[i].CreatedAt = key.CreatedAt
+		result[i].ExpiredAt = key.ExpiredAt
+		result[i].LastUsedAt = key.LastUsedAt
+		result[i].RevokedAt = key.RevokedAt
+		result[i].Name = key.Name
+		result[i].Prefix = key.Prefix
+		result[i].Scopes = key.Scopes
+		result[i].Id = key.Id
+	}
+	return response.New(ctx, response.Options{Data: result})
+}
+
+func (ctrl *controller) RevokeApiKey(ctx *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	keyId, err := primitive.ObjectIDFromHex(ctx.Params("key_id"))
+	if err != nil {
+		return response.New(ctx, response.Options{Code: fiber.StatusNotFound, Data: respErr.ErrResourceNotFound})
+	}
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id")
+	if _, err = queries.NewServiceAccount(ctx.Context()).GetByIdAndCreatedBy(id, local.New(ctx).GetUser().Id, queryOption); err != nil {
+		return err
+	}
+	if err = queries.NewApiKey(ctx.Context()).RevokeByIdAndServiceAccountId(keyId, id); err != nil {
+		return err
+	}
+	return response.New(ctx, response.Options{Data: fiber.Map{"success": true}})
+}
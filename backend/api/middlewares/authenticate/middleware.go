@@ -1,7 +1,9 @@
 package authenticate
 
 import (
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -10,6 +12,7 @@ import (
 	"doctor-manager-api/common/response"
 	respErr "doctor-manager-api/common/response/error"
 	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/apikey"
 	jwtTool "doctor-manager-api/utilities/jwt"
 	"doctor-manager-api/utilities/local"
 )
@@ -42,11 +45,14 @@ func RefreshToken(ctx *fiber.Ctx) error {
 	if err != nil {
 		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenRevoked})
 	}
-	queryOption.SetOnlyFields("username", "first_name", "last_name", "avatar", "phone", "email", "_id")
+	queryOption.SetOnlyFields("username", "first_name", "last_name", "avatar", "phone", "email", "role", "groups", "is_disabled", "_id")
 	user, err := queries.NewAccount(ctx.Context()).GetById(token.AccountId, queryOption)
 	if err != nil {
 		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: "User not found"})
 	}
+	if user.IsDisabled {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrAccountDisabled})
+	}
 	localService := local.New(ctx)
 	localService.SetUser(*user)
 	localService.SetTokenId(tokenId)
@@ -79,11 +85,95 @@ func AccessToken(ctx *fiber.Ctx) error {
 	if err != nil {
 		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenRevoked})
 	}
-	queryOption.SetOnlyFields("username", "first_name", "last_name", "avatar", "email", "_id")
+	queryOption.SetOnlyFields("username", "first_name", "last_name", "avatar", "email", "role", "is_disabled", "_id")
 	user, err := queries.NewAccount(ctx.Context()).GetById(tok.AccountId, queryOption)
 	if err != nil {
 		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: "User not found"})
 	}
-	local.New(ctx).SetUser(*user)
+	if user.IsDisabled {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrAccountDisabled})
+	}
+	localService := local.New(ctx)
+	localService.SetUser(*user)
+	localService.SetTokenId(tokenId)
+	return ctx.Next()
+}
+
+// Admin must run after AccessToken.
+func Admin(ctx *fiber.Ctx) error {
+	if user := local.New(ctx).GetUser(); !user.IsAdmin() {
+		return response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrPermissionDenied})
+	}
 	return ctx.Next()
 }
+
+// AccessTokenOrApiKey accepts either a user access token or a service account api key.
+func AccessTokenOrApiKey(ctx *fiber.Ctx) error {
+	if !strings.HasPrefix(ctx.Get("Authorization"), cfg.ApiKeyType) {
+		return AccessToken(ctx)
+	}
+	return ApiKey(ctx)
+}
+
+func ApiKey(ctx *fiber.Ctx) error {
+	tokenString := ctx.Get("Authorization")
+	if tokenString == "" {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenRequired})
+	}
+	if !strings.HasPrefix(tokenString, cfg.ApiKeyType) {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenWrongFormat})
+	}
+	rawKey := strings.TrimSpace(strings.TrimPrefix(tokenString, cfg.ApiKeyType))
+	prefix, ok := apikey.Prefix(rawKey)
+	if !ok {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenWrongFormat})
+	}
+	apiKeyQuery := queries.NewApiKey(ctx.Context())
+	key, err := apiKeyQuery.GetByPrefix(prefix)
+	if err != nil {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenWrong})
+	}
+	if !apikey.Verify(rawKey, key.KeyHash) {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenWrong})
+	}
+	currentTime := time.Now()
+	if !key.IsActive(currentTime) {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrTokenRevoked})
+	}
+	// A key stops working with the account that created it.
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("_id", "is_disabled")
+	creator, err := queries.NewAccount(ctx.Context()).GetById(key.CreatedBy, queryOption)
+	if err != nil || creator.IsDisabled {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: respErr.ErrAccountDisabled})
+	}
+	serviceAccount, err := queries.NewServiceAccount(ctx.Context()).GetById(key.ServiceAccountId)
+	if err != nil {
+		return response.NewError(fiber.StatusUnauthorized, response.ErrorOptions{Data: "Service account not found"})
+	}
+	if err = apiKeyQuery.UpdateLastUsedAtById(key.Id, currentTime); err != nil {
+		return err
+	}
+	localService := local.New(ctx)
+	localService.SetServiceAccount(*serviceAccount)
+	localService.SetScopes(key.Scopes)
+	return ctx.Next()
+}
+
+// RequireScopes rejects service account requests whose api key lacks any of the given scopes.
+// Requests authenticated with a user access token are not restricted.
+func RequireScopes(scopes ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		localService := local.New(ctx)
+		if _, ok := localService.GetServiceAccount(); !ok {
+			return ctx.Next()
+		}
+		granted := localService.GetScopes()
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrScopeMissing})
+			}
+		}
+		return ctx.Next()
+	}
+}
@@ -27,7 +27,15 @@ func (r *auth) V1() {
 	r.router.Post("/register", r.controller.Register)
 	r.router.Post("/login", r.controller.Login)
 	r.router.Post("/refresh-token", authMiddleware.RefreshToken, r.controller.RefreshToken)
+	r.router.Get("/jwks", r.controller.GetJWKS)
+	r.router.Get("/oidc/login", r.controller.OidcLogin)
+	r.router.Post("/oidc/callback", r.controller.OidcCallback)
 	r.router.Use(authMiddleware.AccessToken)
 	r.router.Get("/profile", r.controller.GetProfile)
 	r.router.Put("/profile", r.controller.UpdateProfile)
+	r.router.Post("/logout", r.controller.Logout)
+	r.router.Post("/logout-all", r.controller.LogoutAll)
+	r.router.Get("/sessions", r.controller.ListSessions)
+	r.router.Delete("/sessions/:id", r.controller.RevokeSession)
+	r.router.Put("/password", r.controller.ChangePassword)
 }
@@ -0,0 +1,38 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	adminCtrl "doctor-manager-api/api/controllers/admin"
+	authMiddleware "doctor-manager-api/api/middlewares/authenticate"
+)
+
+type Admin interface {
+	V1()
+}
+
+type admin struct {
+	router     fiber.Router
+	controller adminCtrl.Controller
+}
+
+func NewAdmin(router fiber.Router) Admin {
+	return &admin{
+		router:     router.Group("/admin"),
+		controller: adminCtrl.New(),
+	}
+}
+
+func (r *admin) V1() {
+	r.router.Use(authMiddleware.AccessToken, authMiddleware.Admin)
+	r.router.Post("/accounts/list", r.controller.ListAccounts)
+	r.router.Put("/accounts/:id/disable", r.controller.DisableAccount)
+	r.router.Put("/accounts/:id/enable", r.controller.EnableAccount)
+	r.router.Put("/accounts/:id/password", r.controller.ResetAccountPassword)
+	r.router.Delete("/accounts/:id/sessions", r.controller.RevokeAccountSessions)
+	r.router.Put("/accounts/:id/unlock", r.controller.UnlockAccount)
+	r.router.Post("/login-attempts/list", r.controller.ListLoginAttempts)
+	r.router.Post("/invitations", r.controller.CreateInvitation)
+	r.router.Post("/invitations/list", r.controller.ListInvitations)
+	r.router.Delete("/invitations/:id", r.controller.DeleteInvitation)
+}
@@ -5,6 +5,7 @@ import (
 
 	databaseCtrl "doctor-manager-api/api/controllers/database"
 	authMiddleware "doctor-manager-api/api/middlewares/authenticate"
+	"doctor-manager-api/common/constants"
 )
 
 type Database interface {
@@ -25,24 +26,52 @@ func NewDatabase(router fiber.Router) Database {
 
 func (r *database) V1() {
 	r.collection()
+	r.cluster()
 	r.root()
 }
 
 func (r *database) root() {
 	router := r.router.Group("/")
-	router.Use(authMiddleware.AccessToken)
-	router.Get("/:id", r.controller.Get)
-	router.Post("/", r.controller.Create)
-	router.Post("/list", r.controller.List)
-	router.Put("/:id/", r.controller.Update)
-	router.Delete("/:id/", r.controller.Delete)
+	router.Use(authMiddleware.AccessTokenOrApiKey)
+	var (
+		read  = authMiddleware.RequireScopes(constants.ScopeDatabaseRead)
+		write = authMiddleware.RequireScopes(constants.ScopeDatabaseWrite)
+	)
+	router.Get("/:id", read, r.controller.Get)
+	router.Post("/", write, r.controller.Create)
+	router.Post("/list", read, r.controller.List)
+	router.Put("/:id/", write, r.controller.Update)
+	router.Delete("/:id/", write, r.controller.Delete)
 }
 
 func (r *database) collection() {
 	router := r.router.Group("/collections")
-	router.Use(authMiddleware.AccessToken)
-	router.Post("/list", r.controller.ListCollections)
-	router.Post("/", r.controller.CreateCollection)
-	router.Put("/", r.controller.UpdateCollection)
-	router.Delete("/", r.controller.DeleteCollection)
+	router.Use(authMiddleware.AccessTokenOrApiKey)
+	var (
+		read  = authMiddleware.RequireScopes(constants.ScopeDatabaseRead)
+		write = authMiddleware.RequireScopes(constants.ScopeDatabaseWrite)
+	)
+	router.Post("/list", read, r.controller.ListCollections)
+	router.Post("/live/list", read, r.controller.ListLiveCollections)
+	router.Post("/adopt", write, r.controller.AdoptCollection)
+	router.Post("/schema", read, r.controller.SampleSchemas)
+	router.Post("/", write, r.controller.CreateCollection)
+	router.Put("/", write, r.controller.UpdateCollection)
+	router.Delete("/", write, r.controller.DeleteCollection)
+}
+
+func (r *database) cluster() {
+	router := r.router.Group("/clusters")
+	router.Use(authMiddleware.AccessTokenOrApiKey)
+	var (
+		read  = authMiddleware.RequireScopes(constants.ScopeDatabaseRead)
+		write = authMiddleware.RequireScopes(constants.ScopeDatabaseWrite)
+	)
+	router.Post("/databases/list", read, r.controller.ListClusterDatabases)
+	router.Post("/databases/import", write, r.controller.ImportClusterDatabases)
+	router.Get("/:id", read, r.controller.GetCluster)
+	router.Post("/", write, r.controller.CreateCluster)
+	router.Post("/list", read, r.controller.ListClusters)
+	router.Put("/:id/", write, r.controller.UpdateCluster)
+	router.Delete("/:id/", write, r.controller.DeleteCluster)
 }
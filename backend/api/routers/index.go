@@ -5,6 +5,7 @@ import (
 
 	indexCtrl "doctor-manager-api/api/controllers/index"
 	authMiddleware "doctor-manager-api/api/middlewares/authenticate"
+	"doctor-manager-api/common/constants"
 )
 
 type Index interface {
@@ -24,17 +25,31 @@ func NewIndex(router fiber.Router) Index {
 }
 
 func (r *index) V1() {
-	r.router.Use(authMiddleware.AccessToken)
-	r.router.Post("/", r.controller.Create)
-	r.router.Get("/:id", r.controller.Get)
-	r.router.Post("/list-by-collection", r.controller.ListByCollection)
-	r.router.Put("/:id", r.controller.Update)
-	r.router.Delete("/:id", r.controller.Delete)
-	r.router.Post("/compare-by-collections", r.controller.CompareByCollections)
-	r.router.Post("/compare-by-database", r.controller.CompareByDatabase)
-	r.router.Post("/sync-by-collections", r.controller.SyncByCollections)
-	r.router.Post("/sync-by-database", r.controller.SyncByDatabase)
-	r.router.Post("/sync-from-database", r.controller.SyncFromDatabase)
-	r.router.Get("/sync-status/:sync_id", r.controller.GetSyncStatus)
-	r.router.Get("/sync-status/by-database/:database_id", r.controller.GetSyncStatusByDatabase)
+	r.router.Use(authMiddleware.AccessTokenOrApiKey)
+	var (
+		read    = authMiddleware.RequireScopes(constants.ScopeIndexRead)
+		write   = authMiddleware.RequireScopes(constants.ScopeIndexWrite)
+		compare = authMiddleware.RequireScopes(constants.ScopeIndexCompare)
+		sync    = authMiddleware.RequireScopes(constants.ScopeIndexSync)
+	)
+	r.router.Post("/", write, r.controller.Create)
+	r.router.Get("/:id", read, r.controller.Get)
+	r.router.Post("/list-by-collection", read, r.controller.ListByCollection)
+	r.router.Put("/:id", write, r.controller.Update)
+	r.router.Delete("/:id", write, r.controller.Delete)
+	r.router.Post("/compare-by-collections", compare, r.controller.CompareByCollections)
+	r.router.Post("/compare-by-database", compare, r.controller.CompareByDatabase)
+	r.router.Post("/usage-by-database", read, r.controller.UsageByDatabase)
+	r.router.Post("/lint", read, r.controller.Lint)
+	r.router.Post("/check-duplicates", compare, r.controller.CheckDuplicates)
+	r.router.Post("/shard-consistency", compare, r.controller.ShardConsistency)
+	r.router.Post("/advisor/profile", read, r.controller.AdvisorFromProfile)
+	r.router.Post("/advisor/log", read, r.controller.AdvisorFromLog)
+	r.router.Post("/advisor/accept", write, r.controller.AdvisorAccept)
+	r.router.Post("/sync-by-collections", sync, r.controller.SyncByCollections)
+	r.router.Post("/sync-by-database", sync, r.controller.SyncByDatabase)
+	r.router.Post("/sync-from-database", write, r.controller.SyncFromDatabase)
+	r.router.Get("/sync-status/:sync_id", read, r.controller.GetSyncStatus)
+	r.router.Get("/sync-status/by-database/:database_id", read, r.controller.GetSyncStatusByDatabase)
+	r.router.Post("/sync/:sync_id/unhide", sync, r.controller.UnhideSyncIndexes)
 }
@@ -0,0 +1,43 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	queryShapeCtrl "doctor-manager-api/api/controllers/queryshape"
+	authMiddleware "doctor-manager-api/api/middlewares/authenticate"
+	"doctor-manager-api/common/constants"
+)
+
+type QueryShape interface {
+	V1()
+}
+
+type queryShape struct {
+	router     fiber.Router
+	controller queryShapeCtrl.Controller
+}
+
+func NewQueryShape(router fiber.Router) QueryShape {
+	return &queryShape{
+		router:     router.Group("/query-shapes"),
+		controller: queryShapeCtrl.New(),
+	}
+}
+
+func (r *queryShape) V1() {
+	r.router.Use(authMiddleware.AccessTokenOrApiKey)
+	var (
+		read    = authMiddleware.RequireScopes(constants.ScopeIndexRead)
+		write   = authMiddleware.RequireScopes(constants.ScopeIndexWrite)
+		compare = authMiddleware.RequireScopes(constants.ScopeIndexCompare)
+		// Hiding indexes changes the plans of the live traffic for the time of the explains.
+		compareAndSync = authMiddleware.RequireScopes(constants.ScopeIndexCompare, constants.ScopeIndexSync)
+	)
+	r.router.Post("/", write, r.controller.Create)
+	r.router.Get("/:id", read, r.controller.Get)
+	r.router.Post("/list", read, r.controller.List)
+	r.router.Put("/:id", write, r.controller.Update)
+	r.router.Delete("/:id", write, r.controller.Delete)
+	r.router.Post("/explain", compare, r.controller.Explain)
+	r.router.Post("/explain-after-sync", compareAndSync, r.controller.ExplainAfterSync)
+}
@@ -0,0 +1,35 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	serviceAccountCtrl "doctor-manager-api/api/controllers/serviceaccount"
+	authMiddleware "doctor-manager-api/api/middlewares/authenticate"
+)
+
+type ServiceAccount interface {
+	V1()
+}
+
+type serviceAccount struct {
+	router     fiber.Router
+	controller serviceAccountCtrl.Controller
+}
+
+func NewServiceAccount(router fiber.Router) ServiceAccount {
+	return &serviceAccount{
+		router:     router.Group("/service-accounts"),
+		controller: serviceAccountCtrl.New(),
+	}
+}
+
+func (r *serviceAccount) V1() {
+	r.router.Use(authMiddleware.AccessToken, authMiddleware.Admin)
+	r.router.Post("/", r.controller.Create)
+	r.router.Post("/list", r.controller.List)
+	r.router.Get("/:id", r.controller.Get)
+	r.router.Delete("/:id", r.controller.Delete)
+	r.router.Post("/:id/api-keys", r.controller.CreateApiKey)
+	r.router.Get("/:id/api-keys", r.controller.ListApiKeys)
+	r.router.Delete("/:id/api-keys/:key_id", r.controller.RevokeApiKey)
+}
@@ -0,0 +1,119 @@
+package serializers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/common/request/validator"
+	"doctor-manager-api/common/response"
+)
+
+type AdminListAccountsBodyValidate struct {
+	Query string `json:"query" validate:"omitempty,max=500"`
+	Page  int64  `json:"page" validate:"omitempty,min=0"`
+	Limit int64  `json:"limit" validate:"omitempty,min=0"`
+}
+
+func (v *AdminListAccountsBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type AdminListAccountsResponseItem struct {
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	Username   string             `json:"username"`
+	Email      string             `json:"email"`
+	FirstName  string             `json:"first_name"`
+	LastName   string             `json:"last_name"`
+	Role       string             `json:"role"`
+	Id         primitive.ObjectID `json:"id"`
+	IsDisabled bool               `json:"is_disabled"`
+}
+
+type AdminResetPasswordBodyValidate struct {
+	Password string `json:"password" validate:"required,gte=8"`
+}
+
+func (v *AdminResetPasswordBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type AdminCreateInvitationBodyValidate struct {
+	ExpiredAt *time.Time `json:"expired_at" validate:"omitempty"`
+	Email     string     `json:"email" validate:"omitempty,email"`
+}
+
+func (v *AdminCreateInvitationBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type AdminCreateInvitationResponse struct {
+	ExpiredAt time.Time          `json:"expired_at"`
+	Email     string             `json:"email"`
+	Token     string             `json:"token"`
+	Id        primitive.ObjectID `json:"id"`
+}
+
+type AdminListInvitationsBodyValidate struct {
+	Page  int64 `json:"page" validate:"omitempty,min=0"`
+	Limit int64 `json:"limit" validate:"omitempty,min=0"`
+}
+
+func (v *AdminListInvitationsBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type AdminListInvitationsResponseItem struct {
+	CreatedAt time.Time           `json:"created_at"`
+	ExpiredAt time.Time           `json:"expired_at"`
+	UsedAt    *time.Time          `json:"used_at"`
+	UsedBy    *primitive.ObjectID `json:"used_by"`
+	Email     string              `json:"email"`
+	CreatedBy primitive.ObjectID  `json:"created_by"`
+	Id        primitive.ObjectID  `json:"id"`
+}
+
+type AdminListLoginAttemptsBodyValidate struct {
+	AccountId    *primitive.ObjectID `json:"account_id" validate:"omitempty"`
+	IpAddress    string              `json:"ip_address" validate:"omitempty,ip"`
+	Page         int64               `json:"page" validate:"omitempty,min=0"`
+	Limit        int64               `json:"limit" validate:"omitempty,min=0"`
+	IsFailedOnly bool                `json:"is_failed_only" validate:"omitempty"`
+}
+
+func (v *AdminListLoginAttemptsBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type AdminListLoginAttemptsResponseItem struct {
+	CreatedAt time.Time           `json:"created_at"`
+	AccountId *primitive.ObjectID `json:"account_id"`
+	Identity  string              `json:"identity"`
+	IpAddress string              `json:"ip_address"`
+	UserAgent string              `json:"user_agent"`
+	Reason    string              `json:"reason"`
+	Id        primitive.ObjectID  `json:"id"`
+	IsSuccess bool                `json:"is_success"`
+}
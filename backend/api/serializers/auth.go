@@ -1,16 +1,20 @@
 package serializers
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"doctor-manager-api/common/request/validator"
 	"doctor-manager-api/common/response"
 )
 
 type AuthRegisterBodyValidate struct {
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,gte=8"`
+	Username    string `json:"username" validate:"required"`
+	Email       string `json:"email" validate:"required,email"`
+	Password    string `json:"password" validate:"required,gte=8"`
+	InviteToken string `json:"invite_token" validate:"omitempty"`
 }
 
 func (v *AuthRegisterBodyValidate) Validate() error {
@@ -39,17 +43,36 @@ type AuthLoginResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type AuthOidcLoginResponse struct {
+	AuthorizationUrl string `json:"authorization_url"`
+}
+
+type AuthOidcCallbackBodyValidate struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+func (v *AuthOidcCallbackBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
 type AuthRefreshTokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
 
 type AuthGetProfileResponse struct {
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Avatar    string `json:"avatar"`
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Avatar    string   `json:"avatar"`
+	Role      string   `json:"role"`
+	Groups    []string `json:"groups"`
 }
 
 type AuthUpdateProfileBodyValidate struct {
@@ -65,3 +88,25 @@ func (v *AuthUpdateProfileBodyValidate) Validate() error {
 	}
 	return nil
 }
+
+type AuthListSessionsResponseItem struct {
+	CreatedAt time.Time          `json:"created_at"`
+	ExpiredAt time.Time          `json:"expired_at"`
+	UserAgent string             `json:"user_agent"`
+	IpAddress string             `json:"ip_address"`
+	Id        primitive.ObjectID `json:"id"`
+	IsCurrent bool               `json:"is_current"`
+}
+
+type AuthChangePasswordBodyValidate struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,gte=8,nefield=CurrentPassword"`
+}
+
+func (v *AuthChangePasswordBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
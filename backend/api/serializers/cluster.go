@@ -0,0 +1,136 @@
+package serializers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/common/request/validator"
+	"doctor-manager-api/common/response"
+)
+
+type ClusterGetResponse struct {
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	Tls            *DatabaseTlsResponse  `json:"tls"`
+	Auth           *DatabaseAuthResponse `json:"auth"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description"`
+	Uri            string                `json:"uri"`
+	TotalDatabases int64                 `json:"total_databases"`
+	Id             primitive.ObjectID    `json:"id"`
+}
+
+type ClusterCreateBodyValidate struct {
+	Name             string                    `json:"name" validate:"required"`
+	Description      string                    `json:"description" validate:"omitempty"`
+	Uri              string                    `json:"uri" validate:"required,databaseUri"`
+	Tls              *DatabaseTlsBodyValidate  `json:"tls" validate:"omitempty"`
+	Auth             *DatabaseAuthBodyValidate `json:"auth" validate:"omitempty"`
+	IsTestConnection bool                      `json:"is_test_connection" validate:"omitempty"`
+}
+
+func (v *ClusterCreateBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type ClusterListBodyValidate struct {
+	Query string `json:"query" validate:"omitempty,max=500"`
+	Page  int64  `json:"page" validate:"omitempty,min=0"`
+	Limit int64  `json:"limit" validate:"omitempty,min=0"`
+}
+
+func (v *ClusterListBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type ClusterListResponseItem struct {
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	Tls         *DatabaseTlsResponse  `json:"tls"`
+	Auth        *DatabaseAuthResponse `json:"auth"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Uri         string                `json:"uri"`
+	Id          primitive.ObjectID    `json:"id"`
+}
+
+type ClusterUpdateBodyValidate struct {
+	Name             string                    `json:"name" validate:"required"`
+	Description      string                    `json:"description" validate:"omitempty"`
+	Uri              string                    `json:"uri" validate:"required,databaseUri"`
+	Tls              *DatabaseTlsBodyValidate  `json:"tls" validate:"omitempty"`
+	Auth             *DatabaseAuthBodyValidate `json:"auth" validate:"omitempty"`
+	IsTestConnection bool                      `json:"is_test_connection" validate:"omitempty"`
+}
+
+func (v *ClusterUpdateBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type ClusterListDatabasesBodyValidate struct {
+	ClusterId primitive.ObjectID `json:"cluster_id" validate:"required"`
+}
+
+func (v *ClusterListDatabasesBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+// ClusterListDatabasesResponseItem is a database found on the cluster, DatabaseId is set once
+// it is registered under the cluster.
+type ClusterListDatabasesResponseItem struct {
+	DatabaseId *primitive.ObjectID `json:"database_id"`
+	DBName     string              `json:"db_name"`
+	SizeOnDisk int64               `json:"size_on_disk"`
+	IsEmpty    bool                `json:"is_empty"`
+}
+
+// ClusterImportDatabasesBodyValidate registers the databases of the cluster not registered yet,
+// named after db_name unless a name is given, and imports their indexes when IsSyncIndex.
+type ClusterImportDatabasesBodyValidate struct {
+	Databases   []ClusterImportDatabase `json:"databases" validate:"required,min=1,dive"`
+	ClusterId   primitive.ObjectID      `json:"cluster_id" validate:"required"`
+	IsSyncIndex bool                    `json:"is_sync_index" validate:"omitempty"`
+}
+
+type ClusterImportDatabase struct {
+	DBName string `json:"db_name" validate:"required"`
+	Name   string `json:"name" validate:"omitempty"`
+}
+
+func (v *ClusterImportDatabasesBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+// ClusterImportDatabasesResponseItem reports one database of the request. Error is set when it
+// could not be registered or its indexes could not be read, the other databases go on.
+type ClusterImportDatabasesResponseItem struct {
+	DatabaseId    *primitive.ObjectID `json:"database_id"`
+	DBName        string              `json:"db_name"`
+	Name          string              `json:"name"`
+	Error         string              `json:"error,omitempty"`
+	ImportedCount int                 `json:"imported_count"`
+	SkippedCount  int                 `json:"skipped_count"`
+	IsCreated     bool                `json:"is_created"`
+}
@@ -11,22 +11,30 @@ import (
 )
 
 type DatabaseGetResponse struct {
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
-	Name        string             `json:"name"`
-	Description string             `json:"description"`
-	Uri         string             `json:"uri"`
-	DBName      string             `json:"db_name"`
-	Id          primitive.ObjectID `json:"id"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	Tls         *DatabaseTlsResponse  `json:"tls"`
+	Auth        *DatabaseAuthResponse `json:"auth"`
+	ClusterId   *primitive.ObjectID   `json:"cluster_id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Uri         string                `json:"uri"`
+	DBName      string                `json:"db_name"`
+	Id          primitive.ObjectID    `json:"id"`
 }
 
+// DatabaseCreateBodyValidate takes either its own uri, tls and auth or the cluster_id of a
+// registered cluster.
 type DatabaseCreateBodyValidate struct {
-	Name             string `json:"name" validate:"required"`
-	Description      string `json:"description" validate:"omitempty"`
-	Uri              string `json:"uri" validate:"required,databaseUri"`
-	DBName           string `json:"db_name" validate:"required"`
-	IsTestConnection bool   `json:"is_test_connection" validate:"omitempty"`
-	IsSyncIndex      bool   `json:"is_sync_index" validate:"omitempty"`
+	Name             string                    `json:"name" validate:"required"`
+	Description      string                    `json:"description" validate:"omitempty"`
+	Uri              string                    `json:"uri" validate:"omitempty,databaseUri"`
+	DBName           string                    `json:"db_name" validate:"required"`
+	Tls              *DatabaseTlsBodyValidate  `json:"tls" validate:"omitempty"`
+	Auth             *DatabaseAuthBodyValidate `json:"auth" validate:"omitempty"`
+	ClusterId        *primitive.ObjectID       `json:"cluster_id" validate:"omitempty"`
+	IsTestConnection bool                      `json:"is_test_connection" validate:"omitempty"`
+	IsSyncIndex      bool                      `json:"is_sync_index" validate:"omitempty"`
 }
 
 func (v *DatabaseCreateBodyValidate) Validate() error {
@@ -37,6 +45,42 @@ func (v *DatabaseCreateBodyValidate) Validate() error {
 	return nil
 }
 
+// DatabaseTlsBodyValidate takes PEM encoded material. On update an empty certificate or
+// key keeps the stored one.
+type DatabaseTlsBodyValidate struct {
+	CaCertificate        string `json:"ca_certificate" validate:"omitempty"`
+	ClientCertificate    string `json:"client_certificate" validate:"omitempty"`
+	ClientKey            string `json:"client_key" validate:"omitempty"`
+	IsEnabled            bool   `json:"is_enabled"`
+	IsInsecureSkipVerify bool   `json:"is_insecure_skip_verify"`
+}
+
+// DatabaseAuthBodyValidate overrides the credentials of the uri. On update an empty
+// password or session token keeps the stored one.
+type DatabaseAuthBodyValidate struct {
+	Mechanism       string `json:"mechanism" validate:"required,oneof=SCRAM-SHA-1 SCRAM-SHA-256 MONGODB-X509 MONGODB-AWS PLAIN"`
+	Source          string `json:"source" validate:"omitempty"`
+	Username        string `json:"username" validate:"omitempty"`
+	Password        string `json:"password" validate:"omitempty"`
+	AwsSessionToken string `json:"aws_session_token" validate:"omitempty"`
+}
+
+type DatabaseTlsResponse struct {
+	IsEnabled            bool `json:"is_enabled"`
+	IsInsecureSkipVerify bool `json:"is_insecure_skip_verify"`
+	HasCaCertificate     bool `json:"has_ca_certificate"`
+	HasClientCertificate bool `json:"has_client_certificate"`
+	HasClientKey         bool `json:"has_client_key"`
+}
+
+type DatabaseAuthResponse struct {
+	Mechanism          string `json:"mechanism"`
+	Source             string `json:"source"`
+	Username           string `json:"username"`
+	HasPassword        bool   `json:"has_password"`
+	HasAwsSessionToken bool   `json:"has_aws_session_token"`
+}
+
 type DatabaseListBodyValidate struct {
 	Query string `json:"query" validate:"omitempty,max=500"`
 	Page  int64  `json:"page" validate:"omitempty,min=0"`
@@ -52,21 +96,27 @@ func (v *DatabaseListBodyValidate) Validate() error {
 }
 
 type DatabaseListResponseItem struct {
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
-	Name        string             `json:"name"`
-	Description string             `json:"description"`
-	Uri         string             `json:"uri"`
-	DBName      string             `json:"db_name"`
-	Id          primitive.ObjectID `json:"id"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	Tls         *DatabaseTlsResponse  `json:"tls"`
+	Auth        *DatabaseAuthResponse `json:"auth"`
+	ClusterId   *primitive.ObjectID   `json:"cluster_id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Uri         string                `json:"uri"`
+	DBName      string                `json:"db_name"`
+	Id          primitive.ObjectID    `json:"id"`
 }
 
 type DatabaseUpdateBodyValidate struct {
-	Name             string `json:"name" validate:"required"`
-	Description      string `json:"description" validate:"omitempty"`
-	Uri              string `json:"uri" validate:"required,databaseUri"`
-	DBName           string `json:"db_name" validate:"required"`
-	IsTestConnection bool   `json:"is_test_connection" validate:"omitempty"`
+	Name             string                    `json:"name" validate:"required"`
+	Description      string                    `json:"description" validate:"omitempty"`
+	Uri              string                    `json:"uri" validate:"omitempty,databaseUri"`
+	DBName           string                    `json:"db_name" validate:"required"`
+	Tls              *DatabaseTlsBodyValidate  `json:"tls" validate:"omitempty"`
+	Auth             *DatabaseAuthBodyValidate `json:"auth" validate:"omitempty"`
+	ClusterId        *primitive.ObjectID       `json:"cluster_id" validate:"omitempty"`
+	IsTestConnection bool                      `json:"is_test_connection" validate:"omitempty"`
 }
 
 func (v *DatabaseUpdateBodyValidate) Validate() error {
@@ -79,6 +129,7 @@ func (v *DatabaseUpdateBodyValidate) Validate() error {
 
 type DatabaseListCollectionsBodyValidate struct {
 	Query      string             `json:"query" validate:"omitempty"`
+	SortBy     string             `json:"sort_by" validate:"omitempty,oneof=collection count size storage_size total_index_size"`
 	Page       int64              `json:"page" validate:"omitempty,min=0"`
 	Limit      int64              `json:"limit" validate:"omitempty,min=0"`
 	DatabaseId primitive.ObjectID `json:"database_id" validate:"required"`
@@ -93,8 +144,125 @@ func (v *DatabaseListCollectionsBodyValidate) Validate() error {
 }
 
 type DatabaseListCollectionsResponseItem struct {
-	Collection   string `json:"collection"`
-	TotalIndexes int    `json:"total_indexes"`
+	Stats        *DatabaseCollectionStatsResponse `json:"stats"`
+	ShardKey     *DatabaseShardKeyResponse        `json:"shard_key"`
+	Timeseries   *DatabaseTimeseriesResponse      `json:"timeseries"`
+	Collection   string                           `json:"collection"`
+	Type         string                           `json:"type"`
+	TotalIndexes int                              `json:"total_indexes"`
+	IsCapped     bool                             `json:"is_capped"`
+	IsClustered  bool                             `json:"is_clustered"`
+}
+
+type DatabaseTimeseriesResponse struct {
+	TimeField   string `json:"time_field"`
+	MetaField   string `json:"meta_field"`
+	Granularity string `json:"granularity"`
+}
+
+type DatabaseShardKeyResponse struct {
+	Keys       []DatabaseShardKeyField `json:"keys"`
+	IndexNames []string                `json:"index_names"`
+	IsUnique   bool                    `json:"is_unique"`
+}
+
+type DatabaseShardKeyField struct {
+	Value interface{} `json:"value"`
+	Field string      `json:"field"`
+}
+
+type DatabaseCollectionStatsResponse struct {
+	Count          int64 `json:"count"`
+	Size           int64 `json:"size"`
+	StorageSize    int64 `json:"storage_size"`
+	TotalIndexSize int64 `json:"total_index_size"`
+}
+
+// DatabaseListLiveCollectionsBodyValidate lists the collections found on the cluster. IsManaged,
+// when set, keeps only the collections with or without declared indexes.
+type DatabaseListLiveCollectionsBodyValidate struct {
+	IsManaged  *bool              `json:"is_managed" validate:"omitempty"`
+	Query      string             `json:"query" validate:"omitempty"`
+	Page       int64              `json:"page" validate:"omitempty,min=0"`
+	Limit      int64              `json:"limit" validate:"omitempty,min=0"`
+	DatabaseId primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *DatabaseListLiveCollectionsBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+// DatabaseListLiveCollectionsResponseItem counts in TotalIndexes the declared indexes and in
+// LiveIndexes those on the cluster, both with the _id index.
+type DatabaseListLiveCollectionsResponseItem struct {
+	Timeseries   *DatabaseTimeseriesResponse `json:"timeseries"`
+	Collection   string                      `json:"collection"`
+	Type         string                      `json:"type"`
+	TotalIndexes int                         `json:"total_indexes"`
+	LiveIndexes  int                         `json:"live_indexes"`
+	IsCapped     bool                        `json:"is_capped"`
+	IsClustered  bool                        `json:"is_clustered"`
+	IsManaged    bool                        `json:"is_managed"`
+}
+
+// DatabaseSampleSchemasBodyValidate samples the given collections, the managed ones when empty.
+// SampleSize defaults to SCHEMA_SAMPLE_SIZE.
+type DatabaseSampleSchemasBodyValidate struct {
+	Collections []string           `json:"collections" validate:"omitempty,unique"`
+	SampleSize  int64              `json:"sample_size" validate:"omitempty,min=1,max=10000"`
+	DatabaseId  primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *DatabaseSampleSchemasBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type DatabaseSampleSchemasResponseItem struct {
+	Fields     []DatabaseSchemaField `json:"fields"`
+	Collection string                `json:"collection"`
+	SampleSize int64                 `json:"sample_size"`
+}
+
+// DatabaseSchemaField counts in Count the sampled documents holding the path. HasNestedArray
+// tells that the path holds arrays of arrays, whose elements no dotted path reaches.
+type DatabaseSchemaField struct {
+	Types          []DatabaseSchemaFieldType `json:"types"`
+	Path           string                    `json:"path"`
+	Count          int64                     `json:"count"`
+	PresenceRate   float64                   `json:"presence_rate"`
+	IsArray        bool                      `json:"is_array"`
+	HasNestedArray bool                      `json:"has_nested_array"`
+}
+
+type DatabaseSchemaFieldType struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+type DatabaseAdoptCollectionBodyValidate struct {
+	Collection string             `json:"collection" validate:"required"`
+	DatabaseId primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *DatabaseAdoptCollectionBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type DatabaseAdoptCollectionResponse struct {
+	ImportedCount int `json:"imported_count"`
+	SkippedCount  int `json:"skipped_count"`
 }
 
 type DatabaseCreateCollectionBodyValidate struct {
@@ -1,6 +1,8 @@
 package serializers
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -19,11 +21,14 @@ type IndexCreateBodyValidate struct {
 }
 
 type CollationCreateOption struct {
-	Strength        *int   `json:"strength,omitempty"`
+	Strength        *int   `json:"strength,omitempty" validate:"omitempty,min=1,max=5"`
 	CaseLevel       *bool  `json:"case_level,omitempty"`
 	NumericOrdering *bool  `json:"numeric_ordering,omitempty"`
-	Locale          string `json:"locale" validate:"required"`
-	CaseFirst       string `json:"case_first,omitempty"`
+	Backwards       *bool  `json:"backwards,omitempty"`
+	Locale          string `json:"locale" validate:"required,mongodbCollationLocale"`
+	CaseFirst       string `json:"case_first,omitempty" validate:"omitempty,oneof=upper lower off"`
+	Alternate       string `json:"alternate,omitempty" validate:"omitempty,oneof=non-ignorable shifted"`
+	MaxVariable     string `json:"max_variable,omitempty" validate:"omitempty,oneof=punct space"`
 }
 
 type IndexCreateOption struct {
@@ -44,56 +49,84 @@ func (v *IndexCreateBodyValidate) Validate() error {
 	if err := validateEngine.Struct(v); err != nil {
 		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
 	}
-	if v.Options.ExpireAfterSeconds != nil && len(v.Keys) > 1 {
-		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "ttl_compound"}})
+	return validateIndexDefinition(v.Keys, v.Options)
+}
+
+// validateIndexDefinition checks the keys and options of an index the way the server does when
+// building it, so an index it would refuse is rejected when saved rather than when synced. The
+// errors are keyed by their path in the body.
+func validateIndexDefinition(keys []IndexCreateKey, options IndexCreateOption) error {
+	result := fiber.Map{}
+	if options.ExpireAfterSeconds != nil && len(keys) > 1 {
+		result["keys"] = "ttl_compound"
 	}
-	hasTextIndex := false
-	for _, key := range v.Keys {
-		switch val := key.Value.(type) {
-		case float64:
-			if val != 1 && val != -1 {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		case int32:
-			if val != 1 && val != -1 {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		case int:
-			if val != 1 && val != -1 {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		case string:
-			if val == "text" {
-				hasTextIndex = true
-			} else {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		default:
-			return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
+	textFields := make(map[string]struct{})
+	isTextEnded := false
+	for i, key := range keys {
+		path := fmt.Sprintf("keys[%d]", i)
+		if key.Field == "_fts" || key.Field == "_ftsx" {
+			result[path+".field"] = "reserved for text indexes"
+		}
+		if !isIndexKeyValue(key.Value) {
+			result[path+".value"] = "value must be 1, -1, or \"text\""
+			continue
 		}
+		if key.Value != "text" {
+			isTextEnded = len(textFields) > 0
+			continue
+		}
+		if isTextEnded {
+			result[path+".value"] = "text fields must be adjacent"
+		}
+		textFields[key.Field] = struct{}{}
 	}
-	if hasTextIndex && v.Options.Collation != nil {
-		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"options": "text indexes cannot have collation"}})
+	if options.Collation != nil {
+		if len(textFields) > 0 {
+			result["options.collation"] = "text indexes cannot have collation"
+		}
+		collation := options.Collation
+		if collation.Locale == "simple" && (collation.Strength != nil || collation.CaseLevel != nil || collation.NumericOrdering != nil || collation.CaseFirst != "" ||
+			collation.Backwards != nil || collation.Alternate != "" || collation.MaxVariable != "") {
+			result["options.collation.locale"] = "simple cannot be combined with other collation options"
+		}
 	}
-	if v.Options.Collation != nil && v.Options.Collation.Locale == "" {
-		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"options.collation": "locale is required"}})
+	if len(options.Weights) > 0 && len(textFields) == 0 {
+		result["options.weights"] = "weights need a text index"
 	}
-	if hasTextIndex && v.Options.Weights != nil && len(v.Options.Weights) > 0 {
-		textFields := make(map[string]bool)
-		for _, key := range v.Keys {
-			if val, ok := key.Value.(string); ok && val == "text" {
-				textFields[key.Field] = true
-			}
+	for field, weight := range options.Weights {
+		path := "options.weights." + field
+		if _, isText := textFields[field]; !isText && slices.ContainsFunc(keys, func(key IndexCreateKey) bool { return key.Field == field }) {
+			result[path] = "field is not a text field of the index"
+			continue
 		}
-		for field := range textFields {
-			if _, exists := v.Options.Weights[field]; !exists {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"options.weights": "all text fields must be present in weights"}})
-			}
+		if number, ok := weight.(float64); !ok || number <= 0 || number >= maxTextWeight {
+			result[path] = fmt.Sprintf("weight must be a number between 0 and %d exclusive", maxTextWeight)
 		}
 	}
+	if len(result) > 0 {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: result})
+	}
 	return nil
 }
 
+// maxTextWeight bounds the weights of a text index, the server refuses 0 and above.
+const maxTextWeight = 100000
+
+func isIndexKeyValue(value interface{}) bool {
+	switch val := value.(type) {
+	case float64:
+		return val == 1 || val == -1
+	case int32:
+		return val == 1 || val == -1
+	case int:
+		return val == 1 || val == -1
+	case string:
+		return val == "text"
+	default:
+		return false
+	}
+}
+
 type IndexGetResponse struct {
 	CreatedAt    time.Time              `json:"created_at"`
 	UpdatedAt    time.Time              `json:"updated_at"`
@@ -110,8 +143,11 @@ type CollationGetResponse struct {
 	Strength        *int   `json:"strength,omitempty"`
 	CaseLevel       *bool  `json:"case_level,omitempty"`
 	NumericOrdering *bool  `json:"numeric_ordering,omitempty"`
+	Backwards       *bool  `json:"backwards,omitempty"`
 	Locale          string `json:"locale"`
 	CaseFirst       string `json:"case_first,omitempty"`
+	Alternate       string `json:"alternate,omitempty"`
+	MaxVariable     string `json:"max_variable,omitempty"`
 }
 
 type IndexGetResponseOption struct {
@@ -129,6 +165,7 @@ type IndexGetResponseKey struct {
 
 type IndexListByCollectionBodyValidate struct {
 	Query      string             `json:"query" validate:"omitempty"`
+	SortBy     string             `json:"sort_by" validate:"omitempty,oneof=size"`
 	Collection string             `json:"collection" validate:"required"`
 	Page       int64              `json:"page" validate:"omitempty,min=0"`
 	Limit      int64              `json:"limit" validate:"omitempty,min=0"`
@@ -146,6 +183,7 @@ func (v *IndexListByCollectionBodyValidate) Validate() error {
 type IndexListByCollectionResponseItem struct {
 	CreatedAt    time.Time                           `json:"created_at"`
 	UpdatedAt    time.Time                           `json:"updated_at"`
+	Size         *int64                              `json:"size"`
 	Options      IndexListByCollectionResponseOption `json:"options"`
 	Collection   string                              `json:"collection"`
 	Name         string                              `json:"name"`
@@ -193,54 +231,11 @@ func (v *IndexUpdateBodyValidate) Validate() error {
 	if err := validateEngine.Struct(v); err != nil {
 		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
 	}
-	if v.Options.ExpireAfterSeconds != nil && len(v.Keys) > 1 {
-		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "ttl_compound"}})
-	}
-	hasTextIndex := false
-	for _, key := range v.Keys {
-		switch val := key.Value.(type) {
-		case float64:
-			if val != 1 && val != -1 {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		case int32:
-			if val != 1 && val != -1 {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		case int:
-			if val != 1 && val != -1 {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		case string:
-			if val == "text" {
-				hasTextIndex = true
-			} else {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-			}
-		default:
-			return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"keys": "value must be 1, -1, or \"text\""}})
-		}
-	}
-	if hasTextIndex && v.Options.Collation != nil {
-		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"options": "text indexes cannot have collation"}})
-	}
-	if v.Options.Collation != nil && v.Options.Collation.Locale == "" {
-		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"options.collation": "locale is required"}})
+	keys := make([]IndexCreateKey, len(v.Keys))
+	for i, key := range v.Keys {
+		keys[i] = IndexCreateKey(key)
 	}
-	if hasTextIndex && v.Options.Weights != nil && len(v.Options.Weights) > 0 {
-		textFields := make(map[string]bool)
-		for _, key := range v.Keys {
-			if val, ok := key.Value.(string); ok && val == "text" {
-				textFields[key.Field] = true
-			}
-		}
-		for field := range textFields {
-			if _, exists := v.Options.Weights[field]; !exists {
-				return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: fiber.Map{"options.weights": "all text fields must be present in weights"}})
-			}
-		}
-	}
-	return nil
+	return validateIndexDefinition(keys, IndexCreateOption(v.Options))
 }
 
 type IndexCompareByCollectionsValidate struct {
@@ -256,18 +251,50 @@ func (v *IndexCompareByCollectionsValidate) Validate() error {
 	return nil
 }
 
+// IndexBuildEstimate is the estimated cost of building missing indexes, sizes in bytes.
+// AdditionalDisk adds to IndexSize the keys a build spills to disk while sorting. ExceedsCache
+// tells that the indexes of the database with the new ones would outgrow the WiredTiger cache of
+// the target.
+type IndexBuildEstimate struct {
+	IndexSize      int64   `json:"index_size"`
+	AdditionalDisk int64   `json:"additional_disk"`
+	Entries        int64   `json:"entries"`
+	BuildSeconds   float64 `json:"build_seconds"`
+	ExceedsCache   bool    `json:"exceeds_cache"`
+}
+
+// IndexBuildEstimateTotal sums the builds of every compared collection. CacheSize is 0 when the
+// cache of the target is unknown.
+type IndexBuildEstimateTotal struct {
+	IndexBuildEstimate
+	CacheSize        int64 `json:"cache_size"`
+	CurrentIndexSize int64 `json:"current_index_size"`
+}
+
+// IndexCompareByCollectionsResponseItem tells the type of the collection on the cluster, empty
+// when it does not exist there. BuildEstimate sums the builds of its missing indexes.
 type IndexCompareByCollectionsResponseItem struct {
+	BuildEstimate    *IndexBuildEstimate              `json:"build_estimate,omitempty"`
+	Type             string                           `json:"type"`
+	IsCapped         bool                             `json:"is_capped"`
+	IsClustered      bool                             `json:"is_clustered"`
 	Collection       string                           `json:"collection"`
 	MissingIndexes   []IndexCompareByCollectionsIndex `json:"missing_indexes"`
 	MatchedIndexes   []IndexCompareByCollectionsIndex `json:"matched_indexes"`
 	RedundantIndexes []IndexCompareByCollectionsIndex `json:"redundant_indexes"`
 }
 
+// IndexCompareByCollectionsIndex of a missing index tells in Unsupported why the collection
+// cannot have it, or in BuildEstimate what building it costs. Warnings of a declared index report
+// keys the sampled documents never hold.
 type IndexCompareByCollectionsIndex struct {
-	Options      IndexCompareByCollectionsIndexOption `json:"options,omitempty"`
-	Name         string                               `json:"name"`
-	Keys         []IndexCompareByCollectionsIndexKey  `json:"keys"`
-	KeySignature string                               `json:"key_signature"`
+	BuildEstimate *IndexBuildEstimate                  `json:"build_estimate,omitempty"`
+	Options       IndexCompareByCollectionsIndexOption `json:"options,omitempty"`
+	Name          string                               `json:"name"`
+	Keys          []IndexCompareByCollectionsIndexKey  `json:"keys"`
+	KeySignature  string                               `json:"key_signature"`
+	Unsupported   string                               `json:"unsupported,omitempty"`
+	Warnings      []IndexLintIssue                     `json:"warnings,omitempty"`
 }
 
 type IndexCompareByCollectionsIndexOption struct {
@@ -295,18 +322,29 @@ func (v *IndexCompareByDatabaseValidate) Validate() error {
 	return nil
 }
 
+// IndexCompareByDatabaseResponseItem tells the type of the collection on the cluster, empty
+// when it does not exist there. BuildEstimate sums the builds of its missing indexes.
 type IndexCompareByDatabaseResponseItem struct {
+	BuildEstimate    *IndexBuildEstimate           `json:"build_estimate,omitempty"`
+	Type             string                        `json:"type"`
+	IsCapped         bool                          `json:"is_capped"`
+	IsClustered      bool                          `json:"is_clustered"`
 	Collection       string                        `json:"collection"`
 	MissingIndexes   []IndexCompareByDatabaseIndex `json:"missing_indexes"`
 	MatchedIndexes   []IndexCompareByDatabaseIndex `json:"matched_indexes"`
 	RedundantIndexes []IndexCompareByDatabaseIndex `json:"redundant_indexes"`
 }
 
+// IndexCompareByDatabaseIndex of a missing index tells in Unsupported why the collection
+// cannot have it, or in BuildEstimate what building it costs. Warnings of a declared index report keys the sampled documents never hold.
 type IndexCompareByDatabaseIndex struct {
-	Options      IndexCompareByDatabaseIndexOption `json:"options,omitempty"`
-	Name         string                            `json:"name"`
-	Keys         []IndexCompareByDatabaseIndexKey  `json:"keys"`
-	KeySignature string                            `json:"key_signature"`
+	BuildEstimate *IndexBuildEstimate               `json:"build_estimate,omitempty"`
+	Options       IndexCompareByDatabaseIndexOption `json:"options,omitempty"`
+	Name          string                            `json:"name"`
+	Keys          []IndexCompareByDatabaseIndexKey  `json:"keys"`
+	KeySignature  string                            `json:"key_signature"`
+	Unsupported   string                            `json:"unsupported,omitempty"`
+	Warnings      []IndexLintIssue                  `json:"warnings,omitempty"`
 }
 
 type IndexCompareByDatabaseIndexOption struct {
@@ -322,9 +360,230 @@ type IndexCompareByDatabaseIndexKey struct {
 	Field string      `json:"field"`
 }
 
-type IndexSyncByCollectionsValidate struct {
-	Collections []string           `json:"collections" validate:"required,min=1,unique"`
+type IndexUsageByDatabaseValidate struct {
+	Collections       []string           `json:"collections" validate:"omitempty,unique"`
+	DatabaseId        primitive.ObjectID `json:"database_id" validate:"required"`
+	UnusedWindowHours int                `json:"unused_window_hours" validate:"omitempty,min=1"`
+}
+
+func (v *IndexUsageByDatabaseValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type IndexUsageByDatabaseResponseItem struct {
+	Collection string                      `json:"collection"`
+	Indexes    []IndexUsageByDatabaseIndex `json:"indexes"`
+}
+
+type IndexUsageByDatabaseIndex struct {
+	Usage        *IndexUsageByDatabaseUsage        `json:"usage"`
+	Id           *primitive.ObjectID               `json:"id,omitempty"`
+	Options      IndexCompareByDatabaseIndexOption `json:"options,omitempty"`
+	Name         string                            `json:"name"`
+	Keys         []IndexCompareByDatabaseIndexKey  `json:"keys"`
+	KeySignature string                            `json:"key_signature"`
+	IsDeclared   bool                              `json:"is_declared"`
+	IsUnused     bool                              `json:"is_unused"`
+}
+
+type IndexUsageByDatabaseUsage struct {
+	Since   time.Time                    `json:"since"`
+	Members []IndexUsageByDatabaseMember `json:"members"`
+	Ops     int64                        `json:"ops"`
+}
+
+// IndexUsageUnavailableMember is a member whose ops are missing from the usage, no index is
+// flagged unused while one is listed.
+type IndexUsageUnavailableMember struct {
+	Host  string `json:"host"`
+	Error string `json:"error"`
+}
+
+type IndexUsageByDatabaseMember struct {
+	Since time.Time `json:"since"`
+	Host  string    `json:"host"`
+	Ops   int64     `json:"ops"`
+}
+
+type IndexLintValidate struct {
+	Collections []string           `json:"collections" validate:"omitempty,unique"`
+	DatabaseId  primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *IndexLintValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type IndexLintResponseItem struct {
+	Collection string           `json:"collection"`
+	Issues     []IndexLintIssue `json:"issues"`
+}
+
+type IndexLintIssue struct {
+	Related  []string `json:"related"`
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"`
+	Index    string   `json:"index,omitempty"`
+	Message  string   `json:"message"`
+}
+
+type IndexCheckDuplicatesValidate struct {
+	Collections []string           `json:"collections" validate:"omitempty,unique"`
 	DatabaseId  primitive.ObjectID `json:"database_id" validate:"required"`
+	Limit       int64              `json:"limit" validate:"omitempty,min=1,max=1000"`
+}
+
+func (v *IndexCheckDuplicatesValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type IndexCheckDuplicatesResponse struct {
+	Violations []IndexCheckDuplicatesViolation `json:"violations"`
+	Checked    int                             `json:"checked"`
+}
+
+type IndexCheckDuplicatesViolation struct {
+	Collection string                          `json:"collection"`
+	Name       string                          `json:"name"`
+	Duplicates []IndexCheckDuplicatesDuplicate `json:"duplicates"`
+}
+
+type IndexCheckDuplicatesDuplicate struct {
+	Values []IndexCompareByDatabaseIndexKey `json:"values"`
+	Count  int64                            `json:"count"`
+}
+
+type IndexShardConsistencyValidate struct {
+	Collections []string           `json:"collections" validate:"omitempty,unique"`
+	DatabaseId  primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *IndexShardConsistencyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type IndexShardConsistencyResponseItem struct {
+	Collection   string                       `json:"collection"`
+	Shards       []IndexShardConsistencyShard `json:"shards"`
+	Inconsistent []IndexShardConsistencyIndex `json:"inconsistent"`
+	IsConsistent bool                         `json:"is_consistent"`
+}
+
+type IndexShardConsistencyShard struct {
+	Shard   string   `json:"shard"`
+	Missing []string `json:"missing"`
+	Extra   []string `json:"extra"`
+}
+
+type IndexShardConsistencyIndex struct {
+	Name         string   `json:"name"`
+	KeySignature string   `json:"key_signature"`
+	PresentOn    []string `json:"present_on"`
+	MissingOn    []string `json:"missing_on"`
+}
+
+type IndexAdvisorProfileValidate struct {
+	Since            *time.Time         `json:"since" validate:"omitempty"`
+	Collections      []string           `json:"collections" validate:"omitempty,unique"`
+	MinMillis        int64              `json:"min_millis" validate:"omitempty,min=0"`
+	Limit            int64              `json:"limit" validate:"omitempty,min=1,max=10000"`
+	MinExaminedRatio float64            `json:"min_examined_ratio" validate:"omitempty,gt=0"`
+	DatabaseId       primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *IndexAdvisorProfileValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type IndexAdvisorLogValidate struct {
+	Log              string             `json:"log" validate:"required"`
+	MinExaminedRatio float64            `json:"min_examined_ratio" validate:"omitempty,gt=0"`
+	DatabaseId       primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *IndexAdvisorLogValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type IndexAdvisorAcceptValidate struct {
+	Collection string                  `json:"collection" validate:"required"`
+	Name       string                  `json:"name" validate:"omitempty,max=100,ne=_id_"`
+	Keys       []IndexAdvisorAcceptKey `json:"keys" validate:"required,min=1,unique=Field,dive"`
+	DatabaseId primitive.ObjectID      `json:"database_id" validate:"required"`
+}
+
+type IndexAdvisorAcceptKey struct {
+	Field string `json:"field" validate:"required"`
+	Value int32  `json:"value" validate:"required,oneof=1 -1"`
+}
+
+func (v *IndexAdvisorAcceptValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type IndexAdvisorResponse struct {
+	Shapes    []IndexAdvisorShape    `json:"shapes"`
+	Proposals []IndexAdvisorProposal `json:"proposals"`
+	Analyzed  int                    `json:"analyzed"`
+}
+
+type IndexAdvisorShape struct {
+	PlanSummaries []string `json:"plan_summaries"`
+	Collection    string   `json:"collection"`
+	Shape         string   `json:"shape"`
+	Reason        string   `json:"reason,omitempty"`
+	Count         int64    `json:"count"`
+	TotalMillis   int64    `json:"total_millis"`
+	MaxMillis     int64    `json:"max_millis"`
+	KeysExamined  int64    `json:"keys_examined"`
+	DocsExamined  int64    `json:"docs_examined"`
+	Returned      int64    `json:"returned"`
+	CollScans     int64    `json:"coll_scans"`
+	IsSlow        bool     `json:"is_slow"`
+}
+
+type IndexAdvisorProposal struct {
+	CoveredBy    string                           `json:"covered_by,omitempty"`
+	Collection   string                           `json:"collection"`
+	Name         string                           `json:"name"`
+	KeySignature string                           `json:"key_signature"`
+	Keys         []IndexCompareByDatabaseIndexKey `json:"keys"`
+	Shapes       []string                         `json:"shapes"`
+}
+
+type IndexSyncByCollectionsValidate struct {
+	RemovalMode        string             `json:"removal_mode" validate:"omitempty,oneof=drop hide"`
+	Collections        []string           `json:"collections" validate:"required,min=1,unique"`
+	GracePeriodSeconds int64              `json:"grace_period_seconds" validate:"omitempty,min=0"`
+	DatabaseId         primitive.ObjectID `json:"database_id" validate:"required"`
 }
 
 func (v *IndexSyncByCollectionsValidate) Validate() error {
@@ -336,31 +595,58 @@ func (v *IndexSyncByCollectionsValidate) Validate() error {
 }
 
 type IndexSyncStatusResponse struct {
-	StartedAt   time.Time          `json:"started_at"`
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
-	CompletedAt *time.Time         `json:"completed_at,omitempty"`
-	Status      string             `json:"status"`
-	Error       string             `json:"error"`
-	Collections []string           `json:"collections"`
-	Progress    int                `json:"progress"`
-	Id          primitive.ObjectID `json:"id"`
-	DatabaseId  primitive.ObjectID `json:"database_id"`
-	IsFinished  bool               `json:"is_finished"`
+	StartedAt         time.Time              `json:"started_at"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+	CompletedAt       *time.Time             `json:"completed_at,omitempty"`
+	GracePeriodEndsAt *time.Time             `json:"grace_period_ends_at,omitempty"`
+	Status            string                 `json:"status"`
+	Error             string                 `json:"error"`
+	RemovalMode       string                 `json:"removal_mode"`
+	RemovalStatus     string                 `json:"removal_status,omitempty"`
+	RemovalError      string                 `json:"removal_error,omitempty"`
+	Collections       []string               `json:"collections"`
+	IndexBuilds       []IndexSyncBuild       `json:"index_builds"`
+	HiddenIndexes     []IndexSyncHiddenIndex `json:"hidden_indexes"`
+	Progress          int                    `json:"progress"`
+	Id                primitive.ObjectID     `json:"id"`
+	DatabaseId        primitive.ObjectID     `json:"database_id"`
+	IsFinished        bool                   `json:"is_finished"`
+}
+
+type IndexSyncHiddenIndex struct {
+	Collection   string `json:"collection"`
+	Name         string `json:"name"`
+	KeySignature string `json:"key_signature"`
+}
+
+type IndexSyncBuild struct {
+	UpdatedAt   time.Time `json:"updated_at"`
+	Collection  string    `json:"collection"`
+	Host        string    `json:"host"`
+	Shard       string    `json:"shard,omitempty"`
+	Phase       string    `json:"phase"`
+	Indexes     []string  `json:"indexes"`
+	Done        int64     `json:"done"`
+	Total       int64     `json:"total"`
+	SecsRunning int64     `json:"secs_running"`
 }
 
 type IndexSyncStatusListResponseItem struct {
-	StartedAt   time.Time                           `json:"started_at"`
-	CreatedAt   time.Time                           `json:"created_at"`
-	UpdatedAt   time.Time                           `json:"updated_at"`
-	CompletedAt *time.Time                          `json:"completed_at,omitempty"`
-	Collections []string                            `json:"collections"`
-	Database    IndexSyncStatusListResponseDatabase `json:"database"`
-	Status      string                              `json:"status"`
-	Error       string                              `json:"error"`
-	Progress    int                                 `json:"progress"`
-	Id          primitive.ObjectID                  `json:"id"`
-	IsFinished  bool                                `json:"is_finished"`
+	StartedAt         time.Time                           `json:"started_at"`
+	CreatedAt         time.Time                           `json:"created_at"`
+	UpdatedAt         time.Time                           `json:"updated_at"`
+	CompletedAt       *time.Time                          `json:"completed_at,omitempty"`
+	GracePeriodEndsAt *time.Time                          `json:"grace_period_ends_at,omitempty"`
+	Collections       []string                            `json:"collections"`
+	Database          IndexSyncStatusListResponseDatabase `json:"database"`
+	Status            string                              `json:"status"`
+	Error             string                              `json:"error"`
+	RemovalMode       string                              `json:"removal_mode"`
+	RemovalStatus     string                              `json:"removal_status,omitempty"`
+	Progress          int                                 `json:"progress"`
+	Id                primitive.ObjectID                  `json:"id"`
+	IsFinished        bool                                `json:"is_finished"`
 }
 
 type IndexSyncStatusListResponseDatabase struct {
@@ -387,7 +673,9 @@ type IndexSyncFromDatabaseResponse struct {
 }
 
 type IndexSyncByDatabaseValidate struct {
-	DatabaseId primitive.ObjectID `json:"database_id" validate:"required"`
+	RemovalMode        string             `json:"removal_mode" validate:"omitempty,oneof=drop hide"`
+	GracePeriodSeconds int64              `json:"grace_period_seconds" validate:"omitempty,min=0"`
+	DatabaseId         primitive.ObjectID `json:"database_id" validate:"required"`
 }
 
 func (v *IndexSyncByDatabaseValidate) Validate() error {
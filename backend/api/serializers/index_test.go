@@ -0,0 +1,132 @@
+package serializers
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"doctor-manager-api/common/request/validator"
+	"doctor-manager-api/common/response"
+)
+
+func TestIndexCreateBodyValidate(t *testing.T) {
+	validator.InitValidateEngine()
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "valid",
+			body: `{"keys": [{"field": "a", "value": 1}, {"field": "b", "value": -1}], "options": {"collation": {"locale": "fr", "strength": 2, "case_first": "upper", "alternate": "shifted", "max_variable": "space", "backwards": true}}}`,
+		},
+		{
+			name: "valid text index",
+			body: `{"keys": [{"field": "category", "value": 1}, {"field": "title", "value": "text"}, {"field": "body", "value": "text"}], "options": {"default_language": "french", "weights": {"title": 10}}}`,
+		},
+		{
+			name: "unknown text language",
+			body: `{"keys": [{"field": "title", "value": "text"}], "options": {"default_language": "klingon"}}`,
+			want: []string{"options.default_language"},
+		},
+		{
+			name: "strength out of range",
+			body: `{"keys": [{"field": "a", "value": 1}], "options": {"collation": {"locale": "fr", "strength": 6}}}`,
+			want: []string{"options.collation.strength"},
+		},
+		{
+			name: "unknown case first",
+			body: `{"keys": [{"field": "a", "value": 1}], "options": {"collation": {"locale": "fr", "case_first": "title"}}}`,
+			want: []string{"options.collation.case_first"},
+		},
+		{
+			name: "unknown alternate",
+			body: `{"keys": [{"field": "a", "value": 1}], "options": {"collation": {"locale": "fr", "alternate": "ignorable"}}}`,
+			want: []string{"options.collation.alternate"},
+		},
+		{
+			name: "unknown max variable",
+			body: `{"keys": [{"field": "a", "value": 1}], "options": {"collation": {"locale": "fr", "alternate": "shifted", "max_variable": "symbol"}}}`,
+			want: []string{"options.collation.max_variable"},
+		},
+		{
+			name: "backwards with the simple locale",
+			body: `{"keys": [{"field": "a", "value": 1}], "options": {"collation": {"locale": "simple", "backwards": true}}}`,
+			want: []string{"options.collation.locale"},
+		},
+		{
+			name: "unknown locale",
+			body: `{"keys": [{"field": "a", "value": 1}], "options": {"collation": {"locale": "xx"}}}`,
+			want: []string{"options.collation.locale"},
+		},
+		{
+			name: "text index with collation",
+			body: `{"keys": [{"field": "title", "value": "text"}], "options": {"collation": {"locale": "fr"}}}`,
+			want: []string{"options.collation"},
+		},
+		{
+			name: "text fields apart",
+			body: `{"keys": [{"field": "title", "value": "text"}, {"field": "category", "value": 1}, {"field": "body", "value": "text"}], "options": {}}`,
+			want: []string{"keys[2].value"},
+		},
+		{
+			name: "weights without text",
+			body: `{"keys": [{"field": "a", "value": 1}], "options": {"weights": {"b": 2}}}`,
+			want: []string{"options.weights"},
+		},
+		{
+			name: "weight of a non-text key",
+			body: `{"keys": [{"field": "category", "value": 1}, {"field": "title", "value": "text"}], "options": {"weights": {"category": 2}}}`,
+			want: []string{"options.weights.category"},
+		},
+		{
+			name: "weight out of range",
+			body: `{"keys": [{"field": "title", "value": "text"}], "options": {"weights": {"title": 0, "body": 100000}}}`,
+			want: []string{"options.weights.body", "options.weights.title"},
+		},
+		{
+			name: "ttl on a compound index",
+			body: `{"keys": [{"field": "a", "value": 1}, {"field": "b", "value": 1}], "options": {"expire_after_seconds": 60}}`,
+			want: []string{"keys"},
+		},
+		{
+			name: "reserved text field",
+			body: `{"keys": [{"field": "_fts", "value": 1}], "options": {}}`,
+			want: []string{"keys[0].field"},
+		},
+		{
+			name: "unknown key value",
+			body: `{"keys": [{"field": "a", "value": 2}], "options": {}}`,
+			want: []string{"keys[0].value"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body IndexCreateBodyValidate
+			if err := json.Unmarshal([]byte(test.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			body.Collection = "orders"
+			body.DatabaseId[0] = 1
+			got := make([]string, 0)
+			if err := body.Validate(); err != nil {
+				e := new(response.Error)
+				if !errors.As(err, &e) || e.Code != fiber.StatusBadRequest {
+					t.Fatalf("Validate() = %v, want a bad request", err)
+				}
+				data, ok := e.Data.(fiber.Map)
+				if !ok {
+					t.Fatalf("Validate() data = %#v, want a map of paths", e.Data)
+				}
+				got = slices.Sorted(maps.Keys(data))
+			}
+			if !slices.Equal(got, test.want) && (len(got) != 0 || len(test.want) != 0) {
+				t.Errorf("Validate() paths = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package serializers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/common/request/validator"
+	"doctor-manager-api/common/response"
+)
+
+type QueryShapeCreateBodyValidate struct {
+	Collection string             `json:"collection" validate:"required"`
+	Name       string             `json:"name" validate:"required,max=100"`
+	Filter     json.RawMessage    `json:"filter" validate:"omitempty,extJsonDocument"`
+	Sort       json.RawMessage    `json:"sort" validate:"omitempty,extJsonDocument"`
+	Projection json.RawMessage    `json:"projection" validate:"omitempty,extJsonDocument"`
+	DatabaseId primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *QueryShapeCreateBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type QueryShapeUpdateBodyValidate struct {
+	Name       string          `json:"name" validate:"required,max=100"`
+	Filter     json.RawMessage `json:"filter" validate:"omitempty,extJsonDocument"`
+	Sort       json.RawMessage `json:"sort" validate:"omitempty,extJsonDocument"`
+	Projection json.RawMessage `json:"projection" validate:"omitempty,extJsonDocument"`
+}
+
+func (v *QueryShapeUpdateBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type QueryShapeListBodyValidate struct {
+	Query      string             `json:"query" validate:"omitempty"`
+	Collection string             `json:"collection" validate:"omitempty"`
+	Page       int64              `json:"page" validate:"omitempty,min=0"`
+	Limit      int64              `json:"limit" validate:"omitempty,min=0"`
+	DatabaseId primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *QueryShapeListBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+// QueryShapeGetResponse carries the filter, sort and projection as the extended JSON documents
+// they are stored as.
+type QueryShapeGetResponse struct {
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	Collection string             `json:"collection"`
+	Name       string             `json:"name"`
+	Filter     json.RawMessage    `json:"filter"`
+	Sort       json.RawMessage    `json:"sort"`
+	Projection json.RawMessage    `json:"projection"`
+	Id         primitive.ObjectID `json:"id"`
+	DatabaseId primitive.ObjectID `json:"database_id"`
+}
+
+type QueryShapeExplainValidate struct {
+	Collections []string           `json:"collections" validate:"omitempty,unique"`
+	DatabaseId  primitive.ObjectID `json:"database_id" validate:"required"`
+}
+
+func (v *QueryShapeExplainValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+// QueryShapeExplainResponseItem is the plan of one query shape, Plan is nil and Error set when
+// the server could not explain it.
+type QueryShapeExplainResponseItem struct {
+	Plan       *QueryShapeExplainPlan `json:"plan"`
+	Collection string                 `json:"collection"`
+	Name       string                 `json:"name"`
+	Error      string                 `json:"error,omitempty"`
+	Id         primitive.ObjectID     `json:"id"`
+}
+
+// QueryShapeExplainPlan is the winning plan of a query shape. WinningIndex is the first index the
+// plan reads, empty for a collection scan.
+type QueryShapeExplainPlan struct {
+	WinningIndex    string   `json:"winning_index"`
+	IndexNames      []string `json:"index_names"`
+	Stages          []string `json:"stages"`
+	KeysExamined    int64    `json:"keys_examined"`
+	DocsExamined    int64    `json:"docs_examined"`
+	Returned        int64    `json:"returned"`
+	ExecutionMillis int64    `json:"execution_millis"`
+	IsCollScan      bool     `json:"is_coll_scan"`
+	HasBlockingSort bool     `json:"has_blocking_sort"`
+}
+
+type QueryShapeHiddenIndex struct {
+	Collection string `json:"collection"`
+	Name       string `json:"name"`
+}
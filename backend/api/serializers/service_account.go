@@ -0,0 +1,89 @@
+package serializers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/common/request/validator"
+	"doctor-manager-api/common/response"
+)
+
+type ServiceAccountCreateBodyValidate struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"omitempty,max=500"`
+}
+
+func (v *ServiceAccountCreateBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type ServiceAccountGetResponse struct {
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	CreatedBy   primitive.ObjectID `json:"created_by"`
+	Id          primitive.ObjectID `json:"id"`
+}
+
+type ServiceAccountListBodyValidate struct {
+	Query string `json:"query" validate:"omitempty,max=500"`
+	Page  int64  `json:"page" validate:"omitempty,min=0"`
+	Limit int64  `json:"limit" validate:"omitempty,min=0"`
+}
+
+func (v *ServiceAccountListBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type ServiceAccountListResponseItem struct {
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	CreatedBy   primitive.ObjectID `json:"created_by"`
+	Id          primitive.ObjectID `json:"id"`
+}
+
+type ServiceAccountCreateApiKeyBodyValidate struct {
+	ExpiredAt *time.Time `json:"expired_at" validate:"omitempty"`
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=database:read database:write index:read index:write index:compare index:sync"`
+}
+
+func (v *ServiceAccountCreateApiKeyBodyValidate) Validate() error {
+	validateEngine := validator.GetValidateEngine()
+	if err := validateEngine.Struct(v); err != nil {
+		return response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: validator.ParseValidateError(err)})
+	}
+	return nil
+}
+
+type ServiceAccountCreateApiKeyResponse struct {
+	ExpiredAt time.Time          `json:"expired_at"`
+	Key       string             `json:"key"`
+	Prefix    string             `json:"prefix"`
+	Scopes    []string           `json:"scopes"`
+	Id        primitive.ObjectID `json:"id"`
+}
+
+type ServiceAccountListApiKeysResponseItem struct {
+	CreatedAt  time.Time          `json:"created_at"`
+	ExpiredAt  time.Time          `json:"expired_at"`
+	LastUsedAt *time.Time         `json:"last_used_at"`
+	RevokedAt  *time.Time         `json:"revoked_at"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	Scopes     []string           `json:"scopes"`
+	Id         primitive.ObjectID `json:"id"`
+}
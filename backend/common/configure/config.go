@@ -13,21 +13,61 @@ import (
 var config *Configuration
 
 type Configuration struct {
-	TokenPublicKey           string        `env:"TOKEN_PUBLIC_KEY_PATH,file" envDefault:"certs/public.pem" envExpand:"true"`
-	TokenPrivateKey          string        `env:"TOKEN_PRIVATE_KEY_PATH,file" envDefault:"certs/private.pem" envExpand:"true"`
-	Port                     string        `env:"PORT" envDefault:"8216"`
-	Host                     string        `env:"HOST" envDefault:"0.0.0.0"`
-	TokenType                string        `env:"TOKEN_TYPE" envDefault:"Bearer"`
-	MongoDBDoctorManagerUri  string        `env:"MONGODB_DOCTOR_MANAGER_URI" envDefault:"mongodb://localhost:27017"`
-	MongoDBDoctorManagerName string        `env:"MONGODB_DOCTOR_MANAGER_NAME" envDefault:"db_doctor_manager"`
-	PaginationMaxItem        int64         `env:"PAGINATION_MAX_ITEM" envDefault:"50"`
-	JobConcurrency           int           `env:"JOB_CONCURRENCY" envDefault:"10"`
-	MongoDBRequestTimeout    time.Duration `env:"MONGODB_REQUEST_TIMEOUT" envDefault:"3m"`
-	AccessTokenTimeout       time.Duration `env:"ACCESS_TOKEN_TIMEOUT" envDefault:"10m"`
-	RefreshTokenTimeout      time.Duration `env:"REFRESH_TOKEN_TIMEOUT" envDefault:"24h"`
-	Debug                    bool          `env:"DEBUG" envDefault:"false"`
-	ElasticAPMEnable         bool          `env:"ELASTIC_APM_ENABLE" envDefault:"false"`
-	MongoAutoIndexing        bool          `env:"MONGO_AUTO_INDEXING" envDefault:"false"`
+	TokenPublicKey            string            `env:"TOKEN_PUBLIC_KEY_PATH,file" envDefault:"certs/public.pem" envExpand:"true"`
+	TokenPrivateKey           string            `env:"TOKEN_PRIVATE_KEY_PATH,file" envDefault:"certs/private.pem" envExpand:"true"`
+	TokenVerificationKeysDir  string            `env:"TOKEN_VERIFICATION_KEYS_DIR"`
+	Port                      string            `env:"PORT" envDefault:"8216"`
+	Host                      string            `env:"HOST" envDefault:"0.0.0.0"`
+	TokenType                 string            `env:"TOKEN_TYPE" envDefault:"Bearer"`
+	ApiKeyType                string            `env:"API_KEY_TYPE" envDefault:"ApiKey"`
+	RegistrationMode          string            `env:"REGISTRATION_MODE" envDefault:"open"`
+	AdminUsername             string            `env:"ADMIN_USERNAME"`
+	AdminEmail                string            `env:"ADMIN_EMAIL"`
+	AdminPassword             string            `env:"ADMIN_PASSWORD"`
+	OidcIssuer                string            `env:"OIDC_ISSUER"`
+	OidcClientId              string            `env:"OIDC_CLIENT_ID"`
+	OidcClientSecret          string            `env:"OIDC_CLIENT_SECRET"`
+	OidcRedirectUrl           string            `env:"OIDC_REDIRECT_URL"`
+	DataEncryptionKey         string            `env:"DATA_ENCRYPTION_KEY"`
+	OidcGroupsClaim           string            `env:"OIDC_GROUPS_CLAIM" envDefault:"groups"`
+	MongoDBDoctorManagerUri   string            `env:"MONGODB_DOCTOR_MANAGER_URI" envDefault:"mongodb://localhost:27017"`
+	MongoDBDoctorManagerName  string            `env:"MONGODB_DOCTOR_MANAGER_NAME" envDefault:"db_doctor_manager"`
+	OidcScopes                []string          `env:"OIDC_SCOPES" envDefault:"openid,profile,email" envSeparator:","`
+	OidcAdminGroups           []string          `env:"OIDC_ADMIN_GROUPS" envSeparator:","`
+	OidcAllowedGroups         []string          `env:"OIDC_ALLOWED_GROUPS" envSeparator:","`
+	IndexLintRules            map[string]string `env:"INDEX_LINT_RULES" envSeparator:"," envKeyValSeparator:"="`
+	PaginationMaxItem         int64             `env:"PAGINATION_MAX_ITEM" envDefault:"50"`
+	JobConcurrency            int               `env:"JOB_CONCURRENCY" envDefault:"10"`
+	IndexLintMaxIndexes       int               `env:"INDEX_LINT_MAX_INDEXES" envDefault:"10"`
+	TargetMaxPoolSize         uint64            `env:"TARGET_MAX_POOL_SIZE" envDefault:"10"`
+	DuplicateCheckLimit       int64             `env:"DUPLICATE_CHECK_LIMIT" envDefault:"10"`
+	SchemaSampleSize          int64             `env:"SCHEMA_SAMPLE_SIZE" envDefault:"100"`
+	AdvisorProfileLimit       int64             `env:"ADVISOR_PROFILE_LIMIT" envDefault:"1000"`
+	AdvisorMinExaminedRatio   float64           `env:"ADVISOR_MIN_EXAMINED_RATIO" envDefault:"10"`
+	LoginMaxFailures          int               `env:"LOGIN_MAX_FAILURES" envDefault:"5"`
+	LoginMaxFailuresPerIp     int               `env:"LOGIN_MAX_FAILURES_PER_IP" envDefault:"20"`
+	MongoDBRequestTimeout     time.Duration     `env:"MONGODB_REQUEST_TIMEOUT" envDefault:"3m"`
+	AccessTokenTimeout        time.Duration     `env:"ACCESS_TOKEN_TIMEOUT" envDefault:"10m"`
+	RefreshTokenTimeout       time.Duration     `env:"REFRESH_TOKEN_TIMEOUT" envDefault:"24h"`
+	ApiKeyDefaultTimeout      time.Duration     `env:"API_KEY_DEFAULT_TIMEOUT" envDefault:"2160h"`
+	CleanupInterval           time.Duration     `env:"CLEANUP_INTERVAL" envDefault:"1h"`
+	InvitationTimeout         time.Duration     `env:"INVITATION_TIMEOUT" envDefault:"72h"`
+	LoginFailureWindow        time.Duration     `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	LoginLockoutDuration      time.Duration     `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+	LoginDelayBase            time.Duration     `env:"LOGIN_DELAY_BASE" envDefault:"1s"`
+	LoginDelayMax             time.Duration     `env:"LOGIN_DELAY_MAX" envDefault:"30s"`
+	LoginAttemptRetention     time.Duration     `env:"LOGIN_ATTEMPT_RETENTION" envDefault:"720h"`
+	OidcStateTimeout          time.Duration     `env:"OIDC_STATE_TIMEOUT" envDefault:"10m"`
+	TargetIdleTimeout         time.Duration     `env:"TARGET_IDLE_TIMEOUT" envDefault:"10m"`
+	TargetHealthCheckInterval time.Duration     `env:"TARGET_HEALTH_INTERVAL" envDefault:"1m"`
+	SyncProgressInterval      time.Duration     `env:"SYNC_PROGRESS_INTERVAL" envDefault:"2s"`
+	SyncGracePeriod           time.Duration     `env:"SYNC_GRACE_PERIOD" envDefault:"24h"`
+	SyncRemovalInterval       time.Duration     `env:"SYNC_REMOVAL_INTERVAL" envDefault:"1m"`
+	IndexUnusedWindow         time.Duration     `env:"INDEX_UNUSED_WINDOW" envDefault:"168h"`
+	Debug                     bool              `env:"DEBUG" envDefault:"false"`
+	ElasticAPMEnable          bool              `env:"ELASTIC_APM_ENABLE" envDefault:"false"`
+	MongoAutoIndexing         bool              `env:"MONGO_AUTO_INDEXING" envDefault:"false"`
+	OidcEnable                bool              `env:"OIDC_ENABLE" envDefault:"false"`
 }
 
 func (cfg Configuration) ServerAddress() string {
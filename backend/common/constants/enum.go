@@ -6,3 +6,72 @@ const (
 	SyncStatusCompleted = "completed"
 	SyncStatusFailed    = "failed"
 )
+
+// A sync removes redundant indexes by dropping them, or by hiding them first and dropping them
+// once the grace period of the sync ends without a rollback.
+const (
+	SyncRemovalModeDrop = "drop"
+	SyncRemovalModeHide = "hide"
+)
+
+const (
+	SyncRemovalStatusHidden     = "hidden"
+	SyncRemovalStatusDropping   = "dropping"
+	SyncRemovalStatusDropped    = "dropped"
+	SyncRemovalStatusRolledBack = "rolled_back"
+	SyncRemovalStatusFailed     = "failed"
+)
+
+const (
+	AccountRoleAdmin  = "admin"
+	AccountRoleMember = "member"
+)
+
+const (
+	RegistrationModeOpen     = "open"
+	RegistrationModeDisabled = "disabled"
+	RegistrationModeInvite   = "invite"
+)
+
+// BootstrapIdAdmin is the bootstrap of the first admin account.
+const BootstrapIdAdmin = "admin"
+
+const (
+	LoginThrottleKeyPrefixAccount = "account:"
+	LoginThrottleKeyPrefixIp      = "ip:"
+)
+
+const (
+	LoginAttemptReasonSuccess         = "success"
+	LoginAttemptReasonAccountNotFound = "account_not_found"
+	LoginAttemptReasonInvalidPassword = "invalid_password"
+	LoginAttemptReasonAccountDisabled = "account_disabled"
+	LoginAttemptReasonThrottled       = "throttled"
+	LoginAttemptReasonOidcSuccess     = "oidc_success"
+	LoginAttemptReasonOidcDenied      = "oidc_denied"
+)
+
+const (
+	ScopeDatabaseRead  = "database:read"
+	ScopeDatabaseWrite = "database:write"
+	ScopeIndexRead     = "index:read"
+	ScopeIndexWrite    = "index:write"
+	ScopeIndexCompare  = "index:compare"
+	ScopeIndexSync     = "index:sync"
+)
+
+const (
+	DatabaseAuthMechanismScramSha1   = "SCRAM-SHA-1"
+	DatabaseAuthMechanismScramSha256 = "SCRAM-SHA-256"
+	DatabaseAuthMechanismX509        = "MONGODB-X509"
+	DatabaseAuthMechanismAws         = "MONGODB-AWS"
+	DatabaseAuthMechanismPlain       = "PLAIN"
+)
+
+const (
+	SortByCollection     = "collection"
+	SortByCount          = "count"
+	SortBySize           = "size"
+	SortByStorageSize    = "storage_size"
+	SortByTotalIndexSize = "total_index_size"
+)
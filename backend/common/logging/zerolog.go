@@ -18,8 +18,12 @@ import (
 
 var logger *zerolog.Logger
 
+// InitLogger applies the configured level. The loggers packages took at init are the same
+// logger and follow it.
 func InitLogger() {
-	logger = createLogger()
+	if logger == nil {
+		logger = createLogger()
+	}
 	if configure.GetConfig().Debug {
 		zerolog.SetGlobalLevel(zerolog.TraceLevel)
 	} else {
@@ -27,9 +31,11 @@ func InitLogger() {
 	}
 }
 
+// GetLogger does not load the configuration, so packages holding a logger can be tested
+// without one.
 func GetLogger() *zerolog.Logger {
 	if logger == nil {
-		InitLogger()
+		logger = createLogger()
 	}
 	return logger
 }
@@ -5,10 +5,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-var (
-	cfg = configure.GetConfig()
-)
-
 // Pagination contains pagination request
 type Pagination struct {
 	Total *int64 `json:"-"`
@@ -22,11 +18,11 @@ func (p *Pagination) SetTotal(value int64) {
 }
 
 func (p *Pagination) Format() {
-	maxLimit := cfg.PaginationMaxItem
+	maxLimit := configure.GetConfig().PaginationMaxItem
 	if p.Limit > maxLimit {
 		p.Limit = maxLimit
 	} else if p.Limit < 1 {
-		p.Limit = cfg.PaginationMaxItem
+		p.Limit = maxLimit
 	}
 	if p.Page < 1 {
 		p.Page = 1
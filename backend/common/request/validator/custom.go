@@ -1,21 +1,86 @@
 package validator
 
 import (
+	"net/url"
 	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
+var databaseUriHost = regexp.MustCompile(`^(?:[a-zA-Z0-9.-]+|\[[0-9a-fA-F:.]+\])(?::(\d{1,5}))?$`)
+
+// databaseUri accepts mongodb:// and mongodb+srv:// connection strings. The SRV record
+// is not resolved, an srv uri only needs a single host without port.
 var databaseUri = ValidateFunction{
 	Tag: "databaseUri",
 	Function: func(fl validator.FieldLevel) bool {
 		fieldString := fl.Field().String()
-		regex := `^mongodb:\/\/(?:[a-zA-Z0-9_-]+(?::[a-zA-Z0-9!$&'()*+,;=._-]+)?@)?(?:[a-zA-Z0-9.-]+(?::\d+)?)((?:,(?:[a-zA-Z0-9.-]+(?::\d+)?))*)(?:\/[a-zA-Z0-9_-]*)?(?:\?[a-zA-Z0-9=&_-]+)?$`
-		r := regexp.MustCompile(regex)
-		return r.MatchString(fieldString)
+		isSrv := false
+		switch {
+		case strings.HasPrefix(fieldString, "mongodb+srv://"):
+			isSrv = true
+			fieldString = strings.TrimPrefix(fieldString, "mongodb+srv://")
+		case strings.HasPrefix(fieldString, "mongodb://"):
+			fieldString = strings.TrimPrefix(fieldString, "mongodb://")
+		default:
+			return false
+		}
+		fieldString, query, hasQuery := strings.Cut(fieldString, "?")
+		if hasQuery {
+			if _, err := url.ParseQuery(query); err != nil {
+				return false
+			}
+		}
+		hostList, _, _ := strings.Cut(fieldString, "/")
+		if at := strings.LastIndex(hostList, "@"); at >= 0 {
+			username, password, _ := strings.Cut(hostList[:at], ":")
+			if username == "" || strings.ContainsAny(username+password, "/?#[]@") {
+				return false
+			}
+			hostList = hostList[at+1:]
+		}
+		hosts := strings.Split(hostList, ",")
+		if isSrv && len(hosts) != 1 {
+			return false
+		}
+		for _, host := range hosts {
+			match := databaseUriHost.FindStringSubmatch(host)
+			if match == nil {
+				return false
+			}
+			if isSrv && match[1] != "" {
+				return false
+			}
+		}
+		return true
 	},
 }
 
+// mongodbLanguages are the languages of text search, by name and by ISO 639-1 code, as the
+// server accepts them for default_language. "none" indexes every word without stemming or stop
+// words.
+var mongodbLanguages = map[string]struct{}{
+	"none": {},
+
+	"danish": {}, "da": {},
+	"dutch": {}, "nl": {},
+	"english": {}, "en": {},
+	"finnish": {}, "fi": {},
+	"french": {}, "fr": {},
+	"german": {}, "de": {},
+	"hungarian": {}, "hu": {},
+	"italian": {}, "it": {},
+	"norwegian": {}, "nb": {},
+	"portuguese": {}, "pt": {},
+	"romanian": {}, "ro": {},
+	"russian": {}, "ru": {},
+	"spanish": {}, "es": {},
+	"swedish": {}, "sv": {},
+	"turkish": {}, "tr": {},
+}
+
 var mongodbLanguage = ValidateFunction{
 	Tag: "mongodbLanguage",
 	Function: func(fl validator.FieldLevel) bool {
@@ -23,21 +88,62 @@ var mongodbLanguage = ValidateFunction{
 		if fieldString == "" {
 			return true
 		}
-		if fieldString == "none" {
-			return true
-		}
-		// Validate ISO 639-1 two-letter language codes
-		// MongoDB supports standard ISO 639-1 codes (lowercase, 2 letters)
-		if len(fieldString) != 2 {
+		_, exists := mongodbLanguages[fieldString]
+		return exists
+	},
+}
+
+// mongodbCollationLocales are the locales a collation accepts, "simple" being the binary
+// comparison of strings.
+var mongodbCollationLocales = map[string]struct{}{
+	"simple": {},
+
+	"af": {}, "sq": {}, "am": {}, "ar": {}, "hy": {}, "as": {}, "az": {}, "be": {}, "bn": {},
+	"bs": {}, "bs_Cyrl": {}, "bg": {}, "my": {}, "ca": {}, "chr": {}, "zh": {}, "zh_Hant": {},
+	"hr": {}, "cs": {}, "da": {}, "nl": {}, "dz": {}, "en": {}, "en_US": {}, "en_US_POSIX": {},
+	"eo": {}, "et": {}, "ee": {}, "fo": {}, "fil": {}, "fi": {}, "fr": {}, "fr_CA": {}, "gl": {},
+	"ka": {}, "de": {}, "de_AT": {}, "el": {}, "gu": {}, "ha": {}, "haw": {}, "he": {}, "hi": {},
+	"hu": {}, "is": {}, "ig": {}, "smn": {}, "id": {}, "ga": {}, "it": {}, "ja": {}, "kl": {},
+	"kn": {}, "kk": {}, "km": {}, "kok": {}, "ko": {}, "ky": {}, "lkt": {}, "lo": {}, "lv": {},
+	"ln": {}, "lt": {}, "dsb": {}, "lb": {}, "mk": {}, "ms": {}, "ml": {}, "mt": {}, "mr": {},
+	"mn": {}, "ne": {}, "se": {}, "nb": {}, "nn": {}, "or": {}, "om": {}, "ps": {}, "fa": {},
+	"fa_AF": {}, "pl": {}, "pt": {}, "pa": {}, "ro": {}, "ru": {}, "sr": {}, "sr_Latn": {},
+	"si": {}, "sk": {}, "sl": {}, "es": {}, "sw": {}, "sv": {}, "ta": {}, "te": {}, "th": {},
+	"bo": {}, "to": {}, "tr": {}, "uk": {}, "hsb": {}, "ur": {}, "ug": {}, "vi": {}, "wae": {},
+	"cy": {}, "yi": {}, "yo": {}, "zu": {},
+}
+
+// mongodbCollationVariants are the collation keywords a locale takes after @collation=, such
+// as de@collation=phonebook.
+var mongodbCollationVariants = map[string]struct{}{
+	"big5han": {}, "compat": {}, "dictionary": {}, "eor": {}, "gb2312han": {}, "phonebook": {},
+	"pinyin": {}, "search": {}, "standard": {}, "stroke": {}, "traditional": {}, "unihan": {},
+	"zhuyin": {},
+}
+
+// mongodbCollationLocale accepts the locales of a collation, with an optional variant. The
+// server knows which variants each locale has, only the keyword is checked here.
+var mongodbCollationLocale = ValidateFunction{
+	Tag: "mongodbCollationLocale",
+	Function: func(fl validator.FieldLevel) bool {
+		locale, variant, hasVariant := strings.Cut(fl.Field().String(), "@collation=")
+		if _, exists := mongodbCollationLocales[locale]; !exists {
 			return false
 		}
-		regex := regexp.MustCompile(`^[a-z]{2}$`)
-		if !regex.MatchString(fieldString) {
-			return false
+		if !hasVariant {
+			return true
 		}
-		// Common MongoDB supported languages (non-exhaustive list, but validates format)
-		// MongoDB accepts any valid ISO 639-1 code
-		// Common ones: da, nl, en, fi, fr, de, hu, it, nb, pt, ro, ru, es, sv, tr
-		return true
+		_, exists := mongodbCollationVariants[variant]
+		return exists && locale != "simple"
+	},
+}
+
+// extJsonDocument accepts a raw JSON document in relaxed or canonical extended JSON, the form a
+// filter written in mongosh takes once its helpers such as ObjectId() are spelled out.
+var extJsonDocument = ValidateFunction{
+	Tag: "extJsonDocument",
+	Function: func(fl validator.FieldLevel) bool {
+		var document bson.D
+		return bson.UnmarshalExtJSON(fl.Field().Bytes(), false, &document) == nil
 	},
 }
@@ -37,7 +37,7 @@ func InitValidateEngine() *validator.Validate {
 		}
 		return name
 	})
-	RegisterValidate(databaseUri, mongodbLanguage)
+	RegisterValidate(databaseUri, mongodbLanguage, mongodbCollationLocale, extJsonDocument)
 	return validateEngine
 }
 
@@ -60,7 +60,17 @@ func ParseValidateError(rawErr error) fiber.Map {
 		if err.Param() != "" {
 			errValue = fmt.Sprintf("%s=%s", errValue, err.Param())
 		}
-		result[err.Field()] = errValue
+		result[fieldPath(err)] = errValue
 	}
 	return result
 }
+
+// fieldPath is the path of the field in the request body, such as options.collation.strength or
+// keys[1].field, the namespace of the error without the validated struct.
+func fieldPath(err validator.FieldError) string {
+	_, path, found := strings.Cut(err.Namespace(), ".")
+	if !found {
+		return err.Field()
+	}
+	return path
+}
@@ -10,4 +10,25 @@ var (
 	ErrTokenWrongFormat      = "Token is wrong format"
 	ErrTokenWrong            = "Token is wrong"
 	ErrTokenRevoked          = "Token is revoked"
+	ErrScopeMissing          = "Api key is missing a required scope"
+	ErrPermissionDenied      = "Permission denied"
+	ErrRegistrationDisabled  = "Registration is disabled"
+	ErrInvitationRequired    = "Invitation token is required"
+	ErrInvitationInvalid     = "Invitation token is invalid, expired or already used"
+	ErrAccountDisabled       = "Account is disabled"
+	ErrTooManyLoginAttempts  = "Too many login attempts, try again later"
+	ErrOidcDisabled          = "Single sign-on is not enabled"
+	ErrOidcStateInvalid      = "Single sign-on state is invalid or expired"
+	ErrOidcProviderFailed    = "Identity provider request failed"
+	ErrOidcTokenInvalid      = "Identity provider token is invalid"
+	ErrOidcGroupNotAllowed   = "Identity is not a member of an allowed group"
+	ErrOidcEmailMissing      = "Identity provider did not return an email"
+	ErrOidcEmailNotVerified  = "Email must be verified by the identity provider to link an existing account"
+	ErrOidcAccountLinked     = "Account is already linked to another identity"
+	ErrEncryptionKeyMissing  = "DATA_ENCRYPTION_KEY is not configured, secrets cannot be stored"
+	ErrAuthUsernameRequired  = "Username is required for this auth mechanism"
+	ErrAuthX509TlsRequired   = "MONGODB-X509 requires TLS with a client certificate and key"
+	ErrDatabaseUriRequired   = "Either uri or cluster_id is required"
+	ErrDatabaseClusterMixed  = "uri, tls and auth cannot be set along with cluster_id"
+	ErrClusterInUse          = "Cluster is still referenced by databases"
 )
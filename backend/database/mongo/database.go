@@ -7,6 +7,7 @@ import (
 
 	"doctor-manager-api/common/configure"
 	"doctor-manager-api/common/logging"
+	"doctor-manager-api/database/mongo/models"
 
 	"go.elastic.co/apm/module/apmmongo/v2"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -23,12 +24,55 @@ var (
 func InitDatabase() {
 	managerDBClient = initClientConnection(cfg.MongoDBDoctorManagerUri, cfg.ElasticAPMEnable)
 	autoIndexing()
+	migrateIndexKeySignatures()
+}
+
+// migrateIndexKeySignatures recomputes the key signature of the declared indexes, stored ones
+// keep the format of the release that wrote them and would no longer match live indexes.
+func migrateIndexKeySignatures() {
+	coll := utils.GetIndexCollection()
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	cursor, err := coll.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"keys": 1, "options": 1, "key_signature": 1}))
+	if err != nil {
+		logger.Error().Err(err).Str("function", "migrateIndexKeySignatures").Str("functionInline", "coll.Find").Msg("database")
+		return
+	}
+	var indexes []models.Index
+	if err = cursor.All(ctx, &indexes); err != nil {
+		logger.Error().Err(err).Str("function", "migrateIndexKeySignatures").Str("functionInline", "cursor.All").Msg("database")
+		return
+	}
+	writes := make([]mongo.WriteModel, 0)
+	for _, index := range indexes {
+		if keySignature := index.GetKeySignature(); keySignature != index.KeySignature {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": index.Id}).
+				SetUpdate(bson.M{"$set": bson.M{"key_signature": keySignature}}))
+		}
+	}
+	if len(writes) == 0 {
+		return
+	}
+	if _, err = coll.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		logger.Error().Err(err).Str("function", "migrateIndexKeySignatures").Str("functionInline", "coll.BulkWrite").Msg("database")
+		return
+	}
+	logger.Info().Int("count", len(writes)).Str("function", "migrateIndexKeySignatures").Msg("database")
 }
 
 func autoIndexing() {
 	if cfg.MongoAutoIndexing {
 		managerDBAccountIndex()
 		managerDBAuthTokenIndex()
+		managerDBServiceAccountIndex()
+		managerDBClusterIndex()
+		managerDBApiKeyIndex()
+		managerDBInvitationIndex()
+		managerDBLoginThrottleIndex()
+		managerDBLoginAttemptIndex()
+		managerDBOidcStateIndex()
+		managerDBQueryShapeIndex()
 	}
 }
 
@@ -77,6 +121,11 @@ func managerDBAccountIndex() {
 			Keys:    bson.D{{Key: "email", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys: bson.D{{Key: "oidc_issuer", Value: 1}, {Key: "oidc_subject", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"oidc_subject": bson.M{"$type": "string"}}),
+		},
 	}); err != nil {
 		logger.Fatal().Err(err).Msg("managerDBAccountIndex")
 	}
@@ -94,7 +143,163 @@ func managerDBAuthTokenIndex() {
 			Keys:    bson.D{{Key: "expired_at", Value: 1}},
 			Options: options.Index().SetExpireAfterSeconds(0),
 		},
+		{
+			Keys: bson.D{{Key: "account_id", Value: 1}},
+		},
 	}); err != nil {
 		logger.Fatal().Err(err).Msg("managerDBAuthTokenIndex")
 	}
 }
+
+func managerDBServiceAccountIndex() {
+	collIndex := utils.GetServiceAccountCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBServiceAccountIndex")
+	}
+}
+
+func managerDBClusterIndex() {
+	collIndex := utils.GetClusterCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBClusterIndex")
+	}
+}
+
+func managerDBApiKeyIndex() {
+	collIndex := utils.GetApiKeyCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "prefix", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "service_account_id", Value: 1}},
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBApiKeyIndex")
+	}
+}
+
+func managerDBInvitationIndex() {
+	collIndex := utils.GetInvitationCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBInvitationIndex")
+	}
+}
+
+func managerDBLoginThrottleIndex() {
+	collIndex := utils.GetLoginThrottleCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expired_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBLoginThrottleIndex")
+	}
+}
+
+func managerDBLoginAttemptIndex() {
+	collIndex := utils.GetLoginAttemptCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(cfg.LoginAttemptRetention.Seconds())),
+		},
+		{
+			Keys: bson.D{{Key: "account_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "ip_address", Value: 1}},
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBLoginAttemptIndex")
+	}
+}
+
+func managerDBOidcStateIndex() {
+	collIndex := utils.GetOidcStateCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "state_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expired_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBOidcStateIndex")
+	}
+}
+
+func managerDBQueryShapeIndex() {
+	collIndex := utils.GetQueryShapeCollection().Indexes()
+	ctxDrop, cancelDrop := utils.GetContextTimeout(context.Background())
+	defer cancelDrop()
+	_, _ = collIndex.DropAll(ctxDrop)
+	ctx, cancel := utils.GetContextTimeout(context.Background())
+	defer cancel()
+	if _, err := collIndex.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "database_id", Value: 1}, {Key: "collection", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}); err != nil {
+		logger.Fatal().Err(err).Msg("managerDBQueryShapeIndex")
+	}
+}
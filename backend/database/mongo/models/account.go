@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/common/constants"
 )
 
 type Account struct {
@@ -15,7 +17,16 @@ type Account struct {
 	Avatar       string             `bson:"avatar"`
 	Email        string             `bson:"email"`
 	PasswordHash string             `bson:"password_hash"`
+	Role         string             `bson:"role"`
+	OidcIssuer   string             `bson:"oidc_issuer,omitempty"`
+	OidcSubject  string             `bson:"oidc_subject,omitempty"`
+	Groups       []string           `bson:"groups,omitempty"`
 	Id           primitive.ObjectID `bson:"_id,omitempty"`
+	IsDisabled   bool               `bson:"is_disabled"`
+}
+
+func (m *Account) IsAdmin() bool {
+	return m.Role == constants.AccountRoleAdmin
 }
 
 func (m *Account) CollectionName() string {
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ApiKey struct {
+	CreatedAt        time.Time          `bson:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at"`
+	ExpiredAt        time.Time          `bson:"expired_at"`
+	LastUsedAt       *time.Time         `bson:"last_used_at,omitempty"`
+	RevokedAt        *time.Time         `bson:"revoked_at,omitempty"`
+	Name             string             `bson:"name"`
+	Prefix           string             `bson:"prefix"`
+	KeyHash          string             `bson:"key_hash"`
+	Scopes           []string           `bson:"scopes"`
+	ServiceAccountId primitive.ObjectID `bson:"service_account_id"`
+	CreatedBy        primitive.ObjectID `bson:"created_by"`
+	Id               primitive.ObjectID `bson:"_id,omitempty"`
+}
+
+func (m *ApiKey) IsActive(now time.Time) bool {
+	return m.RevokedAt == nil && now.Before(m.ExpiredAt)
+}
+
+func (m *ApiKey) CollectionName() string {
+	return "api_keys"
+}
@@ -10,6 +10,8 @@ type AuthToken struct {
 	ExpiredAt time.Time          `bson:"expired_at"`
 	CreatedAt time.Time          `bson:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at"`
+	UserAgent string             `bson:"user_agent"`
+	IpAddress string             `bson:"ip_address"`
 	AccountId primitive.ObjectID `bson:"account_id"`
 	Id        primitive.ObjectID `bson:"_id,omitempty"`
 }
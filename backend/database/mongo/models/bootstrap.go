@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bootstrap marks a one-time setup of the instance. Its fixed id makes the setup happen once
+// even when several requests or instances race for it.
+type Bootstrap struct {
+	CreatedAt time.Time          `bson:"created_at"`
+	Id        string             `bson:"_id"`
+	AccountId primitive.ObjectID `bson:"account_id"`
+}
+
+func (m *Bootstrap) CollectionName() string {
+	return "bootstraps"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cluster owns the connection shared by the databases referencing it. Tls and Auth are
+// encrypted as those of a database.
+type Cluster struct {
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	Tls         *DatabaseTls       `bson:"tls,omitempty"`
+	Auth        *DatabaseAuth      `bson:"auth,omitempty"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description"`
+	Uri         string             `bson:"uri"`
+	Id          primitive.ObjectID `bson:"_id,omitempty"`
+}
+
+func (m *Cluster) CollectionName() string {
+	return "clusters"
+}
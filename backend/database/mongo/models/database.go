@@ -6,14 +6,39 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Database is a namespace on a target cluster. When ClusterId is set the connection, Uri, Tls
+// and Auth, belongs to the cluster and is filled in when the database is read.
 type Database struct {
-	CreatedAt   time.Time          `bson:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at"`
-	Name        string             `bson:"name"`
-	Description string             `bson:"description"`
-	Uri         string             `bson:"uri"`
-	DBName      string             `bson:"db_name"`
-	Id          primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at"`
+	Tls         *DatabaseTls        `bson:"tls,omitempty"`
+	Auth        *DatabaseAuth       `bson:"auth,omitempty"`
+	ClusterId   *primitive.ObjectID `bson:"cluster_id,omitempty"`
+	Name        string              `bson:"name"`
+	Description string              `bson:"description"`
+	Uri         string              `bson:"uri"`
+	DBName      string              `bson:"db_name"`
+	Id          primitive.ObjectID  `bson:"_id,omitempty"`
+}
+
+// DatabaseTls holds PEM encoded material, every field but the flags is encrypted
+// with DATA_ENCRYPTION_KEY.
+type DatabaseTls struct {
+	CaCertificate        string `bson:"ca_certificate,omitempty"`
+	ClientCertificate    string `bson:"client_certificate,omitempty"`
+	ClientKey            string `bson:"client_key,omitempty"`
+	IsEnabled            bool   `bson:"is_enabled"`
+	IsInsecureSkipVerify bool   `bson:"is_insecure_skip_verify"`
+}
+
+// DatabaseAuth overrides the credentials of the uri. Password and AwsSessionToken are
+// encrypted with DATA_ENCRYPTION_KEY.
+type DatabaseAuth struct {
+	Mechanism       string `bson:"mechanism"`
+	Source          string `bson:"source,omitempty"`
+	Username        string `bson:"username,omitempty"`
+	Password        string `bson:"password,omitempty"`
+	AwsSessionToken string `bson:"aws_session_token,omitempty"`
 }
 
 func (m *Database) CollectionName() string {
@@ -1,11 +1,11 @@
 package models
 
 import (
-	"fmt"
-	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/utilities/indexspec"
 )
 
 const (
@@ -35,88 +35,22 @@ type Index struct {
 	Id           primitive.ObjectID `bson:"_id,omitempty"`
 }
 
-type Collation struct {
-	Strength        *int   `bson:"strength,omitempty" json:"strength,omitempty"`
-	CaseLevel       *bool  `bson:"case_level,omitempty" json:"case_level,omitempty"`
-	NumericOrdering *bool  `bson:"numeric_ordering,omitempty" json:"numeric_ordering,omitempty"`
-	Locale          string `bson:"locale" json:"locale"`
-	CaseFirst       string `bson:"case_first,omitempty" json:"case_first,omitempty"`
-}
+type (
+	Collation   = indexspec.Collation
+	IndexOption = indexspec.Option
+	IndexKey    = indexspec.Key
+)
 
-type IndexOption struct {
-	ExpireAfterSeconds *int32                 `bson:"expire_after_seconds"`
-	Collation          *Collation             `bson:"collation,omitempty"`
-	Weights            map[string]interface{} `bson:"weights,omitempty"`
-	DefaultLanguage    string                 `bson:"default_language,omitempty"`
-	IsUnique           bool                   `bson:"is_unique"`
+func IsTextIndex(keys []IndexKey) bool {
+	return indexspec.IsText(keys)
 }
 
-type IndexKey struct {
-	Value interface{} `bson:"value"`
-	Field string      `bson:"field"`
-}
-
-func IsTextIndex(keys []IndexKey) bool {
-	for _, key := range keys {
-		if v, ok := key.Value.(string); ok && v == "text" {
-			return true
-		}
-	}
-	return false
+func (m *Index) Spec() indexspec.Spec {
+	return indexspec.Spec{Options: m.Options, Keys: m.Keys}
 }
 
 func (m *Index) GetKeySignature() string {
-	if len(m.Keys) == 0 {
-		return ""
-	}
-	var keyString string
-	if !m.IsText {
-		for _, key := range m.Keys {
-			if key.Field == "_fts" || key.Field == "_ftsx" {
-				continue
-			}
-			switch v := key.Value.(type) {
-			case int32:
-				keyString += fmt.Sprintf("%s_%d_", key.Field, v)
-			case string:
-				if v == "text" {
-					keyString += fmt.Sprintf("%s_text_", key.Field)
-				} else {
-					keyString += fmt.Sprintf("%s_%s_", key.Field, v)
-				}
-			default:
-				keyString += fmt.Sprintf("%s_%v_", key.Field, v)
-			}
-		}
-	} else {
-		keyString += "text_"
-		if m.Options.DefaultLanguage != "" {
-			keyString += fmt.Sprintf("default_language_%s_", m.Options.DefaultLanguage)
-		}
-		if len(m.Options.Weights) > 0 {
-			weightKeys := make([]string, 0, len(m.Options.Weights))
-			for k := range m.Options.Weights {
-				weightKeys = append(weightKeys, k)
-			}
-			sort.Strings(weightKeys)
-			for _, k := range weightKeys {
-				keyString += fmt.Sprintf("weights_%s_%v_", k, m.Options.Weights[k])
-			}
-		}
-	}
-	if m.Options.Collation != nil && m.Options.Collation.Locale != "" {
-		keyString += fmt.Sprintf("collation_locale_%s_", m.Options.Collation.Locale)
-		if m.Options.Collation.Strength != nil {
-			keyString += fmt.Sprintf("strength_%d_", *m.Options.Collation.Strength)
-		}
-	}
-	if m.Options.IsUnique {
-		keyString += "unique_"
-	}
-	if m.Options.ExpireAfterSeconds != nil {
-		keyString += fmt.Sprintf("expireAfterSeconds_%d", *m.Options.ExpireAfterSeconds)
-	}
-	return keyString
+	return m.Spec().Signature()
 }
 
 func (m *Index) CollectionName() string {
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Invitation struct {
+	CreatedAt time.Time           `bson:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at"`
+	ExpiredAt time.Time           `bson:"expired_at"`
+	UsedAt    *time.Time          `bson:"used_at,omitempty"`
+	UsedBy    *primitive.ObjectID `bson:"used_by,omitempty"`
+	Email     string              `bson:"email"`
+	TokenHash string              `bson:"token_hash"`
+	CreatedBy primitive.ObjectID  `bson:"created_by"`
+	Id        primitive.ObjectID  `bson:"_id,omitempty"`
+}
+
+func (m *Invitation) CollectionName() string {
+	return "invitations"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LoginAttempt struct {
+	CreatedAt time.Time           `bson:"created_at"`
+	AccountId *primitive.ObjectID `bson:"account_id,omitempty"`
+	Identity  string              `bson:"identity"`
+	IpAddress string              `bson:"ip_address"`
+	UserAgent string              `bson:"user_agent"`
+	Reason    string              `bson:"reason"`
+	Id        primitive.ObjectID  `bson:"_id,omitempty"`
+	IsSuccess bool                `bson:"is_success"`
+}
+
+func (m *LoginAttempt) CollectionName() string {
+	return "login_attempts"
+}
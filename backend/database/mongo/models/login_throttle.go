@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginThrottle counts recent failed logins for one key, an account or a client IP.
+type LoginThrottle struct {
+	UpdatedAt    time.Time          `bson:"updated_at"`
+	LastFailedAt time.Time          `bson:"last_failed_at"`
+	ExpiredAt    time.Time          `bson:"expired_at"`
+	LockedUntil  *time.Time         `bson:"locked_until,omitempty"`
+	Key          string             `bson:"key"`
+	Failures     int                `bson:"failures"`
+	Id           primitive.ObjectID `bson:"_id,omitempty"`
+}
+
+func (m *LoginThrottle) CollectionName() string {
+	return "login_throttles"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OidcState keeps the per-login secrets of a single sign-on flow between the
+// authorization redirect and the callback.
+type OidcState struct {
+	CreatedAt    time.Time          `bson:"created_at"`
+	ExpiredAt    time.Time          `bson:"expired_at"`
+	StateHash    string             `bson:"state_hash"`
+	Nonce        string             `bson:"nonce"`
+	CodeVerifier string             `bson:"code_verifier"`
+	Id           primitive.ObjectID `bson:"_id,omitempty"`
+}
+
+func (m *OidcState) CollectionName() string {
+	return "oidc_states"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QueryShape is a representative query of a collection, replayed with explain to check the
+// plans the indexes give it. Filter, Sort and Projection are relaxed extended JSON documents,
+// kept as text since a sort depends on key order and filters hold $ operators.
+type QueryShape struct {
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+	Collection string             `bson:"collection"`
+	Name       string             `bson:"name"`
+	Filter     string             `bson:"filter"`
+	Sort       string             `bson:"sort"`
+	Projection string             `bson:"projection"`
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	DatabaseId primitive.ObjectID `bson:"database_id"`
+}
+
+func (m *QueryShape) CollectionName() string {
+	return "query_shapes"
+}
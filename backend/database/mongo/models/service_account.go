@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ServiceAccount struct {
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description"`
+	CreatedBy   primitive.ObjectID `bson:"created_by"`
+	Id          primitive.ObjectID `bson:"_id,omitempty"`
+}
+
+func (m *ServiceAccount) CollectionName() string {
+	return "service_accounts"
+}
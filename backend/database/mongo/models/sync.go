@@ -6,18 +6,48 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Sync is a run of the sync job. In the hide removal mode the redundant indexes are hidden
+// rather than dropped, RemovalStatus then follows them until GracePeriodEndsAt, when they are
+// dropped unless the sync was rolled back.
 type Sync struct {
-	CreatedAt   time.Time          `bson:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at"`
-	StartedAt   time.Time          `bson:"started_at"`
-	CompletedAt *time.Time         `bson:"completed_at,omitempty"`
-	Error       string             `bson:"error"`
-	Status      string             `bson:"status"`
-	Collections []string           `bson:"collections"`
-	Progress    int                `bson:"progress"`
-	Id          primitive.ObjectID `bson:"_id,omitempty"`
-	DatabaseID  primitive.ObjectID `bson:"database_id"`
-	IsFinished  bool               `bson:"is_finished"`
+	CreatedAt         time.Time          `bson:"created_at"`
+	UpdatedAt         time.Time          `bson:"updated_at"`
+	StartedAt         time.Time          `bson:"started_at"`
+	CompletedAt       *time.Time         `bson:"completed_at,omitempty"`
+	GracePeriodEndsAt *time.Time         `bson:"grace_period_ends_at,omitempty"`
+	Error             string             `bson:"error"`
+	Status            string             `bson:"status"`
+	RemovalMode       string             `bson:"removal_mode,omitempty"`
+	RemovalStatus     string             `bson:"removal_status,omitempty"`
+	RemovalError      string             `bson:"removal_error,omitempty"`
+	Collections       []string           `bson:"collections"`
+	IndexBuilds       []SyncIndexBuild   `bson:"index_builds"`
+	HiddenIndexes     []SyncHiddenIndex  `bson:"hidden_indexes,omitempty"`
+	Progress          int                `bson:"progress"`
+	Id                primitive.ObjectID `bson:"_id,omitempty"`
+	DatabaseID        primitive.ObjectID `bson:"database_id"`
+	IsFinished        bool               `bson:"is_finished"`
+}
+
+// SyncHiddenIndex is a redundant index the sync hid instead of dropping it.
+type SyncHiddenIndex struct {
+	Collection   string `bson:"collection"`
+	Name         string `bson:"name"`
+	KeySignature string `bson:"key_signature"`
+}
+
+// SyncIndexBuild is the progress of an index build seen on the target while the sync creates
+// indexes, as reported by currentOp.
+type SyncIndexBuild struct {
+	UpdatedAt   time.Time `bson:"updated_at"`
+	Collection  string    `bson:"collection"`
+	Host        string    `bson:"host"`
+	Shard       string    `bson:"shard,omitempty"`
+	Phase       string    `bson:"phase"`
+	Indexes     []string  `bson:"indexes"`
+	Done        int64     `bson:"done"`
+	Total       int64     `bson:"total"`
+	SecsRunning int64     `bson:"secs_running"`
 }
 
 func (m *Sync) CollectionName() string {
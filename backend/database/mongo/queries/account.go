@@ -3,6 +3,7 @@ package queries
 import (
 	"context"
 	"errors"
+	"regexp"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -20,6 +21,14 @@ import (
 type AccountQuery interface {
 	GetByUsernameOrEmail(username, email string, opts ...OptionsQuery) (account *models.Account, err error)
 	GetById(id primitive.ObjectID, opts ...OptionsQuery) (account *models.Account, err error)
+	GetByEmail(email string, opts ...OptionsQuery) (account *models.Account, err error)
+	GetByOidcIdentity(issuer, subject string, opts ...OptionsQuery) (account *models.Account, err error)
+	UpdateOidcIdentityById(id primitive.ObjectID, identity AccountUpdateOidcIdentityByIdRequest) error
+	GetTotal() (total int64, err error)
+	GetByQuery(query string, opts ...OptionsQuery) (accounts []models.Account, err error)
+	GetTotalByQuery(query string) (total int64, err error)
+	UpdateIsDisabledById(id primitive.ObjectID, isDisabled bool) error
+	UpdatePasswordHashById(id primitive.ObjectID, passwordHash string) error
 	CreateOne(account models.Account) (newAccount *models.Account, err error)
 	UpdateProfileById(id primitive.ObjectID, profile AccountUpdateProfileByIdRequest) error
 }
@@ -115,3 +124,172 @@ func (q *accountQuery) UpdateProfileById(id primitive.ObjectID, profile AccountU
 	}
 	return nil
 }
+
+func (q *accountQuery) GetTotal() (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotal").Str("functionInline", "q.collection.CountDocuments").Msg("accountQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+func (q *accountQuery) GetTotalByQuery(query string) (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	filter := bson.M{}
+	if query != "" {
+		regexQuery := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		filter["$or"] = []bson.M{
+			{"username": regexQuery},
+			{"email": regexQuery},
+		}
+	}
+	result, err := q.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotalByQuery").Str("functionInline", "q.collection.CountDocuments").Msg("accountQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+func (q *accountQuery) GetByQuery(query string, opts ...OptionsQuery) ([]models.Account, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Limit:      opt.QueryPaginationLimit(),
+		Skip:       opt.QueryPaginationSkip(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	filter := bson.M{}
+	if query != "" {
+		regexQuery := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		filter["$or"] = []bson.M{
+			{"username": regexQuery},
+			{"email": regexQuery},
+		}
+	}
+	cursor, err := q.collection.Find(ctx, filter, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByQuery").Str("functionInline", "q.collection.Find").Msg("accountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.Account, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByQuery").Str("functionInline", "cursor.All").Msg("accountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *accountQuery) UpdateIsDisabledById(id primitive.ObjectID, isDisabled bool) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"updated_at":  time.Now(),
+			"is_disabled": isDisabled,
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UpdateIsDisabledById").Str("functionInline", "q.collection.UpdateByID").Msg("accountQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
+
+func (q *accountQuery) UpdatePasswordHashById(id primitive.ObjectID, passwordHash string) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"updated_at":    time.Now(),
+			"password_hash": passwordHash,
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UpdatePasswordHashById").Str("functionInline", "q.collection.UpdateByID").Msg("accountQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
+
+func (q *accountQuery) GetByEmail(email string, opts ...OptionsQuery) (*models.Account, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.Account
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"email": email}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Account not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetByEmail").Str("functionInline", "q.collection.FindOne").Msg("accountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *accountQuery) GetByOidcIdentity(issuer, subject string, opts ...OptionsQuery) (*models.Account, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.Account
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{
+		"oidc_issuer":  issuer,
+		"oidc_subject": subject,
+	}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Account not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetByOidcIdentity").Str("functionInline", "q.collection.FindOne").Msg("accountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *accountQuery) UpdateOidcIdentityById(id primitive.ObjectID, identity AccountUpdateOidcIdentityByIdRequest) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	set := bson.M{
+		"updated_at":   time.Now(),
+		"oidc_issuer":  identity.Issuer,
+		"oidc_subject": identity.Subject,
+		"groups":       identity.Groups,
+	}
+	if identity.Role != "" {
+		set["role"] = identity.Role
+	}
+	result, err := q.collection.UpdateByID(ctx, id, bson.M{"$set": set})
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrOidcAccountLinked})
+		}
+		logger.Error().Err(err).Str("function", "UpdateOidcIdentityById").Str("functionInline", "q.collection.UpdateByID").Msg("accountQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type ApiKeyQuery interface {
+	GetById(id primitive.ObjectID, opts ...OptionsQuery) (apiKey *models.ApiKey, err error)
+	GetByPrefix(prefix string, opts ...OptionsQuery) (apiKey *models.ApiKey, err error)
+	GetByServiceAccountId(serviceAccountId primitive.ObjectID, opts ...OptionsQuery) (apiKeys []models.ApiKey, err error)
+	CreateOne(apiKey models.ApiKey) (newApiKey *models.ApiKey, err error)
+	UpdateLastUsedAtById(id primitive.ObjectID, lastUsedAt time.Time) error
+	RevokeByIdAndServiceAccountId(id, serviceAccountId primitive.ObjectID) error
+	DeleteByServiceAccountId(serviceAccountId primitive.ObjectID) error
+}
+
+type apiKeyQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewApiKey(ctx context.Context) ApiKeyQuery {
+	return &apiKeyQuery{
+		collection: mongo.NewUtilityService().GetApiKeyCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *apiKeyQuery) CreateOne(apiKey models.ApiKey) (*models.ApiKey, error) {
+	currentTime := time.Now()
+	apiKey.CreatedAt = currentTime
+	apiKey.UpdatedAt = currentTime
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.InsertOne(ctx, apiKey)
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("apiKeyQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	apiKey.Id = result.InsertedID.(primitive.ObjectID)
+	return &apiKey, nil
+}
+
+func (q *apiKeyQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*models.ApiKey, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.ApiKey
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"_id": id}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Api key not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetById").Str("functionInline", "q.collection.FindOne").Msg("apiKeyQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *apiKeyQuery) GetByPrefix(prefix string, opts ...OptionsQuery) (*models.ApiKey, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.ApiKey
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"prefix": prefix}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Api key not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetByPrefix").Str("functionInline", "q.collection.FindOne").Msg("apiKeyQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *apiKeyQuery) GetByServiceAccountId(serviceAccountId primitive.ObjectID, opts ...OptionsQuery) ([]models.ApiKey, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{"service_account_id": serviceAccountId}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByServiceAccountId").Str("functionInline", "q.collection.Find").Msg("apiKeyQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.ApiKey, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByServiceAccountId").Str("functionInline", "cursor.All").Msg("apiKeyQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *apiKeyQuery) UpdateLastUsedAtById(id primitive.ObjectID, lastUsedAt time.Time) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"last_used_at": lastUsedAt,
+		},
+	}); err != nil {
+		logger.Error().Err(err).Str("function", "UpdateLastUsedAtById").Str("functionInline", "q.collection.UpdateByID").Msg("apiKeyQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (q *apiKeyQuery) RevokeByIdAndServiceAccountId(id, serviceAccountId primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	currentTime := time.Now()
+	result, err := q.collection.UpdateOne(ctx, bson.M{
+		"_id":                id,
+		"service_account_id": serviceAccountId,
+	}, bson.M{
+		"$set": bson.M{
+			"updated_at": currentTime,
+			"revoked_at": currentTime,
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "RevokeByIdAndServiceAccountId").Str("functionInline", "q.collection.UpdateOne").Msg("apiKeyQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
+
+func (q *apiKeyQuery) DeleteByServiceAccountId(serviceAccountId primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.DeleteMany(ctx, bson.M{"service_account_id": serviceAccountId}); err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByServiceAccountId").Str("functionInline", "q.collection.DeleteMany").Msg("apiKeyQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
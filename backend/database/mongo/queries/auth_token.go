@@ -21,6 +21,11 @@ type AuthTokenQuery interface {
 	CreateOne(token models.AuthToken) (newToken *models.AuthToken, err error)
 	DeleteById(id primitive.ObjectID) error
 	GetById(id primitive.ObjectID, opts ...OptionsQuery) (token *models.AuthToken, err error)
+	GetActiveByAccountId(accountId primitive.ObjectID, opts ...OptionsQuery) (tokens []models.AuthToken, err error)
+	DeleteByIdAndAccountId(id, accountId primitive.ObjectID) error
+	DeleteByAccountId(accountId primitive.ObjectID) error
+	DeleteByAccountIdExceptId(accountId, id primitive.ObjectID) error
+	DeleteExpired() (total int64, err error)
 }
 
 type authTokenQuery struct {
@@ -83,3 +88,81 @@ func (q *authTokenQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*
 	}
 	return &token, nil
 }
+
+func (q *authTokenQuery) GetActiveByAccountId(accountId primitive.ObjectID, opts ...OptionsQuery) ([]models.AuthToken, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{
+		"account_id": accountId,
+		"expired_at": bson.M{"$gt": time.Now()},
+	}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetActiveByAccountId").Str("functionInline", "q.collection.Find").Msg("authTokenQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	tokens := make([]models.AuthToken, 0)
+	if err = cursor.All(ctx, &tokens); err != nil {
+		logger.Error().Err(err).Str("function", "GetActiveByAccountId").Str("functionInline", "cursor.All").Msg("authTokenQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return tokens, nil
+}
+
+func (q *authTokenQuery) DeleteByIdAndAccountId(id, accountId primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteOne(ctx, bson.M{
+		"_id":        id,
+		"account_id": accountId,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByIdAndAccountId").Str("functionInline", "q.collection.DeleteOne").Msg("authTokenQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.DeletedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
+
+func (q *authTokenQuery) DeleteByAccountId(accountId primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.DeleteMany(ctx, bson.M{"account_id": accountId}); err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByAccountId").Str("functionInline", "q.collection.DeleteMany").Msg("authTokenQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (q *authTokenQuery) DeleteExpired() (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteMany(ctx, bson.M{"expired_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteExpired").Str("functionInline", "q.collection.DeleteMany").Msg("authTokenQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result.DeletedCount, nil
+}
+
+func (q *authTokenQuery) DeleteByAccountIdExceptId(accountId, id primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.DeleteMany(ctx, bson.M{
+		"account_id": accountId,
+		"_id":        bson.M{"$ne": id},
+	}); err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByAccountIdExceptId").Str("functionInline", "q.collection.DeleteMany").Msg("authTokenQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
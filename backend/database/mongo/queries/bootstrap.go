@@ -0,0 +1,57 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type BootstrapQuery interface {
+	CreateOne(bootstrap models.Bootstrap) (newBootstrap *models.Bootstrap, err error)
+	DeleteById(id string) error
+}
+
+type bootstrapQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewBootstrap(ctx context.Context) BootstrapQuery {
+	return &bootstrapQuery{
+		collection: mongo.NewUtilityService().GetBootstrapCollection(),
+		context:    ctx,
+	}
+}
+
+// CreateOne claims the bootstrap, a conflict meaning it already happened.
+func (q *bootstrapQuery) CreateOne(bootstrap models.Bootstrap) (*models.Bootstrap, error) {
+	bootstrap.CreatedAt = time.Now()
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.InsertOne(ctx, bootstrap); err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("bootstrapQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &bootstrap, nil
+}
+
+func (q *bootstrapQuery) DeleteById(id string) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		logger.Error().Err(err).Str("function", "DeleteById").Str("functionInline", "q.collection.DeleteOne").Msg("bootstrapQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
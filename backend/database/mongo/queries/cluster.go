@@ -0,0 +1,265 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type ClusterQuery interface {
+	GetById(id primitive.ObjectID, opts ...OptionsQuery) (cluster *models.Cluster, err error)
+	GetByName(name string, opts ...OptionsQuery) (cluster *models.Cluster, err error)
+	GetAll(opts ...OptionsQuery) (clusters []models.Cluster, err error)
+	GetByIds(ids []primitive.ObjectID, opts ...OptionsQuery) (clusters []models.Cluster, err error)
+	GetByQuery(query string, opts ...OptionsQuery) (clusters []models.Cluster, err error)
+	GetTotal() (total int64, err error)
+	GetTotalByQuery(query string) (total int64, err error)
+	CreateOne(cluster models.Cluster) (newCluster *models.Cluster, err error)
+	UpdateInfoById(id primitive.ObjectID, request ClusterUpdateInfoByIdRequest) error
+	DeleteById(id primitive.ObjectID) error
+}
+
+type clusterQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewCluster(ctx context.Context) ClusterQuery {
+	return &clusterQuery{
+		collection: mongo.NewUtilityService().GetClusterCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *clusterQuery) CreateOne(cluster models.Cluster) (*models.Cluster, error) {
+	currentTime := time.Now()
+	cluster.CreatedAt = currentTime
+	cluster.UpdatedAt = currentTime
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.InsertOne(ctx, cluster)
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	cluster.Id = result.InsertedID.(primitive.ObjectID)
+	return &cluster, nil
+}
+
+func (q *clusterQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*models.Cluster, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.Cluster
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"_id": id}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Cluster not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetById").Str("functionInline", "q.collection.FindOne").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *clusterQuery) GetByName(name string, opts ...OptionsQuery) (*models.Cluster, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.Cluster
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"name": name}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Cluster not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetByName").Str("functionInline", "q.collection.FindOne").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *clusterQuery) GetTotalByQuery(query string) (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	regexQuery := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+	filter := bson.M{}
+	if query != "" {
+		filter["$or"] = []bson.M{
+			{"name": regexQuery},
+			{"description": regexQuery},
+			{"uri": regexQuery},
+		}
+	}
+	result, err := q.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotalByQuery").Str("functionInline", "q.collection.CountDocuments").Msg("clusterQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+func (q *clusterQuery) GetByQuery(query string, opts ...OptionsQuery) ([]models.Cluster, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Limit:      opt.QueryPaginationLimit(),
+		Skip:       opt.QueryPaginationSkip(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	filter := bson.M{}
+	regexQuery := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+	if query != "" {
+		filter["$or"] = []bson.M{
+			{"name": regexQuery},
+			{"description": regexQuery},
+			{"uri": regexQuery},
+		}
+	}
+	cursor, err := q.collection.Find(ctx, filter, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByQuery").Str("functionInline", "q.collection.Find").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.Cluster, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByQuery").Str("functionInline", "cursor.All").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *clusterQuery) GetByIds(ids []primitive.ObjectID, opts ...OptionsQuery) ([]models.Cluster, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByIds").Str("functionInline", "q.collection.Find").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.Cluster, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByIds").Str("functionInline", "cursor.All").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *clusterQuery) UpdateInfoById(id primitive.ObjectID, request ClusterUpdateInfoByIdRequest) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	set := bson.M{
+		"updated_at":  time.Now(),
+		"name":        request.Name,
+		"uri":         request.Uri,
+		"description": request.Description,
+	}
+	unset := bson.M{}
+	if request.Tls != nil {
+		set["tls"] = request.Tls
+	} else {
+		unset["tls"] = ""
+	}
+	if request.Auth != nil {
+		set["auth"] = request.Auth
+	} else {
+		unset["auth"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := q.collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "UpdateInfoById").Str("functionInline", "q.collection.UpdateByID").Msg("clusterQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
+
+func (q *clusterQuery) GetTotal() (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotal").Str("functionInline", "q.collection.CountDocuments").Msg("clusterQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+func (q *clusterQuery) GetAll(opts ...OptionsQuery) ([]models.Cluster, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Limit:      opt.QueryPaginationLimit(),
+		Skip:       opt.QueryPaginationSkip(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetAll").Str("functionInline", "q.collection.Find").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.Cluster, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetAll").Str("functionInline", "cursor.All").Msg("clusterQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *clusterQuery) DeleteById(id primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteById").Str("functionInline", "q.collection.DeleteOne").Msg("clusterQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.DeletedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
@@ -23,6 +23,8 @@ type DatabaseQuery interface {
 	GetByName(name string, opts ...OptionsQuery) (database *models.Database, err error)
 	GetAll(opts ...OptionsQuery) (databases []models.Database, err error)
 	GetByQuery(query string, opts ...OptionsQuery) (databases []models.Database, err error)
+	GetByClusterId(clusterId primitive.ObjectID, opts ...OptionsQuery) (databases []models.Database, err error)
+	GetTotalByClusterId(clusterId primitive.ObjectID) (total int64, err error)
 	GetTotal() (total int64, err error)
 	GetTotalByQuery(query string) (total int64, err error)
 	CreateOne(database models.Database) (newDatabase *models.Database, err error)
@@ -60,13 +62,15 @@ func (q *databaseQuery) CreateOne(database models.Database) (*models.Database, e
 	return &database, nil
 }
 
+// GetById fills in the connection of a database referencing a cluster whenever the uri is part
+// of the projection.
 func (q *databaseQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*models.Database, error) {
 	opt := NewOptions()
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 	var data models.Database
-	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	optFind := &options.FindOneOptions{Projection: databaseProjection(opt)}
 	ctx, cancel := timeoutFunc(q.context)
 	defer cancel()
 	if err := q.collection.FindOne(ctx, bson.M{"_id": id}, optFind).Decode(&data); err != nil {
@@ -76,6 +80,17 @@ func (q *databaseQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*m
 		logger.Error().Err(err).Str("function", "GetById").Str("functionInline", "q.collection.FindOne").Msg("databaseQuery")
 		return nil, response.NewError(fiber.StatusInternalServerError)
 	}
+	if data.ClusterId != nil {
+		clusterOption := NewOptions()
+		clusterOption.SetOnlyFields("uri", "tls", "auth")
+		cluster, err := NewCluster(q.context).GetById(*data.ClusterId, clusterOption)
+		if err != nil {
+			return nil, err
+		}
+		data.Uri = cluster.Uri
+		data.Tls = cluster.Tls
+		data.Auth = cluster.Auth
+	}
 	return &data, nil
 }
 
@@ -125,7 +140,7 @@ func (q *databaseQuery) GetByQuery(query string, opts ...OptionsQuery) ([]models
 		opt = opts[0]
 	}
 	optFind := &options.FindOptions{
-		Projection: opt.QueryOnlyField(),
+		Projection: databaseProjection(opt),
 		Limit:      opt.QueryPaginationLimit(),
 		Skip:       opt.QueryPaginationSkip(),
 		Sort:       opt.QuerySort(),
@@ -152,21 +167,78 @@ func (q *databaseQuery) GetByQuery(query string, opts ...OptionsQuery) ([]models
 		logger.Error().Err(err).Str("function", "GetByQuery").Str("functionInline", "cursor.All").Msg("databaseQuery")
 		return nil, response.NewError(fiber.StatusInternalServerError)
 	}
+	if err = q.fillClusterConnections(data); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
+func (q *databaseQuery) GetByClusterId(clusterId primitive.ObjectID, opts ...OptionsQuery) ([]models.Database, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{"cluster_id": clusterId}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByClusterId").Str("functionInline", "q.collection.Find").Msg("databaseQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.Database, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByClusterId").Str("functionInline", "cursor.All").Msg("databaseQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *databaseQuery) GetTotalByClusterId(clusterId primitive.ObjectID) (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.CountDocuments(ctx, bson.M{"cluster_id": clusterId})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotalByClusterId").Str("functionInline", "q.collection.CountDocuments").Msg("databaseQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
 func (q *databaseQuery) UpdateInfoById(id primitive.ObjectID, request DatabaseUpdateInfoByIdRequest) error {
 	ctx, cancel := timeoutFunc(q.context)
 	defer cancel()
-	result, err := q.collection.UpdateByID(ctx, id, bson.M{
-		"$set": bson.M{
-			"updated_at":  time.Now(),
-			"name":        request.Name,
-			"uri":         request.Uri,
-			"db_name":     request.DBName,
-			"description": request.Description,
-		},
-	})
+	set := bson.M{
+		"updated_at":  time.Now(),
+		"name":        request.Name,
+		"uri":         request.Uri,
+		"db_name":     request.DBName,
+		"description": request.Description,
+	}
+	unset := bson.M{}
+	if request.Tls != nil {
+		set["tls"] = request.Tls
+	} else {
+		unset["tls"] = ""
+	}
+	if request.Auth != nil {
+		set["auth"] = request.Auth
+	} else {
+		unset["auth"] = ""
+	}
+	if request.ClusterId != nil {
+		set["cluster_id"] = request.ClusterId
+	} else {
+		unset["cluster_id"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := q.collection.UpdateByID(ctx, id, update)
 	if err != nil {
 		if mongoDriver.IsDuplicateKeyError(err) {
 			return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
@@ -197,7 +269,7 @@ func (q *databaseQuery) GetAll(opts ...OptionsQuery) ([]models.Database, error)
 		opt = opts[0]
 	}
 	optFind := &options.FindOptions{
-		Projection: opt.QueryOnlyField(),
+		Projection: databaseProjection(opt),
 		Limit:      opt.QueryPaginationLimit(),
 		Skip:       opt.QueryPaginationSkip(),
 		Sort:       opt.QuerySort(),
@@ -214,6 +286,9 @@ func (q *databaseQuery) GetAll(opts ...OptionsQuery) ([]models.Database, error)
 		logger.Error().Err(err).Str("function", "GetAll").Str("functionInline", "cursor.All").Msg("databaseQuery")
 		return nil, response.NewError(fiber.StatusInternalServerError)
 	}
+	if err = q.fillClusterConnections(data); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
@@ -230,3 +305,57 @@ func (q *databaseQuery) DeleteById(id primitive.ObjectID) error {
 	}
 	return nil
 }
+
+// databaseProjection returns the projection of the option, adding cluster_id to a copy of it
+// whenever the uri is projected so that the connection of a cluster can be filled in.
+func databaseProjection(opt OptionsQuery) interface{} {
+	projection := opt.QueryOnlyField()
+	fields, ok := projection.(bson.M)
+	if !ok {
+		return projection
+	}
+	if _, exists := fields["uri"]; !exists {
+		return projection
+	}
+	copied := make(bson.M, len(fields)+1)
+	for key, value := range fields {
+		copied[key] = value
+	}
+	copied["cluster_id"] = 1
+	return copied
+}
+
+// fillClusterConnections sets the uri, TLS and auth settings of the databases referencing a
+// cluster to those of their cluster.
+func (q *databaseQuery) fillClusterConnections(databases []models.Database) error {
+	clusterIds := make([]primitive.ObjectID, 0)
+	for _, database := range databases {
+		if database.ClusterId != nil {
+			clusterIds = append(clusterIds, *database.ClusterId)
+		}
+	}
+	if len(clusterIds) == 0 {
+		return nil
+	}
+	clusterOption := NewOptions()
+	clusterOption.SetOnlyFields("_id", "uri", "tls", "auth")
+	clusters, err := NewCluster(q.context).GetByIds(clusterIds, clusterOption)
+	if err != nil {
+		return err
+	}
+	mapCluster := make(map[primitive.ObjectID]models.Cluster, len(clusters))
+	for _, cluster := range clusters {
+		mapCluster[cluster.Id] = cluster
+	}
+	for i, database := range databases {
+		if database.ClusterId == nil {
+			continue
+		}
+		if cluster, exists := mapCluster[*database.ClusterId]; exists {
+			databases[i].Uri = cluster.Uri
+			databases[i].Tls = cluster.Tls
+			databases[i].Auth = cluster.Auth
+		}
+	}
+	return nil
+}
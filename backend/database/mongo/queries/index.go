@@ -33,6 +33,7 @@ type IndexQuery interface {
 	GetByDatabaseIdCollectionKeyFieldsAndIsUnique(databaseId primitive.ObjectID, collection string, keyFields []string, isUnique bool, opts ...OptionsQuery) (index *models.Index, err error)
 	CreateOne(index models.Index) (newIndex *models.Index, err error)
 	CreateMany(indexes []models.Index) error
+	CreateManyMissing(databaseId primitive.ObjectID, indexes []models.Index) (imported int, skipped int, err error)
 	UpdateNameKeySignatureOptionsKeysById(id primitive.ObjectID, name, keySignature string, indexOpt models.IndexOption, keys []models.IndexKey) error
 	DeleteById(id primitive.ObjectID) error
 	DeleteByDatabaseId(databaseId primitive.ObjectID) error
@@ -42,6 +43,7 @@ type IndexQuery interface {
 	DeleteByDatabaseIdAndCollection(databaseId primitive.ObjectID, collection string) error
 	GetByDatabaseIdCollectionAndName(databaseId primitive.ObjectID, collection, name string, opts ...OptionsQuery) (index *models.Index, err error)
 	GetByDatabaseIdCollectionAndKeySignature(databaseId primitive.ObjectID, collection, keySignature string, opts ...OptionsQuery) (index *models.Index, err error)
+	GetByDatabaseIdCollectionAndIsText(databaseId primitive.ObjectID, collection string, isText bool, opts ...OptionsQuery) (index *models.Index, err error)
 	GetByDatabaseIdCollectionsAndIsDefault(databaseId primitive.ObjectID, collections []string, isDefault bool, opts ...OptionsQuery) (indexes []models.Index, err error)
 	GetByDatabaseIdAndIsDefault(databaseId primitive.ObjectID, isDefault bool, opts ...OptionsQuery) (indexes []models.Index, err error)
 }
@@ -76,6 +78,50 @@ func (q *indexQuery) CreateOne(index models.Index) (*models.Index, error) {
 	return &index, nil
 }
 
+// CreateManyMissing declares the indexes of the database that are not declared yet, by key
+// signature or by name within their collection. An index failing to insert is logged and left
+// out of both counts.
+func (q *indexQuery) CreateManyMissing(databaseId primitive.ObjectID, indexes []models.Index) (int, int, error) {
+	queryOption := NewOptions()
+	queryOption.SetOnlyFields("_id", "key_signature", "collection", "name")
+	existingIndexes, err := q.GetByDatabaseId(databaseId, queryOption)
+	if err != nil {
+		return 0, 0, err
+	}
+	existingIndexMap := make(map[string]struct{})
+	for _, idx := range existingIndexes {
+		existingIndexMap[idx.Collection+":"+idx.KeySignature] = struct{}{}
+		if idx.Name != "" {
+			existingIndexMap[idx.Collection+":"+idx.Name] = struct{}{}
+		}
+	}
+	importedCount := 0
+	skippedCount := 0
+	for _, index := range indexes {
+		keyBySignature := index.Collection + ":" + index.KeySignature
+		keyByName := index.Collection + ":" + index.Name
+		if _, exists := existingIndexMap[keyBySignature]; exists {
+			skippedCount++
+			continue
+		}
+		if _, exists := existingIndexMap[keyByName]; exists {
+			skippedCount++
+			continue
+		}
+		index.DatabaseId = databaseId
+		if _, err = q.CreateOne(index); err != nil {
+			logger.Error().Err(err).Str("function", "CreateManyMissing").Str("functionInline", "q.CreateOne").Str("collection", index.Collection).Str("name", index.Name).Msg("indexQuery")
+			continue
+		}
+		importedCount++
+		existingIndexMap[keyBySignature] = struct{}{}
+		if index.Name != "" {
+			existingIndexMap[keyByName] = struct{}{}
+		}
+	}
+	return importedCount, skippedCount, nil
+}
+
 func (q *indexQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*models.Index, error) {
 	opt := NewOptions()
 	if len(opts) > 0 {
@@ -323,29 +369,30 @@ func (q *indexQuery) GetCollectionsByDatabaseIdAndQuery(databaseId primitive.Obj
 	}
 	pipeline := mongoDriver.Pipeline{
 		{{
-			"$match", matchFilter,
+			Key: "$match", Value: matchFilter,
 		}},
 		{{
-			"$group", bson.M{
+			Key: "$group", Value: bson.M{
 				"_id":           "$collection",
 				"total_indexes": bson.M{"$sum": 1},
 			},
 		}},
-		{{
-			"$sort", opt.QuerySort(),
-		}},
-		{{
-			"$skip", opt.QueryPaginationSkip(),
-		}},
-		{{
-			"$limit", opt.QueryPaginationLimit(),
-		}},
-		{{
-			"$project", bson.M{
-				"total_indexes": "$total_indexes",
-			},
-		}},
 	}
+	if sort := opt.QuerySort(); len(sort) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+	// Without pagination every collection is returned, e.g. to sort them by live size.
+	if limit := opt.QueryPaginationLimit(); limit != nil {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$skip", Value: opt.QueryPaginationSkip()}},
+			bson.D{{Key: "$limit", Value: limit}},
+		)
+	}
+	pipeline = append(pipeline, bson.D{{
+		Key: "$project", Value: bson.M{
+			"total_indexes": "$total_indexes",
+		},
+	}})
 	cursor, err := q.collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		logger.Error().Err(err).Str("function", "GetCollectionsByDatabaseIdAndQuery").Str("functionInline", "q.collection.Find").Msg("indexQuery")
@@ -551,6 +598,29 @@ func (q *indexQuery) GetByDatabaseIdCollectionAndKeySignature(databaseId primiti
 	return &data, nil
 }
 
+func (q *indexQuery) GetByDatabaseIdCollectionAndIsText(databaseId primitive.ObjectID, collection string, isText bool, opts ...OptionsQuery) (*models.Index, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.Index
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{
+		"database_id": databaseId,
+		"collection":  collection,
+		"is_text":     isText,
+	}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Index not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetByDatabaseIdCollectionAndIsText").Str("functionInline", "q.collection.FindOne").Msg("indexQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
 func (q *indexQuery) GetByDatabaseIdCollectionsAndIsDefault(databaseId primitive.ObjectID, collections []string, isDefault bool, opts ...OptionsQuery) ([]models.Index, error) {
 	opt := NewOptions()
 	if len(opts) > 0 {
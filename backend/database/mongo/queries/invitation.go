@@ -0,0 +1,154 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type InvitationQuery interface {
+	CreateOne(invitation models.Invitation) (newInvitation *models.Invitation, err error)
+	GetAll(opts ...OptionsQuery) (invitations []models.Invitation, err error)
+	GetTotal() (total int64, err error)
+	ConsumeByTokenHashAndEmail(tokenHash, email string, usedBy primitive.ObjectID) (invitation *models.Invitation, err error)
+	ReleaseById(id primitive.ObjectID) error
+	DeleteById(id primitive.ObjectID) error
+}
+
+type invitationQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewInvitation(ctx context.Context) InvitationQuery {
+	return &invitationQuery{
+		collection: mongo.NewUtilityService().GetInvitationCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *invitationQuery) CreateOne(invitation models.Invitation) (*models.Invitation, error) {
+	currentTime := time.Now()
+	invitation.CreatedAt = currentTime
+	invitation.UpdatedAt = currentTime
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.InsertOne(ctx, invitation)
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("invitationQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	invitation.Id = result.InsertedID.(primitive.ObjectID)
+	return &invitation, nil
+}
+
+func (q *invitationQuery) GetAll(opts ...OptionsQuery) ([]models.Invitation, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Limit:      opt.QueryPaginationLimit(),
+		Skip:       opt.QueryPaginationSkip(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetAll").Str("functionInline", "q.collection.Find").Msg("invitationQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.Invitation, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetAll").Str("functionInline", "cursor.All").Msg("invitationQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *invitationQuery) GetTotal() (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotal").Str("functionInline", "q.collection.CountDocuments").Msg("invitationQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+// ConsumeByTokenHashAndEmail marks an unused, unexpired invitation as used in a single
+// update, so the same token can never be redeemed twice.
+func (q *invitationQuery) ConsumeByTokenHashAndEmail(tokenHash, email string, usedBy primitive.ObjectID) (*models.Invitation, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	currentTime := time.Now()
+	var data models.Invitation
+	if err := q.collection.FindOneAndUpdate(ctx, bson.M{
+		"token_hash": tokenHash,
+		"used_at":    bson.M{"$exists": false},
+		"expired_at": bson.M{"$gt": currentTime},
+		"email":      bson.M{"$in": bson.A{"", email}},
+	}, bson.M{
+		"$set": bson.M{
+			"updated_at": currentTime,
+			"used_at":    currentTime,
+			"used_by":    usedBy,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusForbidden, response.ErrorOptions{Data: respErr.ErrInvitationInvalid})
+		}
+		logger.Error().Err(err).Str("function", "ConsumeByTokenHashAndEmail").Str("functionInline", "q.collection.FindOneAndUpdate").Msg("invitationQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *invitationQuery) ReleaseById(id primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{
+			"used_at": "",
+			"used_by": "",
+		},
+	}); err != nil {
+		logger.Error().Err(err).Str("function", "ReleaseById").Str("functionInline", "q.collection.UpdateByID").Msg("invitationQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (q *invitationQuery) DeleteById(id primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteById").Str("functionInline", "q.collection.DeleteOne").Msg("invitationQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.DeletedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
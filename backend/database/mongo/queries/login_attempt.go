@@ -0,0 +1,110 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/common/response"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type LoginAttemptQuery interface {
+	CreateOne(attempt models.LoginAttempt) (newAttempt *models.LoginAttempt, err error)
+	GetByFilter(filter LoginAttemptFilterRequest, opts ...OptionsQuery) (attempts []models.LoginAttempt, err error)
+	GetTotalByFilter(filter LoginAttemptFilterRequest) (total int64, err error)
+	DeleteCreatedBefore(createdAt time.Time) (total int64, err error)
+}
+
+type loginAttemptQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewLoginAttempt(ctx context.Context) LoginAttemptQuery {
+	return &loginAttemptQuery{
+		collection: mongo.NewUtilityService().GetLoginAttemptCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *loginAttemptQuery) CreateOne(attempt models.LoginAttempt) (*models.LoginAttempt, error) {
+	attempt.CreatedAt = time.Now()
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.InsertOne(ctx, attempt)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("loginAttemptQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	attempt.Id = result.InsertedID.(primitive.ObjectID)
+	return &attempt, nil
+}
+
+func (q *loginAttemptQuery) buildFilter(filter LoginAttemptFilterRequest) bson.M {
+	query := bson.M{}
+	if filter.AccountId != nil {
+		query["account_id"] = *filter.AccountId
+	}
+	if filter.IpAddress != "" {
+		query["ip_address"] = filter.IpAddress
+	}
+	if filter.IsFailedOnly {
+		query["is_success"] = false
+	}
+	return query
+}
+
+func (q *loginAttemptQuery) GetByFilter(filter LoginAttemptFilterRequest, opts ...OptionsQuery) ([]models.LoginAttempt, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Limit:      opt.QueryPaginationLimit(),
+		Skip:       opt.QueryPaginationSkip(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, q.buildFilter(filter), optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByFilter").Str("functionInline", "q.collection.Find").Msg("loginAttemptQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.LoginAttempt, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByFilter").Str("functionInline", "cursor.All").Msg("loginAttemptQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *loginAttemptQuery) GetTotalByFilter(filter LoginAttemptFilterRequest) (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.CountDocuments(ctx, q.buildFilter(filter))
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotalByFilter").Str("functionInline", "q.collection.CountDocuments").Msg("loginAttemptQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+func (q *loginAttemptQuery) DeleteCreatedBefore(createdAt time.Time) (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteMany(ctx, bson.M{"created_at": bson.M{"$lt": createdAt}})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteCreatedBefore").Str("functionInline", "q.collection.DeleteMany").Msg("loginAttemptQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,138 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/common/response"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type LoginThrottleQuery interface {
+	GetByKeys(keys []string, opts ...OptionsQuery) (throttles []models.LoginThrottle, err error)
+	IncreaseFailuresByKey(key string, request LoginThrottleIncreaseFailuresByKeyRequest) (throttle *models.LoginThrottle, err error)
+	DecreaseFailuresByKey(key string, maxFailures int) error
+	DeleteByKeys(keys ...string) error
+	DeleteExpired() (total int64, err error)
+}
+
+type loginThrottleQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewLoginThrottle(ctx context.Context) LoginThrottleQuery {
+	return &loginThrottleQuery{
+		collection: mongo.NewUtilityService().GetLoginThrottleCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *loginThrottleQuery) GetByKeys(keys []string, opts ...OptionsQuery) ([]models.LoginThrottle, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{"key": bson.M{"$in": keys}}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByKeys").Str("functionInline", "q.collection.Find").Msg("loginThrottleQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.LoginThrottle, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByKeys").Str("functionInline", "cursor.All").Msg("loginThrottleQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+// IncreaseFailuresByKey counts one more failure in a single atomic upsert, so concurrent
+// API instances never lose an increment. The counter restarts once the previous failure
+// falls outside the window, and the key is locked when it reaches the maximum.
+func (q *loginThrottleQuery) IncreaseFailuresByKey(key string, request LoginThrottleIncreaseFailuresByKeyRequest) (*models.LoginThrottle, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	currentTime := time.Now()
+	update := mongoDriver.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"key": key,
+			"failures": bson.M{"$cond": bson.A{
+				bson.M{"$lt": bson.A{"$last_failed_at", currentTime.Add(-request.Window)}},
+				1,
+				bson.M{"$add": bson.A{"$failures", 1}},
+			}},
+			"last_failed_at": currentTime,
+			"updated_at":     currentTime,
+			"expired_at":     currentTime.Add(request.Window + request.Lockout),
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"locked_until": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$failures", request.MaxFailures}},
+				currentTime.Add(request.Lockout),
+				"$locked_until",
+			}},
+		}}},
+	}
+	var data models.LoginThrottle
+	if err := q.collection.FindOneAndUpdate(ctx, bson.M{"key": key}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&data); err != nil {
+		logger.Error().Err(err).Str("function", "IncreaseFailuresByKey").Str("functionInline", "q.collection.FindOneAndUpdate").Msg("loginThrottleQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+// DecreaseFailuresByKey takes back a failure counted for an attempt that succeeded, lifting the
+// lock that attempt set when the counter falls back under the maximum.
+func (q *loginThrottleQuery) DecreaseFailuresByKey(key string, maxFailures int) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	update := mongoDriver.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failures":   bson.M{"$max": bson.A{bson.M{"$subtract": bson.A{"$failures", 1}}, 0}},
+			"updated_at": time.Now(),
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"locked_until": bson.M{"$cond": bson.A{
+				bson.M{"$lt": bson.A{"$failures", maxFailures}},
+				"$$REMOVE",
+				"$locked_until",
+			}},
+		}}},
+	}
+	if _, err := q.collection.UpdateOne(ctx, bson.M{"key": key}, update); err != nil {
+		logger.Error().Err(err).Str("function", "DecreaseFailuresByKey").Str("functionInline", "q.collection.UpdateOne").Msg("loginThrottleQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (q *loginThrottleQuery) DeleteByKeys(keys ...string) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.DeleteMany(ctx, bson.M{"key": bson.M{"$in": keys}}); err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByKeys").Str("functionInline", "q.collection.DeleteMany").Msg("loginThrottleQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (q *loginThrottleQuery) DeleteExpired() (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteMany(ctx, bson.M{"expired_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteExpired").Str("functionInline", "q.collection.DeleteMany").Msg("loginThrottleQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,77 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type OidcStateQuery interface {
+	CreateOne(state models.OidcState) (newState *models.OidcState, err error)
+	ConsumeByStateHash(stateHash string) (state *models.OidcState, err error)
+	DeleteExpired() (total int64, err error)
+}
+
+type oidcStateQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewOidcState(ctx context.Context) OidcStateQuery {
+	return &oidcStateQuery{
+		collection: mongo.NewUtilityService().GetOidcStateCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *oidcStateQuery) CreateOne(state models.OidcState) (*models.OidcState, error) {
+	state.CreatedAt = time.Now()
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.InsertOne(ctx, state)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("oidcStateQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	state.Id = result.InsertedID.(primitive.ObjectID)
+	return &state, nil
+}
+
+// ConsumeByStateHash deletes the state while reading it, so a callback can never be replayed.
+func (q *oidcStateQuery) ConsumeByStateHash(stateHash string) (*models.OidcState, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	var data models.OidcState
+	if err := q.collection.FindOneAndDelete(ctx, bson.M{
+		"state_hash": stateHash,
+		"expired_at": bson.M{"$gt": time.Now()},
+	}).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusBadRequest, response.ErrorOptions{Data: respErr.ErrOidcStateInvalid})
+		}
+		logger.Error().Err(err).Str("function", "ConsumeByStateHash").Str("functionInline", "q.collection.FindOneAndDelete").Msg("oidcStateQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *oidcStateQuery) DeleteExpired() (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteMany(ctx, bson.M{"expired_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteExpired").Str("functionInline", "q.collection.DeleteMany").Msg("oidcStateQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result.DeletedCount, nil
+}
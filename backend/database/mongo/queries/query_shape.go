@@ -0,0 +1,246 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type QueryShapeQuery interface {
+	GetById(id primitive.ObjectID, opts ...OptionsQuery) (queryShape *models.QueryShape, err error)
+	GetByDatabaseIdCollectionAndQuery(databaseId primitive.ObjectID, collection, query string, opts ...OptionsQuery) (queryShapes []models.QueryShape, err error)
+	GetTotalByDatabaseIdCollectionAndQuery(databaseId primitive.ObjectID, collection, query string) (total int64, err error)
+	GetByDatabaseIdAndCollections(databaseId primitive.ObjectID, collections []string, opts ...OptionsQuery) (queryShapes []models.QueryShape, err error)
+	CreateOne(queryShape models.QueryShape) (newQueryShape *models.QueryShape, err error)
+	UpdateInfoById(id primitive.ObjectID, request QueryShapeUpdateInfoByIdRequest) error
+	UpdateCollectionByDatabaseIdAndCollection(databaseId primitive.ObjectID, oldCollection, newCollection string) error
+	DeleteById(id primitive.ObjectID) error
+	DeleteByDatabaseId(databaseId primitive.ObjectID) error
+	DeleteByDatabaseIdAndCollection(databaseId primitive.ObjectID, collection string) error
+}
+
+type queryShapeQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewQueryShape(ctx context.Context) QueryShapeQuery {
+	return &queryShapeQuery{
+		collection: mongo.NewUtilityService().GetQueryShapeCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *queryShapeQuery) CreateOne(queryShape models.QueryShape) (*models.QueryShape, error) {
+	currentTime := time.Now()
+	queryShape.CreatedAt = currentTime
+	queryShape.UpdatedAt = currentTime
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.InsertOne(ctx, queryShape)
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("queryShapeQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	queryShape.Id = result.InsertedID.(primitive.ObjectID)
+	return &queryShape, nil
+}
+
+func (q *queryShapeQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*models.QueryShape, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.QueryShape
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"_id": id}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Query shape not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetById").Str("functionInline", "q.collection.FindOne").Msg("queryShapeQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+// filterByDatabaseIdCollectionAndQuery matches every collection when collection is empty.
+func (q *queryShapeQuery) filterByDatabaseIdCollectionAndQuery(databaseId primitive.ObjectID, collection, query string) bson.M {
+	filter := bson.M{"database_id": databaseId}
+	if collection != "" {
+		filter["collection"] = collection
+	}
+	if query != "" {
+		filter["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+	}
+	return filter
+}
+
+func (q *queryShapeQuery) GetTotalByDatabaseIdCollectionAndQuery(databaseId primitive.ObjectID, collection, query string) (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.CountDocuments(ctx, q.filterByDatabaseIdCollectionAndQuery(databaseId, collection, query))
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotalByDatabaseIdCollectionAndQuery").Str("functionInline", "q.collection.CountDocuments").Msg("queryShapeQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+func (q *queryShapeQuery) GetByDatabaseIdCollectionAndQuery(databaseId primitive.ObjectID, collection, query string, opts ...OptionsQuery) ([]models.QueryShape, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Limit:      opt.QueryPaginationLimit(),
+		Skip:       opt.QueryPaginationSkip(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, q.filterByDatabaseIdCollectionAndQuery(databaseId, collection, query), optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByDatabaseIdCollectionAndQuery").Str("functionInline", "q.collection.Find").Msg("queryShapeQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.QueryShape, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByDatabaseIdCollectionAndQuery").Str("functionInline", "cursor.All").Msg("queryShapeQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+// GetByDatabaseIdAndCollections returns the query shapes of every collection when collections
+// is empty.
+func (q *queryShapeQuery) GetByDatabaseIdAndCollections(databaseId primitive.ObjectID, collections []string, opts ...OptionsQuery) ([]models.QueryShape, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Sort:       bson.D{{Key: "collection", Value: 1}, {Key: "name", Value: 1}},
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	filter := bson.M{"database_id": databaseId}
+	if len(collections) > 0 {
+		filter["collection"] = bson.M{"$in": collections}
+	}
+	cursor, err := q.collection.Find(ctx, filter, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByDatabaseIdAndCollections").Str("functionInline", "q.collection.Find").Msg("queryShapeQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.QueryShape, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByDatabaseIdAndCollections").Str("functionInline", "cursor.All").Msg("queryShapeQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *queryShapeQuery) UpdateInfoById(id primitive.ObjectID, request QueryShapeUpdateInfoByIdRequest) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"updated_at": time.Now(),
+			"name":       request.Name,
+			"filter":     request.Filter,
+			"sort":       request.Sort,
+			"projection": request.Projection,
+		},
+	})
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "UpdateInfoById").Str("functionInline", "q.collection.UpdateByID").Msg("queryShapeQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
+
+func (q *queryShapeQuery) UpdateCollectionByDatabaseIdAndCollection(databaseId primitive.ObjectID, oldCollection, newCollection string) error {
+	if oldCollection == newCollection {
+		return nil
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.UpdateMany(ctx,
+		bson.M{
+			"database_id": databaseId,
+			"collection":  oldCollection,
+		},
+		bson.M{
+			"$set": bson.M{
+				"collection": newCollection,
+				"updated_at": time.Now(),
+			},
+		},
+	); err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "UpdateCollectionByDatabaseIdAndCollection").Str("functionInline", "q.collection.UpdateMany").Msg("queryShapeQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (q *queryShapeQuery) DeleteById(id primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteById").Str("functionInline", "q.collection.DeleteOne").Msg("queryShapeQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.DeletedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
+
+func (q *queryShapeQuery) DeleteByDatabaseId(databaseId primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.DeleteMany(ctx, bson.M{"database_id": databaseId}); err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByDatabaseId").Str("functionInline", "q.collection.DeleteMany").Msg("queryShapeQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (q *queryShapeQuery) DeleteByDatabaseIdAndCollection(databaseId primitive.ObjectID, collection string) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.DeleteMany(ctx, bson.M{"database_id": databaseId, "collection": collection}); err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByDatabaseIdAndCollection").Str("functionInline", "q.collection.DeleteMany").Msg("queryShapeQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
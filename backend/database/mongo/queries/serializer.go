@@ -2,6 +2,10 @@ package queries
 
 import (
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/database/mongo/models"
 )
 
 type AccountUpdateProfileByIdRequest struct {
@@ -11,14 +15,55 @@ type AccountUpdateProfileByIdRequest struct {
 	Avatar    string
 }
 
+// AccountUpdateOidcIdentityByIdRequest leaves the role untouched when Role is empty.
+type AccountUpdateOidcIdentityByIdRequest struct {
+	Issuer  string
+	Subject string
+	Role    string
+	Groups  []string
+}
+
+// DatabaseUpdateInfoByIdRequest removes the TLS or auth settings when Tls or Auth is nil, and
+// the cluster reference when ClusterId is nil.
 type DatabaseUpdateInfoByIdRequest struct {
+	Tls         *models.DatabaseTls
+	Auth        *models.DatabaseAuth
+	ClusterId   *primitive.ObjectID
 	Name        string
 	Description string
 	Uri         string
 	DBName      string
 }
 
+// ClusterUpdateInfoByIdRequest removes the TLS or auth settings when Tls or Auth is nil.
+type ClusterUpdateInfoByIdRequest struct {
+	Tls         *models.DatabaseTls
+	Auth        *models.DatabaseAuth
+	Name        string
+	Description string
+	Uri         string
+}
+
+type QueryShapeUpdateInfoByIdRequest struct {
+	Name       string
+	Filter     string
+	Sort       string
+	Projection string
+}
+
 type IndexGetCollectionsByDatabaseIdAndQueryData struct {
 	Collection   string `bson:"_id"`
 	TotalIndexes int    `bson:"total_indexes"`
 }
+
+type LoginThrottleIncreaseFailuresByKeyRequest struct {
+	Window      time.Duration
+	Lockout     time.Duration
+	MaxFailures int
+}
+
+type LoginAttemptFilterRequest struct {
+	AccountId    *primitive.ObjectID
+	IpAddress    string
+	IsFailedOnly bool
+}
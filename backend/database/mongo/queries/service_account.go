@@ -0,0 +1,185 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/common/response"
+	respErr "doctor-manager-api/common/response/error"
+	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+)
+
+type ServiceAccountQuery interface {
+	GetById(id primitive.ObjectID, opts ...OptionsQuery) (serviceAccount *models.ServiceAccount, err error)
+	GetByIdAndCreatedBy(id primitive.ObjectID, createdBy primitive.ObjectID, opts ...OptionsQuery) (serviceAccount *models.ServiceAccount, err error)
+	GetByName(name string, opts ...OptionsQuery) (serviceAccount *models.ServiceAccount, err error)
+	GetByQueryAndCreatedBy(query string, createdBy primitive.ObjectID, opts ...OptionsQuery) (serviceAccounts []models.ServiceAccount, err error)
+	GetTotalByQueryAndCreatedBy(query string, createdBy primitive.ObjectID) (total int64, err error)
+	CreateOne(serviceAccount models.ServiceAccount) (newServiceAccount *models.ServiceAccount, err error)
+	DeleteByIdAndCreatedBy(id primitive.ObjectID, createdBy primitive.ObjectID) error
+}
+
+type serviceAccountQuery struct {
+	collection *mongoDriver.Collection
+	context    context.Context
+}
+
+func NewServiceAccount(ctx context.Context) ServiceAccountQuery {
+	return &serviceAccountQuery{
+		collection: mongo.NewUtilityService().GetServiceAccountCollection(),
+		context:    ctx,
+	}
+}
+
+func (q *serviceAccountQuery) CreateOne(serviceAccount models.ServiceAccount) (*models.ServiceAccount, error) {
+	currentTime := time.Now()
+	serviceAccount.CreatedAt = currentTime
+	serviceAccount.UpdatedAt = currentTime
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.InsertOne(ctx, serviceAccount)
+	if err != nil {
+		if mongoDriver.IsDuplicateKeyError(err) {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: respErr.ErrResourceConflict})
+		}
+		logger.Error().Err(err).Str("function", "CreateOne").Str("functionInline", "q.collection.InsertOne").Msg("serviceAccountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	serviceAccount.Id = result.InsertedID.(primitive.ObjectID)
+	return &serviceAccount, nil
+}
+
+func (q *serviceAccountQuery) GetById(id primitive.ObjectID, opts ...OptionsQuery) (*models.ServiceAccount, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.ServiceAccount
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"_id": id}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Service account not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetById").Str("functionInline", "q.collection.FindOne").Msg("serviceAccountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+// GetByIdAndCreatedBy returns the service account when it belongs to createdBy, so that users
+// only reach the service accounts they created.
+func (q *serviceAccountQuery) GetByIdAndCreatedBy(id primitive.ObjectID, createdBy primitive.ObjectID, opts ...OptionsQuery) (*models.ServiceAccount, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.ServiceAccount
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"_id": id, "created_by": createdBy}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Service account not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetByIdAndCreatedBy").Str("functionInline", "q.collection.FindOne").Msg("serviceAccountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *serviceAccountQuery) GetByName(name string, opts ...OptionsQuery) (*models.ServiceAccount, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var data models.ServiceAccount
+	optFind := &options.FindOneOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if err := q.collection.FindOne(ctx, bson.M{"name": name}, optFind).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Service account not found"})
+		}
+		logger.Error().Err(err).Str("function", "GetByName").Str("functionInline", "q.collection.FindOne").Msg("serviceAccountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+func (q *serviceAccountQuery) GetTotalByQueryAndCreatedBy(query string, createdBy primitive.ObjectID) (int64, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	filter := bson.M{"created_by": createdBy}
+	if query != "" {
+		regexQuery := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		filter["$or"] = []bson.M{
+			{"name": regexQuery},
+			{"description": regexQuery},
+		}
+	}
+	result, err := q.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetTotalByQueryAndCreatedBy").Str("functionInline", "q.collection.CountDocuments").Msg("serviceAccountQuery")
+		return 0, response.NewError(fiber.StatusInternalServerError)
+	}
+	return result, nil
+}
+
+func (q *serviceAccountQuery) GetByQueryAndCreatedBy(query string, createdBy primitive.ObjectID, opts ...OptionsQuery) ([]models.ServiceAccount, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{
+		Projection: opt.QueryOnlyField(),
+		Limit:      opt.QueryPaginationLimit(),
+		Skip:       opt.QueryPaginationSkip(),
+		Sort:       opt.QuerySort(),
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	filter := bson.M{"created_by": createdBy}
+	if query != "" {
+		regexQuery := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		filter["$or"] = []bson.M{
+			{"name": regexQuery},
+			{"description": regexQuery},
+		}
+	}
+	cursor, err := q.collection.Find(ctx, filter, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetByQueryAndCreatedBy").Str("functionInline", "q.collection.Find").Msg("serviceAccountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	data := make([]models.ServiceAccount, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetByQueryAndCreatedBy").Str("functionInline", "cursor.All").Msg("serviceAccountQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
+func (q *serviceAccountQuery) DeleteByIdAndCreatedBy(id primitive.ObjectID, createdBy primitive.ObjectID) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.DeleteOne(ctx, bson.M{"_id": id, "created_by": createdBy})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "DeleteByIdAndCreatedBy").Str("functionInline", "q.collection.DeleteOne").Msg("serviceAccountQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.DeletedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: respErr.ErrResourceNotFound})
+	}
+	return nil
+}
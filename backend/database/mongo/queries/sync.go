@@ -22,9 +22,16 @@ type SyncQuery interface {
 	GetByDatabaseIdAndIsFinished(databaseId primitive.ObjectID, isFinished bool, opts ...OptionsQuery) (sync *models.Sync, err error)
 	GetById(id primitive.ObjectID, opts ...OptionsQuery) (sync *models.Sync, err error)
 	GetByDatabaseId(databaseId primitive.ObjectID, opts ...OptionsQuery) (syncs []models.Sync, err error)
+	GetPendingRemovalsByDatabaseId(databaseId primitive.ObjectID, opts ...OptionsQuery) (syncs []models.Sync, err error)
 	CreateOne(sync models.Sync) (newIndex *models.Sync, err error)
 	UpdateIsFinishedById(id primitive.ObjectID, isFinished bool) error
 	UpdateStatusById(id primitive.ObjectID, status string, progress int, errorMsg string) error
+	UpdateProgressById(id primitive.ObjectID, progress int, indexBuilds []models.SyncIndexBuild) error
+	UpdateHiddenIndexesById(id primitive.ObjectID, hiddenIndexes []models.SyncHiddenIndex, gracePeriodEndsAt time.Time) error
+	UpdateRemovalStatusById(id primitive.ObjectID, removalStatus string, errorMsg string) error
+	PullPendingHiddenIndexesByDatabaseId(databaseId primitive.ObjectID, hiddenIndexes []models.SyncHiddenIndex) error
+	ClaimExpiredRemoval(staleBefore time.Time) (sync *models.Sync, err error)
+	RollbackRemovalById(id primitive.ObjectID) (sync *models.Sync, err error)
 }
 
 type syncQuery struct {
@@ -126,6 +133,37 @@ func (q *syncQuery) GetByDatabaseId(databaseId primitive.ObjectID, opts ...Optio
 	return data, nil
 }
 
+// GetPendingRemovalsByDatabaseId returns the syncs of the database whose hidden indexes are
+// still to be dropped, failed removals being retried.
+func (q *syncQuery) GetPendingRemovalsByDatabaseId(databaseId primitive.ObjectID, opts ...OptionsQuery) ([]models.Sync, error) {
+	opt := NewOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	optFind := &options.FindOptions{Projection: opt.QueryOnlyField()}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	cursor, err := q.collection.Find(ctx, bson.M{
+		"database_id": databaseId,
+		"removal_status": bson.M{"$in": bson.A{
+			constants.SyncRemovalStatusHidden,
+			constants.SyncRemovalStatusDropping,
+			constants.SyncRemovalStatusFailed,
+		}},
+	}, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "GetPendingRemovalsByDatabaseId").Str("functionInline", "q.collection.Find").Msg("syncQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	defer cursor.Close(ctx)
+	data := make([]models.Sync, 0)
+	if err = cursor.All(ctx, &data); err != nil {
+		logger.Error().Err(err).Str("function", "GetPendingRemovalsByDatabaseId").Str("functionInline", "cursor.All").Msg("syncQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return data, nil
+}
+
 func (q *syncQuery) UpdateIsFinishedById(id primitive.ObjectID, isFinished bool) error {
 	ctx, cancel := timeoutFunc(q.context)
 	defer cancel()
@@ -180,3 +218,167 @@ func (q *syncQuery) UpdateStatusById(id primitive.ObjectID, status string, progr
 	}
 	return nil
 }
+
+func (q *syncQuery) UpdateProgressById(id primitive.ObjectID, progress int, indexBuilds []models.SyncIndexBuild) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if indexBuilds == nil {
+		indexBuilds = []models.SyncIndexBuild{}
+	}
+	result, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"updated_at":   time.Now(),
+			"progress":     progress,
+			"index_builds": indexBuilds,
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UpdateProgressById").Str("functionInline", "q.collection.UpdateByID").Msg("syncQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Sync not found"})
+	}
+	return nil
+}
+
+// UpdateHiddenIndexesById records the indexes the sync hid, to be dropped once the grace period
+// ends.
+func (q *syncQuery) UpdateHiddenIndexesById(id primitive.ObjectID, hiddenIndexes []models.SyncHiddenIndex, gracePeriodEndsAt time.Time) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"updated_at":           time.Now(),
+			"hidden_indexes":       hiddenIndexes,
+			"grace_period_ends_at": gracePeriodEndsAt,
+			"removal_status":       constants.SyncRemovalStatusHidden,
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UpdateHiddenIndexesById").Str("functionInline", "q.collection.UpdateByID").Msg("syncQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Sync not found"})
+	}
+	return nil
+}
+
+func (q *syncQuery) UpdateRemovalStatusById(id primitive.ObjectID, removalStatus string, errorMsg string) error {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	result, err := q.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"updated_at":     time.Now(),
+			"removal_status": removalStatus,
+			"removal_error":  errorMsg,
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "UpdateRemovalStatusById").Str("functionInline", "q.collection.UpdateByID").Msg("syncQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	if result.MatchedCount == 0 {
+		return response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Sync not found"})
+	}
+	return nil
+}
+
+// PullPendingHiddenIndexesByDatabaseId takes the indexes, by collection and name, off the hidden
+// indexes the syncs of the database have yet to drop, so their removal leaves them alone. The
+// update time is kept, it times out the claims and retries of the removals.
+func (q *syncQuery) PullPendingHiddenIndexesByDatabaseId(databaseId primitive.ObjectID, hiddenIndexes []models.SyncHiddenIndex) error {
+	if len(hiddenIndexes) == 0 {
+		return nil
+	}
+	conditions := make(bson.A, len(hiddenIndexes))
+	for i, index := range hiddenIndexes {
+		conditions[i] = bson.M{"collection": index.Collection, "name": index.Name}
+	}
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	if _, err := q.collection.UpdateMany(ctx, bson.M{
+		"database_id": databaseId,
+		"removal_status": bson.M{"$in": bson.A{
+			constants.SyncRemovalStatusHidden,
+			constants.SyncRemovalStatusDropping,
+			constants.SyncRemovalStatusFailed,
+		}},
+	}, bson.M{
+		"$pull": bson.M{"hidden_indexes": bson.M{"$or": conditions}},
+	}); err != nil {
+		logger.Error().Err(err).Str("function", "PullPendingHiddenIndexesByDatabaseId").Str("functionInline", "q.collection.UpdateMany").Msg("syncQuery")
+		return response.NewError(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+// ClaimExpiredRemoval marks as dropping, in a single update, a sync whose grace period ended
+// while its indexes were hidden, so a single instance drops them and a rollback can no longer
+// race the drop. A claim older than staleBefore was left by a stopped instance and is taken
+// over, and a removal that failed before staleBefore is retried, its remaining indexes would
+// otherwise stay hidden.
+func (q *syncQuery) ClaimExpiredRemoval(staleBefore time.Time) (*models.Sync, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	currentTime := time.Now()
+	var data models.Sync
+	if err := q.collection.FindOneAndUpdate(ctx, bson.M{
+		"$or": bson.A{
+			bson.M{
+				"removal_status":       constants.SyncRemovalStatusHidden,
+				"grace_period_ends_at": bson.M{"$lte": currentTime},
+			},
+			bson.M{
+				"removal_status": bson.M{"$in": bson.A{
+					constants.SyncRemovalStatusDropping,
+					constants.SyncRemovalStatusFailed,
+				}},
+				"updated_at": bson.M{"$lte": staleBefore},
+			},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"updated_at":     currentTime,
+			"removal_status": constants.SyncRemovalStatusDropping,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusNotFound, response.ErrorOptions{Data: "Sync not found"})
+		}
+		logger.Error().Err(err).Str("function", "ClaimExpiredRemoval").Str("functionInline", "q.collection.FindOneAndUpdate").Msg("syncQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
+
+// RollbackRemovalById cancels the drop of the indexes the sync hid. A sync already rolled back
+// can be rolled back again, to retry unhiding its indexes, and so can a sync whose removal
+// failed to restore the indexes it left hidden.
+func (q *syncQuery) RollbackRemovalById(id primitive.ObjectID) (*models.Sync, error) {
+	ctx, cancel := timeoutFunc(q.context)
+	defer cancel()
+	var data models.Sync
+	if err := q.collection.FindOneAndUpdate(ctx, bson.M{
+		"_id": id,
+		"removal_status": bson.M{"$in": bson.A{
+			constants.SyncRemovalStatusHidden,
+			constants.SyncRemovalStatusRolledBack,
+			constants.SyncRemovalStatusFailed,
+		}},
+	}, bson.M{
+		"$set": bson.M{
+			"updated_at":     time.Now(),
+			"removal_status": constants.SyncRemovalStatusRolledBack,
+			"removal_error":  "",
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&data); err != nil {
+		if errors.Is(err, mongoDriver.ErrNoDocuments) {
+			return nil, response.NewError(fiber.StatusConflict, response.ErrorOptions{Data: "Sync has no hidden indexes to restore"})
+		}
+		logger.Error().Err(err).Str("function", "RollbackRemovalById").Str("functionInline", "q.collection.FindOneAndUpdate").Msg("syncQuery")
+		return nil, response.NewError(fiber.StatusInternalServerError)
+	}
+	return &data, nil
+}
@@ -13,8 +13,17 @@ type UtilityService interface {
 	GetAccountCollection() (coll *mongo.Collection)
 	GetAuthTokenCollection() (coll *mongo.Collection)
 	GetDatabaseCollection() (coll *mongo.Collection)
+	GetClusterCollection() (coll *mongo.Collection)
 	GetIndexCollection() (coll *mongo.Collection)
 	GetSyncCollection() (coll *mongo.Collection)
+	GetServiceAccountCollection() (coll *mongo.Collection)
+	GetApiKeyCollection() (coll *mongo.Collection)
+	GetInvitationCollection() (coll *mongo.Collection)
+	GetLoginThrottleCollection() (coll *mongo.Collection)
+	GetLoginAttemptCollection() (coll *mongo.Collection)
+	GetOidcStateCollection() (coll *mongo.Collection)
+	GetQueryShapeCollection() (coll *mongo.Collection)
+	GetBootstrapCollection() (coll *mongo.Collection)
 }
 
 type utilityService struct{}
@@ -44,6 +53,10 @@ func (s *utilityService) GetDatabaseCollection() (coll *mongo.Collection) {
 	return s.getManagerDb().Collection(new(models.Database).CollectionName())
 }
 
+func (s *utilityService) GetClusterCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.Cluster).CollectionName())
+}
+
 func (s *utilityService) GetIndexCollection() (coll *mongo.Collection) {
 	return s.getManagerDb().Collection(new(models.Index).CollectionName())
 }
@@ -51,3 +64,35 @@ func (s *utilityService) GetIndexCollection() (coll *mongo.Collection) {
 func (s *utilityService) GetSyncCollection() (coll *mongo.Collection) {
 	return s.getManagerDb().Collection(new(models.Sync).CollectionName())
 }
+
+func (s *utilityService) GetServiceAccountCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.ServiceAccount).CollectionName())
+}
+
+func (s *utilityService) GetApiKeyCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.ApiKey).CollectionName())
+}
+
+func (s *utilityService) GetInvitationCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.Invitation).CollectionName())
+}
+
+func (s *utilityService) GetLoginThrottleCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.LoginThrottle).CollectionName())
+}
+
+func (s *utilityService) GetLoginAttemptCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.LoginAttempt).CollectionName())
+}
+
+func (s *utilityService) GetOidcStateCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.OidcState).CollectionName())
+}
+
+func (s *utilityService) GetQueryShapeCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.QueryShape).CollectionName())
+}
+
+func (s *utilityService) GetBootstrapCollection() (coll *mongo.Collection) {
+	return s.getManagerDb().Collection(new(models.Bootstrap).CollectionName())
+}
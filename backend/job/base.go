@@ -1,11 +1,15 @@
 package job
 
 import (
+	"doctor-manager-api/common/configure"
 	"doctor-manager-api/common/logging"
 	"doctor-manager-api/utilities/taskqueue"
 )
 
-var logger = logging.GetLogger()
+var (
+	cfg    = configure.GetConfig()
+	logger = logging.GetLogger()
+)
 
 func SetupHandler(jobQueue taskqueue.Service) {
 	if jobQueue == nil {
@@ -0,0 +1,82 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"doctor-manager-api/database/mongo/queries"
+)
+
+// Cleanup periodically removes expired records that would otherwise only be
+// purged by a TTL index, which is not created unless MONGO_AUTO_INDEXING is set.
+type Cleanup interface {
+	Start()
+	Stop()
+}
+
+type cleanup struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	interval time.Duration
+}
+
+func NewCleanup(interval time.Duration) Cleanup {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &cleanup{
+		ctx:      ctx,
+		cancel:   cancel,
+		interval: interval,
+	}
+}
+
+func (c *cleanup) Start() {
+	if c.interval <= 0 {
+		return
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		c.run()
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+				c.run()
+			}
+		}
+	}()
+	logger.Info().Dur("interval", c.interval).Msg("cleanup started")
+}
+
+func (c *cleanup) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+func (c *cleanup) run() {
+	if total, err := queries.NewAuthToken(c.ctx).DeleteExpired(); err != nil {
+		logger.Error().Err(err).Str("function", "run").Str("functionInline", "queries.NewAuthToken(c.ctx).DeleteExpired").Msg("job-cleanup")
+	} else if total > 0 {
+		logger.Info().Int64("total", total).Msg("cleanup: expired auth tokens removed")
+	}
+	if total, err := queries.NewLoginThrottle(c.ctx).DeleteExpired(); err != nil {
+		logger.Error().Err(err).Str("function", "run").Str("functionInline", "queries.NewLoginThrottle(c.ctx).DeleteExpired").Msg("job-cleanup")
+	} else if total > 0 {
+		logger.Info().Int64("total", total).Msg("cleanup: expired login throttles removed")
+	}
+	if total, err := queries.NewOidcState(c.ctx).DeleteExpired(); err != nil {
+		logger.Error().Err(err).Str("function", "run").Str("functionInline", "queries.NewOidcState(c.ctx).DeleteExpired").Msg("job-cleanup")
+	} else if total > 0 {
+		logger.Info().Int64("total", total).Msg("cleanup: expired oidc states removed")
+	}
+	if total, err := queries.NewLoginAttempt(c.ctx).DeleteCreatedBefore(time.Now().Add(-cfg.LoginAttemptRetention)); err != nil {
+		logger.Error().Err(err).Str("function", "run").Str("functionInline", "queries.NewLoginAttempt(c.ctx).DeleteCreatedBefore").Msg("job-cleanup")
+	} else if total > 0 {
+		logger.Info().Int64("total", total).Msg("cleanup: old login attempts removed")
+	}
+}
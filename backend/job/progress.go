@@ -0,0 +1,73 @@
+package job
+
+import (
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/mongodb"
+)
+
+// watchIndexBuilds polls currentOp every SYNC_PROGRESS_INTERVAL while the indexes of a
+// collection are built and stores the builds on the sync, with the overall progress given by
+// progress from the fraction of documents scanned. A build goes through several phases, each
+// counting its own documents, so the stored progress never goes back. Polling stops at the
+// first currentOp error, the build itself goes on. stop waits for the last write.
+func watchIndexBuilds(syncQuery queries.SyncQuery, dbClient mongodb.Service, dbName, collection string, syncId primitive.ObjectID, progress func(fraction float64) int) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(cfg.SyncProgressInterval)
+		defer ticker.Stop()
+		lastProgress := 0
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			builds, err := dbClient.GetIndexBuilds(dbName, []string{collection})
+			if err != nil {
+				logger.Warn().Err(err).Str("function", "watchIndexBuilds").Str("functionInline", "dbClient.GetIndexBuilds").Msg("job-handler")
+				return
+			}
+			now := time.Now()
+			indexBuilds := make([]models.SyncIndexBuild, len(builds))
+			var scanned, total int64
+			for i, build := range builds {
+				indexBuilds[i] = models.SyncIndexBuild{
+					UpdatedAt:   now,
+					Collection:  build.Collection,
+					Host:        build.Host,
+					Shard:       build.Shard,
+					Phase:       build.Phase,
+					Indexes:     build.Indexes,
+					Done:        build.Done,
+					Total:       build.Total,
+					SecsRunning: build.SecsRunning,
+				}
+				if build.Total > 0 {
+					scanned += min(build.Done, build.Total)
+					total += build.Total
+				}
+			}
+			fraction := 0.0
+			if total > 0 {
+				fraction = float64(scanned) / float64(total)
+			}
+			lastProgress = max(lastProgress, progress(fraction))
+			if err = syncQuery.UpdateProgressById(syncId, lastProgress, indexBuilds); err != nil {
+				logger.Error().Err(err).Str("function", "watchIndexBuilds").Str("functionInline", "syncQuery.UpdateProgressById").Msg("job-handler")
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,153 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"doctor-manager-api/common/constants"
+	"doctor-manager-api/common/response"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
+	"doctor-manager-api/utilities/mongodb"
+)
+
+// removalClaimTimeout is how long a sync stays claimed for dropping before another instance
+// takes it over, its drops having been interrupted, and how long a failed removal waits before
+// it is retried.
+const removalClaimTimeout = 10 * time.Minute
+
+// Removal periodically drops the indexes syncs hid in the hide removal mode, once their grace
+// period ended without a rollback.
+type Removal interface {
+	Start()
+	Stop()
+}
+
+type removal struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	interval time.Duration
+}
+
+func NewRemoval(interval time.Duration) Removal {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &removal{
+		ctx:      ctx,
+		cancel:   cancel,
+		interval: interval,
+	}
+}
+
+func (r *removal) Start() {
+	if r.interval <= 0 {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		r.run()
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+				r.run()
+			}
+		}
+	}()
+	logger.Info().Dur("interval", r.interval).Msg("removal started")
+}
+
+func (r *removal) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *removal) run() {
+	syncQuery := queries.NewSync(r.ctx)
+	for r.ctx.Err() == nil {
+		syncItem, err := syncQuery.ClaimExpiredRemoval(time.Now().Add(-removalClaimTimeout))
+		if err != nil {
+			if e := new(response.Error); !errors.As(err, &e) || e.Code != fiber.StatusNotFound {
+				logger.Error().Err(err).Str("function", "run").Str("functionInline", "syncQuery.ClaimExpiredRemoval").Msg("job-removal")
+			}
+			return
+		}
+		removalStatus, errorMsg := constants.SyncRemovalStatusDropped, ""
+		if err = r.drop(syncItem); err != nil {
+			logger.Error().Err(err).Str("syncId", syncItem.Id.Hex()).Str("function", "run").Str("functionInline", "r.drop").Msg("job-removal")
+			removalStatus, errorMsg = constants.SyncRemovalStatusFailed, err.Error()
+		}
+		if err = syncQuery.UpdateRemovalStatusById(syncItem.Id, removalStatus, errorMsg); err != nil {
+			logger.Error().Err(err).Str("function", "run").Str("functionInline", "syncQuery.UpdateRemovalStatusById").Msg("job-removal")
+		}
+	}
+}
+
+// drop drops the hidden indexes of the sync that are still hidden with the same keys. An index
+// unhidden or replaced since is left in place, someone decided to keep it, and an index declared
+// again is unhidden.
+func (r *removal) drop(syncItem *models.Sync) error {
+	database, dbClient, releaseClient, err := getDatabaseClient(r.ctx, syncItem.DatabaseID)
+	if err != nil {
+		return err
+	}
+	defer releaseClient()
+	collections := make([]string, 0)
+	for _, index := range syncItem.HiddenIndexes {
+		if !slices.Contains(collections, index.Collection) {
+			collections = append(collections, index.Collection)
+		}
+	}
+	liveIndexes, err := dbClient.GetIndexesByDbNameAndCollections(database.DBName, collections)
+	if err != nil {
+		return err
+	}
+	mapLive := make(map[string]mongodb.Index, len(liveIndexes))
+	for _, index := range liveIndexes {
+		mapLive[index.Collection+"."+index.Name] = index
+	}
+	// The declared indexes are read after the live ones, an index declared in between is kept.
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("key_signature", "collection")
+	declared, err := queries.NewIndex(r.ctx).GetByDatabaseIdAndCollections(syncItem.DatabaseID, collections, queryOption)
+	if err != nil {
+		return err
+	}
+	mapDeclared := make(map[string]struct{}, len(declared))
+	for _, index := range declared {
+		mapDeclared[index.Collection+"."+index.KeySignature] = struct{}{}
+	}
+	indexes := make([]mongodb.Index, 0, len(syncItem.HiddenIndexes))
+	declaredIndexes := make([]mongodb.Index, 0)
+	for _, hidden := range syncItem.HiddenIndexes {
+		index, exists := mapLive[hidden.Collection+"."+hidden.Name]
+		if !exists || !index.IsHidden || index.KeySignature != hidden.KeySignature {
+			logger.Info().Str("collection", hidden.Collection).Str("index", hidden.Name).Str("function", "drop").Msg("keep index no longer hidden")
+			continue
+		}
+		if _, isDeclared := mapDeclared[index.Collection+"."+index.KeySignature]; isDeclared {
+			logger.Info().Str("collection", hidden.Collection).Str("index", hidden.Name).Str("function", "drop").Msg("keep index declared again")
+			declaredIndexes = append(declaredIndexes, index)
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	if len(declaredIndexes) > 0 {
+		unlock := mongodb.GetManager().LockIndexVisibility(syncItem.DatabaseID.Hex())
+		err = dbClient.SetIndexesHidden(database.DBName, declaredIndexes, false)
+		unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return dbClient.RemoveIndexes(database.DBName, indexes)
+}
@@ -2,6 +2,10 @@ package job
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,7 +23,10 @@ type PayloadSyncIndexByCollections struct {
 	Collections   []string           `json:"collections"`
 	ClientIndexes []mongodb.Index    `json:"client_indexes"`
 	ServerIndexes []models.Index     `json:"server_indexes"`
+	DatabaseId    primitive.ObjectID `json:"database_id"`
 	SyncId        primitive.ObjectID `json:"sync_id"`
+	RemovalMode   string             `json:"removal_mode"`
+	GracePeriod   time.Duration      `json:"grace_period"`
 }
 
 func handleSyncIndexByCollection(ctx context.Context, t *taskqueue.Task) error {
@@ -45,6 +52,7 @@ func handleSyncIndexByCollection(ctx context.Context, t *taskqueue.Task) error {
 	var (
 		missingIndexes   = make([]mongodb.Index, 0)
 		redundantIndexes = make([]mongodb.Index, 0)
+		hiddenIndexes    = make([]mongodb.Index, 0)
 	)
 	mapIndexManager := make(map[string]map[string]models.Index)
 	for _, index := range payload.ServerIndexes {
@@ -56,84 +64,28 @@ func handleSyncIndexByCollection(ctx context.Context, t *taskqueue.Task) error {
 
 	for _, collection := range payload.Collections {
 		for _, index := range mapIndexManager[collection] {
-			keys := make([]mongodb.IndexKey, len(index.Keys))
-			for i, key := range index.Keys {
-				keys[i].Field = key.Field
-				value := key.Value
-				switch v := value.(type) {
-				case float64:
-					value = int32(v)
-				case int:
-					value = int32(v)
+			if clientIndex, exists := mapIndexClient[collection][index.KeySignature]; exists {
+				if clientIndex.IsHidden {
+					hiddenIndexes = append(hiddenIndexes, clientIndex)
 				}
-				keys[i].Value = value
-			}
-			var collation *mongodb.Collation
-			if index.Options.Collation != nil {
-				collation = &mongodb.Collation{
-					Locale:          index.Options.Collation.Locale,
-					Strength:        index.Options.Collation.Strength,
-					CaseLevel:       index.Options.Collation.CaseLevel,
-					CaseFirst:       index.Options.Collation.CaseFirst,
-					NumericOrdering: index.Options.Collation.NumericOrdering,
-				}
-			}
-			indexItem := mongodb.Index{
-				Collection: collection,
-				Options: mongodb.IndexOption{
-					ExpireAfterSeconds: index.Options.ExpireAfterSeconds,
-					IsUnique:           index.Options.IsUnique,
-					Collation:          collation,
-					DefaultLanguage:    index.Options.DefaultLanguage,
-					Weights:            index.Options.Weights,
-				},
-				Name: index.Name,
-				Keys: keys,
-			}
-			if _, exists := mapIndexClient[collection][index.KeySignature]; exists {
 				delete(mapIndexClient[collection], index.KeySignature)
 			} else {
-				missingIndexes = append(missingIndexes, indexItem)
+				missingIndexes = append(missingIndexes, mongodb.NewIndexFromModel(index))
 			}
 		}
 		for _, index := range mapIndexClient[collection] {
-			keys := make([]mongodb.IndexKey, len(index.Keys))
-			for i, key := range index.Keys {
-				keys[i].Field = key.Field
-				keys[i].Value = key.Value
-			}
-			var collation *mongodb.Collation
-			if index.Options.Collation != nil {
-				collation = &mongodb.Collation{
-					Locale:          index.Options.Collation.Locale,
-					Strength:        index.Options.Collation.Strength,
-					CaseLevel:       index.Options.Collation.CaseLevel,
-					CaseFirst:       index.Options.Collation.CaseFirst,
-					NumericOrdering: index.Options.Collation.NumericOrdering,
-				}
-			}
-			redundantIndexes = append(redundantIndexes, mongodb.Index{
-				Collection: collection,
-				Options: mongodb.IndexOption{
-					ExpireAfterSeconds: index.Options.ExpireAfterSeconds,
-					IsUnique:           index.Options.IsUnique,
-					Collation:          collation,
-					DefaultLanguage:    index.Options.DefaultLanguage,
-					Weights:            index.Options.Weights,
-				},
-				Name: index.Name,
-				Keys: keys,
-			})
+			redundantIndexes = append(redundantIndexes, index.Normalize())
 		}
 	}
-	dbClient, err := mongodb.New(payload.Uri)
+	dbClient, releaseClient, err := getClient(ctx, payload)
 	if err != nil {
-		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "mongodb.New").Msg("job-controller")
+		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "getClient").Msg("job-controller")
 		if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, 0, err.Error()); updateErr != nil {
 			logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
 		}
 		return err
 	}
+	defer releaseClient()
 	totalCollections := len(payload.Collections)
 	totalOperations := totalCollections * 2 // TODO: Remove and create operations
 	const maxProgress = 100
@@ -144,7 +96,86 @@ func handleSyncIndexByCollection(ctx context.Context, t *taskqueue.Task) error {
 		return int(float64(processed) / float64(total) * maxProgress)
 	}
 	currentProgress := 0
-	if err = dbClient.RemoveIndexes(payload.DBName, redundantIndexes); err != nil {
+	// Indexes the collection type rules out are skipped rather than failing the sync, and the
+	// indexes the server created along with a time-series collection are never dropped.
+	infos, err := dbClient.GetCollectionInfos(payload.DBName, payload.Collections)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "dbClient.GetCollectionInfos").Msg("job-controller")
+		if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, currentProgress, err.Error()); updateErr != nil {
+			logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
+		}
+		return err
+	}
+	mapInfo := make(map[string]mongodb.CollectionInfo, len(infos))
+	for _, info := range infos {
+		mapInfo[info.Name] = info
+	}
+	missingIndexes = slices.DeleteFunc(missingIndexes, func(index mongodb.Index) bool {
+		reason := mapInfo[index.Collection].CheckIndex(index)
+		if reason != "" {
+			logger.Warn().Str("collection", index.Collection).Str("index", index.Name).Str("reason", reason).Str("function", "handleSyncIndexByCollection").Msg("skip index unsupported by the collection")
+		}
+		return reason != ""
+	})
+	redundantIndexes = slices.DeleteFunc(redundantIndexes, func(index mongodb.Index) bool {
+		return mapInfo[index.Collection].IsBuiltinIndex(index)
+	})
+	// Unique indexes over duplicate keys would fail the sync halfway, after the drops.
+	violations, err := dbClient.FindUniqueViolations(payload.DBName, missingIndexes, cfg.DuplicateCheckLimit)
+	if err == nil && len(violations) > 0 {
+		messages := make([]string, len(violations))
+		for i, violation := range violations {
+			messages[i] = violation.String()
+		}
+		err = fmt.Errorf("duplicate keys for unique indexes, nothing was changed: %s", strings.Join(messages, "; "))
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "dbClient.FindUniqueViolations").Msg("job-controller")
+		if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, currentProgress, err.Error()); updateErr != nil {
+			logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
+		}
+		return err
+	}
+	// Indexes supporting a shard key are never dropped, mongos would refuse the last one.
+	shardKeys, err := dbClient.GetShardKeys(payload.DBName, payload.Collections)
+	if err != nil {
+		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "dbClient.GetShardKeys").Msg("job-controller")
+		if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, currentProgress, err.Error()); updateErr != nil {
+			logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
+		}
+		return err
+	}
+	mapShardKeyIndex := make(map[string]struct{})
+	for _, shardKey := range shardKeys {
+		for _, name := range shardKey.IndexNames {
+			mapShardKeyIndex[shardKey.Collection+"."+name] = struct{}{}
+		}
+	}
+	redundantIndexes = slices.DeleteFunc(redundantIndexes, func(index mongodb.Index) bool {
+		_, exists := mapShardKeyIndex[index.Collection+"."+index.Name]
+		if exists {
+			logger.Info().Str("collection", index.Collection).Str("index", index.Name).Str("function", "handleSyncIndexByCollection").Msg("keep index supporting the shard key")
+		}
+		return exists
+	})
+	if err = unhideDeclaredIndexes(syncQuery, dbClient, payload, hiddenIndexes); err != nil {
+		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "unhideDeclaredIndexes").Msg("job-controller")
+		if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, currentProgress, err.Error()); updateErr != nil {
+			logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
+		}
+		return err
+	}
+	if payload.RemovalMode == constants.SyncRemovalModeHide {
+		// A hidden index still holds its name and key pattern, the server would refuse to build
+		// a redefinition of it, so those are dropped right away.
+		conflictingIndexes, otherIndexes := splitConflictingIndexes(redundantIndexes, missingIndexes)
+		if err = dbClient.RemoveIndexes(payload.DBName, conflictingIndexes); err == nil {
+			err = hideIndexes(syncQuery, dbClient, payload, otherIndexes)
+		}
+	} else {
+		err = dbClient.RemoveIndexes(payload.DBName, redundantIndexes)
+	}
+	if err != nil {
 		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "dbClient.RemoveIndexes").Msg("job-controller")
 		if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, currentProgress, err.Error()); updateErr != nil {
 			logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
@@ -155,12 +186,35 @@ func handleSyncIndexByCollection(ctx context.Context, t *taskqueue.Task) error {
 	if err = syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusRunning, currentProgress, ""); err != nil {
 		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
 	}
-	if err = dbClient.CreateIndexes(payload.DBName, missingIndexes); err != nil {
-		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "dbClient.CreateIndexes").Msg("job-controller")
-		if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, currentProgress, err.Error()); updateErr != nil {
-			logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
+	// Indexes are built one collection at a time so progress moves with each collection and,
+	// in between, with the builds reported by currentOp.
+	createCollections := make([]string, 0)
+	mapMissingIndex := make(map[string][]mongodb.Index)
+	for _, index := range missingIndexes {
+		if _, exists := mapMissingIndex[index.Collection]; !exists {
+			createCollections = append(createCollections, index.Collection)
+		}
+		mapMissingIndex[index.Collection] = append(mapMissingIndex[index.Collection], index)
+	}
+	createProgress := currentProgress
+	for i, collection := range createCollections {
+		collectionProgress := func(fraction float64) int {
+			return createProgress + int((float64(i)+fraction)/float64(len(createCollections))*float64(maxProgress-createProgress))
+		}
+		stopWatching := watchIndexBuilds(syncQuery, dbClient, payload.DBName, collection, payload.SyncId, collectionProgress)
+		err = dbClient.CreateIndexes(payload.DBName, mapMissingIndex[collection])
+		stopWatching()
+		if err != nil {
+			logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "dbClient.CreateIndexes").Msg("job-controller")
+			if updateErr := syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusFailed, currentProgress, err.Error()); updateErr != nil {
+				logger.Error().Err(updateErr).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
+			}
+			return err
+		}
+		currentProgress = collectionProgress(1)
+		if err = syncQuery.UpdateProgressById(payload.SyncId, currentProgress, nil); err != nil {
+			logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateProgressById").Msg("job-handler")
 		}
-		return err
 	}
 	if err = syncQuery.UpdateStatusById(payload.SyncId, constants.SyncStatusCompleted, maxProgress, ""); err != nil {
 		logger.Error().Err(err).Str("function", "handleSyncIndexByCollection").Str("functionInline", "syncQuery.UpdateStatusById").Msg("job-handler")
@@ -168,3 +222,101 @@ func handleSyncIndexByCollection(ctx context.Context, t *taskqueue.Task) error {
 	}
 	return nil
 }
+
+// splitConflictingIndexes splits the redundant indexes between those one of the missing
+// indexes conflicts with and the others.
+func splitConflictingIndexes(redundantIndexes, missingIndexes []mongodb.Index) ([]mongodb.Index, []mongodb.Index) {
+	conflicting := make([]mongodb.Index, 0)
+	others := make([]mongodb.Index, 0, len(redundantIndexes))
+	for _, index := range redundantIndexes {
+		if slices.ContainsFunc(missingIndexes, index.ConflictsWith) {
+			conflicting = append(conflicting, index)
+		} else {
+			others = append(others, index)
+		}
+	}
+	return conflicting, others
+}
+
+// hideIndexes hides the redundant indexes instead of dropping them and records them on the
+// sync, the removal job drops them once the grace period ends. Indexes are unhidden again when
+// they cannot be recorded, nothing would ever drop nor restore them. An explain hiding the
+// pending drops is waited for, it would unhide them afterwards.
+func hideIndexes(syncQuery queries.SyncQuery, dbClient mongodb.Service, payload PayloadSyncIndexByCollections, indexes []mongodb.Index) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	unlock := mongodb.GetManager().LockIndexVisibility(payload.DatabaseId.Hex())
+	defer unlock()
+	hiddenIndexes := make([]models.SyncHiddenIndex, len(indexes))
+	for i, index := range indexes {
+		hiddenIndexes[i] = models.SyncHiddenIndex{
+			Collection:   index.Collection,
+			Name:         index.Name,
+			KeySignature: index.GetKeySignature(),
+		}
+	}
+	err := dbClient.SetIndexesHidden(payload.DBName, indexes, true)
+	if err == nil {
+		err = syncQuery.UpdateHiddenIndexesById(payload.SyncId, hiddenIndexes, time.Now().Add(payload.GracePeriod))
+	}
+	if err != nil {
+		if unhideErr := dbClient.SetIndexesHidden(payload.DBName, indexes, false); unhideErr != nil {
+			logger.Error().Err(unhideErr).Str("function", "hideIndexes").Str("functionInline", "dbClient.SetIndexesHidden").Msg("job-handler")
+		}
+		return err
+	}
+	return nil
+}
+
+// unhideDeclaredIndexes makes the live indexes matching a declared index visible again, an
+// earlier sync or someone may have hidden them, and takes them off the pending drops of the
+// earlier syncs.
+func unhideDeclaredIndexes(syncQuery queries.SyncQuery, dbClient mongodb.Service, payload PayloadSyncIndexByCollections, indexes []mongodb.Index) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	unlock := mongodb.GetManager().LockIndexVisibility(payload.DatabaseId.Hex())
+	defer unlock()
+	if err := dbClient.SetIndexesHidden(payload.DBName, indexes, false); err != nil {
+		return err
+	}
+	if payload.DatabaseId.IsZero() {
+		return nil
+	}
+	hiddenIndexes := make([]models.SyncHiddenIndex, len(indexes))
+	for i, index := range indexes {
+		hiddenIndexes[i] = models.SyncHiddenIndex{Collection: index.Collection, Name: index.Name}
+	}
+	return syncQuery.PullPendingHiddenIndexesByDatabaseId(payload.DatabaseId, hiddenIndexes)
+}
+
+// getClient uses the pooled client of the database, with its current TLS and auth
+// settings. Tasks enqueued before payloads carried a database id get a dedicated client.
+func getClient(ctx context.Context, payload PayloadSyncIndexByCollections) (mongodb.Service, func(), error) {
+	if !payload.DatabaseId.IsZero() {
+		_, dbClient, releaseClient, err := getDatabaseClient(ctx, payload.DatabaseId)
+		return dbClient, releaseClient, err
+	}
+	dbClient, err := mongodb.New(payload.Uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dbClient, func() {
+		_ = dbClient.Disconnect()
+	}, nil
+}
+
+func getDatabaseClient(ctx context.Context, databaseId primitive.ObjectID) (*models.Database, mongodb.Service, func(), error) {
+	queryOption := queries.NewOptions()
+	queryOption.SetOnlyFields("uri", "db_name", "tls", "auth")
+	database, err := queries.NewDatabase(ctx).GetById(databaseId, queryOption)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dbClient, releaseClient, err := mongodb.GetManager().GetByDatabase(database)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return database, dbClient, releaseClient, nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,18 +11,27 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	recoverFiber "github.com/gofiber/fiber/v2/middleware/recover"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	_ "go.uber.org/automaxprocs"
 
 	"doctor-manager-api/api/routers"
 	"doctor-manager-api/common/configure"
+	"doctor-manager-api/common/constants"
 	"doctor-manager-api/common/logging"
 	"doctor-manager-api/common/request/validator"
 	"doctor-manager-api/common/response"
 	respErr "doctor-manager-api/common/response/error"
 	"doctor-manager-api/database/mongo"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/database/mongo/queries"
 	"doctor-manager-api/job"
+	"doctor-manager-api/utilities/encryption"
+	"doctor-manager-api/utilities/hasher/password"
 	"doctor-manager-api/utilities/jwt"
+	"doctor-manager-api/utilities/lint"
+	"doctor-manager-api/utilities/mongodb"
+	"doctor-manager-api/utilities/oidc"
 	taskqueue "doctor-manager-api/utilities/taskqueue"
 )
 
@@ -30,13 +41,50 @@ func main() {
 	logging.InitLogger()
 	validator.InitValidateEngine()
 	mongo.InitDatabase()
+	bootstrapAdmin()
 	app := fiber.New(fiber.Config{
 		ErrorHandler: response.FiberErrorHandler,
 		JSONDecoder:  sonic.Unmarshal,
 		JSONEncoder:  sonic.Marshal,
 	})
-	jwt.New(cfg.TokenPrivateKey, cfg.TokenPublicKey).InitGlobal()
+	jwt.New(cfg.TokenPrivateKey, cfg.TokenPublicKey, jwt.ReadVerificationKeys(cfg.TokenVerificationKeysDir)...).InitGlobal()
+	if cfg.OidcEnable {
+		oidc.New(oidc.Option{
+			Issuer:       cfg.OidcIssuer,
+			ClientId:     cfg.OidcClientId,
+			ClientSecret: cfg.OidcClientSecret,
+			RedirectUrl:  cfg.OidcRedirectUrl,
+			GroupsClaim:  cfg.OidcGroupsClaim,
+			Scopes:       cfg.OidcScopes,
+		}).InitGlobal()
+	}
+	if cfg.DataEncryptionKey != "" {
+		encryptionService, err := encryption.New(cfg.DataEncryptionKey)
+		if err != nil {
+			logging.GetLogger().Fatal().Err(err).Str("function", "main").Str("functionInline", "encryption.New").Msg("main")
+		}
+		encryptionService.InitGlobal()
+	}
+	lintService, err := lint.New(lint.Option{
+		Severities: cfg.IndexLintRules,
+		MaxIndexes: cfg.IndexLintMaxIndexes,
+	})
+	if err != nil {
+		logging.GetLogger().Fatal().Err(err).Str("function", "main").Str("functionInline", "lint.New").Msg("main")
+	}
+	lintService.InitGlobal()
+	targetManager := mongodb.NewManager(mongodb.ManagerOption{
+		IdleTimeout:         cfg.TargetIdleTimeout,
+		HealthCheckInterval: cfg.TargetHealthCheckInterval,
+		MaxPoolSize:         cfg.TargetMaxPoolSize,
+	})
+	targetManager.InitGlobal()
+	targetManager.Start()
 	initJobQueue()
+	cleanup := job.NewCleanup(cfg.CleanupInterval)
+	cleanup.Start()
+	removal := job.NewRemoval(cfg.SyncRemovalInterval)
+	removal.Start()
 	addMiddleware(app)
 	addV1Route(app)
 	handleURLNotFound(app)
@@ -53,6 +101,9 @@ func main() {
 	logging.GetLogger().Info().Msg("Shutting down...")
 	_ = app.Shutdown()
 	taskqueue.GetGlobal().Stop()
+	cleanup.Stop()
+	removal.Stop()
+	targetManager.Close()
 	mongo.DisconnectDatabase()
 }
 
@@ -84,6 +135,9 @@ func addV1Route(app *fiber.App) {
 	routers.NewAuth(route).V1()
 	routers.NewDatabase(route).V1()
 	routers.NewIndex(route).V1()
+	routers.NewQueryShape(route).V1()
+	routers.NewServiceAccount(route).V1()
+	routers.NewAdmin(route).V1()
 }
 
 func initJobQueue() {
@@ -98,3 +152,49 @@ func initJobQueue() {
 	job.SetupHandler(serv)
 	go serv.Start()
 }
+
+// bootstrapAdmin creates the ADMIN_USERNAME admin on an instance without accounts, the first
+// admin of an instance whose registration is not open.
+func bootstrapAdmin() {
+	if cfg.AdminUsername == "" {
+		return
+	}
+	if cfg.AdminEmail == "" || cfg.AdminPassword == "" {
+		logging.GetLogger().Fatal().Str("function", "bootstrapAdmin").Msg("ADMIN_EMAIL and ADMIN_PASSWORD are required with ADMIN_USERNAME")
+	}
+	ctx := context.Background()
+	accountQuery := queries.NewAccount(ctx)
+	total, err := accountQuery.GetTotal()
+	if err != nil {
+		logging.GetLogger().Fatal().Err(err).Str("function", "bootstrapAdmin").Str("functionInline", "accountQuery.GetTotal").Msg("main")
+	}
+	if total > 0 {
+		return
+	}
+	passwordHash, err := password.New().Hash(cfg.AdminPassword)
+	if err != nil {
+		logging.GetLogger().Fatal().Err(err).Str("function", "bootstrapAdmin").Str("functionInline", "password.New().Hash").Msg("main")
+	}
+	accountId := primitive.NewObjectID()
+	bootstrapQuery := queries.NewBootstrap(ctx)
+	if _, err = bootstrapQuery.CreateOne(models.Bootstrap{Id: constants.BootstrapIdAdmin, AccountId: accountId}); err != nil {
+		// Another instance or a registration bootstrapped the instance first.
+		if e := new(response.Error); errors.As(err, &e) && e.Code == fiber.StatusConflict {
+			return
+		}
+		logging.GetLogger().Fatal().Err(err).Str("function", "bootstrapAdmin").Str("functionInline", "bootstrapQuery.CreateOne").Msg("main")
+	}
+	if _, err = accountQuery.CreateOne(models.Account{
+		Username:     cfg.AdminUsername,
+		Email:        cfg.AdminEmail,
+		PasswordHash: passwordHash,
+		Role:         constants.AccountRoleAdmin,
+		Id:           accountId,
+	}); err != nil {
+		if releaseErr := bootstrapQuery.DeleteById(constants.BootstrapIdAdmin); releaseErr != nil {
+			logging.GetLogger().Error().Err(releaseErr).Str("function", "bootstrapAdmin").Str("functionInline", "bootstrapQuery.DeleteById").Msg("main")
+		}
+		logging.GetLogger().Fatal().Err(err).Str("function", "bootstrapAdmin").Str("functionInline", "accountQuery.CreateOne").Msg("main")
+	}
+	logging.GetLogger().Info().Str("username", cfg.AdminUsername).Msg("admin account bootstrapped")
+}
@@ -0,0 +1,89 @@
+package advisor
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+
+	"doctor-manager-api/database/mongo/models"
+)
+
+const planSummaryCollScan = "COLLSCAN"
+
+// Field classes of a query shape, ordered by the ESR rule: equality, sort, range.
+const (
+	FieldClassEquality = "eq"
+	FieldClassRange    = "range"
+	FieldClassOther    = "other"
+)
+
+type Service interface {
+	// ParseProfileEntry reads a system.profile document, ok is false for operations
+	// without a filter such as inserts.
+	ParseProfileEntry(entry bson.D) (operation Operation, ok bool)
+	// ParseLogLine reads a "Slow query" line of the structured server log (4.4+).
+	ParseLogLine(line string) (operation Operation, ok bool)
+	// Analyze groups the operations by shape and proposes indexes for the slow shapes,
+	// checked against the declared indexes.
+	Analyze(operations []Operation, indexes []models.Index) (result Result)
+}
+
+type Option struct {
+	// MinExaminedRatio flags shapes examining at least this many keys or documents per
+	// returned document.
+	MinExaminedRatio float64
+}
+
+type Operation struct {
+	Filter       bson.D
+	Sort         bson.D
+	Database     string
+	Collection   string
+	PlanSummary  string
+	KeysExamined int64
+	DocsExamined int64
+	Returned     int64
+	Millis       int64
+}
+
+type Result struct {
+	Shapes    []Shape
+	Proposals []Proposal
+}
+
+// Shape aggregates the operations sharing a collection, filter fields with their class
+// and sort. Values are never kept.
+type Shape struct {
+	PlanSummaries []string
+	Collection    string
+	Shape         string
+	// Reason tells why a slow shape has no proposal.
+	Reason       string
+	Count        int64
+	TotalMillis  int64
+	MaxMillis    int64
+	KeysExamined int64
+	DocsExamined int64
+	Returned     int64
+	CollScans    int64
+	IsSlow       bool
+}
+
+// Proposal is a candidate index in models.Index form. CoveredBy names a declared index
+// that already serves the shapes, which then most likely is not synced to the cluster.
+type Proposal struct {
+	CoveredBy string
+	Shapes    []string
+	Index     models.Index
+}
+
+type service struct {
+	option Option
+}
+
+func New(opt Option) Service {
+	if opt.MinExaminedRatio <= 0 {
+		opt.MinExaminedRatio = 10
+	}
+	return &service{
+		option: opt,
+	}
+}
@@ -0,0 +1,383 @@
+package advisor
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"doctor-manager-api/database/mongo/models"
+)
+
+type shapeField struct {
+	Field string
+	Class string
+}
+
+type shapeSort struct {
+	Field     string
+	Direction int32
+}
+
+type shapeGroup struct {
+	shape         Shape
+	fields        []shapeField
+	sorts         []shapeSort
+	planSummaries map[string]struct{}
+	isUnsupported bool
+}
+
+func (s *service) ParseProfileEntry(entry bson.D) (Operation, bool) {
+	operation := Operation{
+		PlanSummary:  stringValue(entry, "planSummary"),
+		KeysExamined: intValue(entry, "keysExamined"),
+		DocsExamined: intValue(entry, "docsExamined"),
+		Returned:     intValue(entry, "nreturned"),
+		Millis:       intValue(entry, "millis"),
+	}
+	command, _ := lookup(entry, "command").(bson.D)
+	return operation, fillOperation(&operation, stringValue(entry, "ns"), command)
+}
+
+func (s *service) ParseLogLine(line string) (Operation, bool) {
+	var entry bson.D
+	if err := bson.UnmarshalExtJSON([]byte(strings.TrimSpace(line)), false, &entry); err != nil {
+		return Operation{}, false
+	}
+	if stringValue(entry, "msg") != "Slow query" {
+		return Operation{}, false
+	}
+	attr, _ := lookup(entry, "attr").(bson.D)
+	operation := Operation{
+		PlanSummary:  stringValue(attr, "planSummary"),
+		KeysExamined: intValue(attr, "keysExamined"),
+		DocsExamined: intValue(attr, "docsExamined"),
+		Returned:     intValue(attr, "nreturned"),
+		Millis:       intValue(attr, "durationMillis"),
+	}
+	command, _ := lookup(attr, "command").(bson.D)
+	return operation, fillOperation(&operation, stringValue(attr, "ns"), command)
+}
+
+// fillOperation extracts the collection, filter and sort of the usual read and write
+// commands. Profiler and log entries of updates and deletes carry the statement itself.
+func fillOperation(operation *Operation, ns string, command bson.D) bool {
+	if len(command) == 0 {
+		return false
+	}
+	database, collection, _ := strings.Cut(ns, ".")
+	if collection == "" || collection == "$cmd" {
+		collection, _ = command[0].Value.(string)
+	}
+	if collection == "" || strings.HasPrefix(collection, "system.") {
+		return false
+	}
+	operation.Database = database
+	operation.Collection = collection
+	switch command[0].Key {
+	case "find":
+		operation.Filter, _ = lookup(command, "filter").(bson.D)
+		operation.Sort, _ = lookup(command, "sort").(bson.D)
+	case "count", "distinct":
+		operation.Filter, _ = lookup(command, "query").(bson.D)
+	case "findAndModify", "findandmodify":
+		operation.Filter, _ = lookup(command, "query").(bson.D)
+		operation.Sort, _ = lookup(command, "sort").(bson.D)
+	case "aggregate":
+		pipeline, _ := lookup(command, "pipeline").(bson.A)
+		for i, stage := range pipeline {
+			stageDoc, ok := stage.(bson.D)
+			if !ok || len(stageDoc) == 0 {
+				break
+			}
+			if stageDoc[0].Key == "$match" && i == 0 {
+				operation.Filter, _ = stageDoc[0].Value.(bson.D)
+				continue
+			}
+			if stageDoc[0].Key == "$sort" {
+				operation.Sort, _ = stageDoc[0].Value.(bson.D)
+			}
+			break
+		}
+	case "q":
+		operation.Filter, _ = command[0].Value.(bson.D)
+	default:
+		if filter, ok := lookup(command, "q").(bson.D); ok {
+			operation.Filter = filter
+		} else {
+			return false
+		}
+	}
+	return len(operation.Filter) > 0 || len(operation.Sort) > 0
+}
+
+func (s *service) Analyze(operations []Operation, indexes []models.Index) Result {
+	groups := make([]*shapeGroup, 0)
+	mapGroup := make(map[string]*shapeGroup)
+	for _, operation := range operations {
+		fields, isUnsupported := classifyFilter(operation.Filter)
+		sorts := make([]shapeSort, 0, len(operation.Sort))
+		for _, element := range operation.Sort {
+			direction := int32(1)
+			if value, ok := toInt64(element.Value); ok && value < 0 {
+				direction = -1
+			}
+			sorts = append(sorts, shapeSort{Field: element.Key, Direction: direction})
+		}
+		key := shapeKey(operation.Collection, fields, sorts, isUnsupported)
+		group, exists := mapGroup[key]
+		if !exists {
+			group = &shapeGroup{
+				shape: Shape{
+					Collection: operation.Collection,
+					Shape:      key,
+				},
+				fields:        fields,
+				sorts:         sorts,
+				planSummaries: make(map[string]struct{}),
+				isUnsupported: isUnsupported,
+			}
+			mapGroup[key] = group
+			groups = append(groups, group)
+		}
+		group.shape.Count++
+		group.shape.TotalMillis += operation.Millis
+		group.shape.MaxMillis = max(group.shape.MaxMillis, operation.Millis)
+		group.shape.KeysExamined += operation.KeysExamined
+		group.shape.DocsExamined += operation.DocsExamined
+		group.shape.Returned += operation.Returned
+		if strings.HasPrefix(operation.PlanSummary, planSummaryCollScan) {
+			group.shape.CollScans++
+		}
+		if operation.PlanSummary != "" {
+			group.planSummaries[operation.PlanSummary] = struct{}{}
+		}
+	}
+
+	mapIndexByCollection := make(map[string][]models.Index)
+	for _, index := range indexes {
+		mapIndexByCollection[index.Collection] = append(mapIndexByCollection[index.Collection], index)
+	}
+	result := Result{
+		Shapes:    make([]Shape, 0, len(groups)),
+		Proposals: make([]Proposal, 0),
+	}
+	mapProposal := make(map[string]int)
+	for _, group := range groups {
+		for planSummary := range group.planSummaries {
+			group.shape.PlanSummaries = append(group.shape.PlanSummaries, planSummary)
+		}
+		sort.Strings(group.shape.PlanSummaries)
+		examined := max(group.shape.KeysExamined, group.shape.DocsExamined)
+		ratio := float64(examined) / float64(max(group.shape.Returned, 1))
+		group.shape.IsSlow = group.shape.CollScans > 0 || ratio >= s.option.MinExaminedRatio
+		if !group.shape.IsSlow {
+			result.Shapes = append(result.Shapes, group.shape)
+			continue
+		}
+		keys := proposeKeys(group.fields, group.sorts)
+		switch {
+		case group.isUnsupported:
+			group.shape.Reason = "filter uses $or, $nor, $expr, $where or $text"
+		case len(keys) == 0:
+			group.shape.Reason = "no selective field to index"
+		case len(keys) == 1 && keys[0].Field == "_id":
+			group.shape.Reason = "already served by the _id index"
+		}
+		result.Shapes = append(result.Shapes, group.shape)
+		if group.shape.Reason != "" {
+			continue
+		}
+		index := models.Index{
+			Collection: group.shape.Collection,
+			Keys:       keys,
+		}
+		index.KeySignature = index.GetKeySignature()
+		index.Name = index.KeySignature
+		proposalKey := index.Collection + "." + index.KeySignature
+		if i, exists := mapProposal[proposalKey]; exists {
+			result.Proposals[i].Shapes = append(result.Proposals[i].Shapes, group.shape.Shape)
+			continue
+		}
+		proposal := Proposal{
+			Shapes: []string{group.shape.Shape},
+			Index:  index,
+		}
+		for _, declared := range mapIndexByCollection[index.Collection] {
+			if isPrefix(keys, declared.Keys) {
+				proposal.CoveredBy = declared.Name
+				break
+			}
+		}
+		mapProposal[proposalKey] = len(result.Proposals)
+		result.Proposals = append(result.Proposals, proposal)
+	}
+	return result
+}
+
+// classifyFilter returns the top level fields of a filter, $and included, in order.
+func classifyFilter(filter bson.D) ([]shapeField, bool) {
+	fields := make([]shapeField, 0, len(filter))
+	isUnsupported := false
+	for _, element := range filter {
+		switch element.Key {
+		case "$and":
+			conditions, _ := element.Value.(bson.A)
+			for _, condition := range conditions {
+				conditionDoc, _ := condition.(bson.D)
+				subFields, subUnsupported := classifyFilter(conditionDoc)
+				fields = append(fields, subFields...)
+				isUnsupported = isUnsupported || subUnsupported
+			}
+			continue
+		case "$or", "$nor", "$expr", "$where", "$text":
+			isUnsupported = true
+			continue
+		case "$comment":
+			continue
+		}
+		fields = append(fields, shapeField{Field: element.Key, Class: classifyValue(element.Value)})
+	}
+	return fields, isUnsupported
+}
+
+func classifyValue(value interface{}) string {
+	switch v := value.(type) {
+	case primitive.Regex:
+		if strings.HasPrefix(v.Pattern, "^") && !strings.Contains(v.Options, "i") {
+			return FieldClassRange
+		}
+		return FieldClassOther
+	case bson.D:
+		if len(v) == 0 || !strings.HasPrefix(v[0].Key, "$") {
+			return FieldClassEquality
+		}
+		class := FieldClassEquality
+		for _, operator := range v {
+			switch operator.Key {
+			case "$eq", "$in", "$elemMatch", "$all":
+			case "$gt", "$gte", "$lt", "$lte":
+				class = FieldClassRange
+			case "$regex":
+				if pattern, ok := operator.Value.(string); ok && strings.HasPrefix(pattern, "^") {
+					class = FieldClassRange
+				} else {
+					return FieldClassOther
+				}
+			case "$options":
+			default:
+				return FieldClassOther
+			}
+		}
+		return class
+	default:
+		return FieldClassEquality
+	}
+}
+
+func shapeKey(collection string, fields []shapeField, sorts []shapeSort, isUnsupported bool) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field.Field+":"+field.Class)
+	}
+	sort.Strings(parts)
+	key := collection + " {" + strings.Join(parts, ", ") + "}"
+	if isUnsupported {
+		key += " +unsupported"
+	}
+	if len(sorts) > 0 {
+		sortParts := make([]string, len(sorts))
+		for i, sortField := range sorts {
+			sortParts[i] = fmt.Sprintf("%s:%d", sortField.Field, sortField.Direction)
+		}
+		key += " sort {" + strings.Join(sortParts, ", ") + "}"
+	}
+	return key
+}
+
+// proposeKeys follows the ESR rule: equality fields, then sort fields, then range fields.
+func proposeKeys(fields []shapeField, sorts []shapeSort) []models.IndexKey {
+	keys := make([]models.IndexKey, 0, len(fields)+len(sorts))
+	mapField := make(map[string]struct{})
+	add := func(field string, direction int32) {
+		if _, exists := mapField[field]; exists {
+			return
+		}
+		mapField[field] = struct{}{}
+		keys = append(keys, models.IndexKey{Field: field, Value: direction})
+	}
+	for _, field := range fields {
+		if field.Class == FieldClassEquality {
+			add(field.Field, 1)
+		}
+	}
+	for _, sortField := range sorts {
+		add(sortField.Field, sortField.Direction)
+	}
+	for _, field := range fields {
+		if field.Class == FieldClassRange {
+			add(field.Field, 1)
+		}
+	}
+	return keys
+}
+
+// isPrefix tells whether the declared keys start with the proposed fields, in the same
+// directions or all reversed since an index can be walked both ways.
+func isPrefix(proposed, declared []models.IndexKey) bool {
+	if len(proposed) > len(declared) || models.IsTextIndex(declared) {
+		return false
+	}
+	isSame, isReversed := true, true
+	for i, key := range proposed {
+		if key.Field != declared[i].Field {
+			return false
+		}
+		direction, ok := toInt64(declared[i].Value)
+		if !ok {
+			return false
+		}
+		proposedDirection, _ := toInt64(key.Value)
+		isSame = isSame && direction == proposedDirection
+		isReversed = isReversed && direction == -proposedDirection
+	}
+	return isSame || isReversed
+}
+
+func lookup(document bson.D, key string) interface{} {
+	index := slices.IndexFunc(document, func(element bson.E) bool {
+		return element.Key == key
+	})
+	if index < 0 {
+		return nil
+	}
+	return document[index].Value
+}
+
+func stringValue(document bson.D, key string) string {
+	value, _ := lookup(document, key).(string)
+	return value
+}
+
+func intValue(document bson.D, key string) int64 {
+	value, _ := toInt64(lookup(document, key))
+	return value
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,43 @@
+package apikey
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"doctor-manager-api/utilities/hasher/sha256"
+	"doctor-manager-api/utilities/tool"
+)
+
+// Keys look like "mim_<prefix>_<secret>". The "mim_<prefix>" part is stored in clear
+// so a key can be looked up, only the sha256 of the full key is persisted.
+const (
+	keyScheme    = "mim"
+	prefixLength = 8
+	secretLength = 32
+	separator    = "_"
+)
+
+// Generate returns a new raw key together with its lookup prefix and hash.
+func Generate() (rawKey, prefix, keyHash string) {
+	toolService := tool.New()
+	prefix = keyScheme + separator + toolService.GenerateRandomString(prefixLength)
+	rawKey = prefix + separator + toolService.GenerateRandomString(secretLength)
+	return rawKey, prefix, Hash(rawKey)
+}
+
+// Prefix extracts the lookup prefix of a raw key.
+func Prefix(rawKey string) (string, bool) {
+	parts := strings.Split(rawKey, separator)
+	if len(parts) != 3 || parts[0] != keyScheme || len(parts[1]) != prefixLength || len(parts[2]) != secretLength {
+		return "", false
+	}
+	return parts[0] + separator + parts[1], true
+}
+
+func Hash(rawKey string) string {
+	return sha256.New().EncodeToHexString(rawKey)
+}
+
+func Verify(rawKey, keyHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(rawKey)), []byte(keyHash)) == 1
+}
@@ -0,0 +1,54 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+)
+
+// Ciphertexts are "v1:" followed by the base64 of nonce and sealed data, the version
+// leaves room for another scheme without re-encrypting stored values up front.
+const (
+	versionPrefix = "v1:"
+	keyLength     = 32
+)
+
+var global Service
+
+type Service interface {
+	InitGlobal()
+	Encrypt(plaintext string) (ciphertext string, err error)
+	Decrypt(ciphertext string) (plaintext string, err error)
+}
+
+type service struct {
+	aead cipher.AEAD
+}
+
+// New expects a base64 encoded 32 bytes key, e.g. the output of `openssl rand -base64 32`.
+func New(key string) (Service, error) {
+	rawKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, errors.New("encryption: key is not valid base64")
+	}
+	if len(rawKey) != keyLength {
+		return nil, errors.New("encryption: key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(rawKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &service{
+		aead: aead,
+	}, nil
+}
+
+// GetGlobal is nil when DATA_ENCRYPTION_KEY is not configured.
+func GetGlobal() Service {
+	return global
+}
@@ -0,0 +1,54 @@
+// Package estimate predicts what building an index costs from the statistics of its collection
+// and a sample of its documents. Figures are orders of magnitude meant to compare builds and spot
+// the expensive ones, not to plan capacity to the byte.
+package estimate
+
+import (
+	"time"
+)
+
+const (
+	// recordIdSize is the size of the RecordId each index entry points to.
+	recordIdSize = 8
+	// entryOverhead is the per entry cost of the WiredTiger B-tree besides the key.
+	entryOverhead = 4
+	// defaultKeySize is the key size assumed without sampled documents.
+	defaultKeySize = 16
+	// minPrefixCompression is the size ratio left by prefix compression when every entry shares
+	// its key with others, unique keys are not compressed.
+	minPrefixCompression = 0.4
+	// scanRate is the collection scan speed of a build, in bytes per second.
+	scanRate = 100 << 20
+	// insertRate is the speed at which sorted keys are written to the index, per second.
+	insertRate = 250_000
+	// sortMemory is the default maxIndexBuildMemoryUsageMegabytes, above which the build spills
+	// its keys to temporary files.
+	sortMemory = 200 << 20
+)
+
+// Collection is the storage statistics of the collection to index.
+type Collection struct {
+	Count int64
+	Size  int64
+}
+
+// Build is the estimated cost of an index build. AdditionalDisk holds the index itself and the
+// keys spilled to disk while sorting, which are freed once the build ends. Cardinality is the
+// ratio of distinct keys among the sampled entries.
+type Build struct {
+	Duration       time.Duration
+	IndexSize      int64
+	AdditionalDisk int64
+	Entries        int64
+	Cardinality    float64
+}
+
+// Add sums the builds, Cardinality aside.
+func (b Build) Add(other Build) Build {
+	return Build{
+		Duration:       b.Duration + other.Duration,
+		IndexSize:      b.IndexSize + other.IndexSize,
+		AdditionalDisk: b.AdditionalDisk + other.AdditionalDisk,
+		Entries:        b.Entries + other.Entries,
+	}
+}
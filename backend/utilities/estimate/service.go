@@ -0,0 +1,189 @@
+package estimate
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+
+	"doctor-manager-api/utilities/indexspec"
+)
+
+const (
+	// hashedKeySize is the size of the 64 bits hash a hashed key stores.
+	hashedKeySize = 8
+	// termOverhead is the type byte of a text term and its weight, a double.
+	termOverhead = 1 + 8
+)
+
+// keyPart is the values one document gives to a key of the index, or to the whole text part.
+type keyPart struct {
+	values []string
+	size   int
+}
+
+// Index estimates the build of the index on the collection. Sampled documents give the number
+// of entries per document, multikey and text indexes having several, the size of the keys and
+// their cardinality, on which prefix compression depends.
+func Index(collection Collection, documents []bson.Raw, spec indexspec.Spec) Build {
+	if collection.Count <= 0 {
+		return Build{}
+	}
+	var (
+		sampleEntries int64
+		sampleBytes   int64
+		distinct      = make(map[string]struct{})
+	)
+	for _, document := range documents {
+		entries, size := documentEntries(document, spec, distinct)
+		sampleEntries += entries
+		sampleBytes += size
+	}
+	entriesPerDocument, keySize, cardinality := 1.0, float64(defaultKeySize), 1.0
+	if sampleEntries > 0 {
+		entriesPerDocument = float64(sampleEntries) / float64(len(documents))
+		keySize = float64(sampleBytes) / float64(sampleEntries)
+		cardinality = float64(len(distinct)) / float64(sampleEntries)
+	}
+	entries := int64(math.Ceil(float64(collection.Count) * entriesPerDocument))
+	rawSize := float64(entries) * (keySize + recordIdSize + entryOverhead)
+	build := Build{
+		Duration:    time.Duration((float64(collection.Size)/scanRate + float64(entries)/insertRate) * float64(time.Second)),
+		IndexSize:   int64(rawSize * (minPrefixCompression + (1-minPrefixCompression)*cardinality)),
+		Entries:     entries,
+		Cardinality: cardinality,
+	}
+	build.AdditionalDisk = build.IndexSize + max(0, int64(rawSize)-sortMemory)
+	return build
+}
+
+// documentEntries returns the number of entries the document adds to the index and their total
+// key size, and records its distinct keys. A document with arrays in two parts of the key is one
+// the server refuses to index, parallel arrays, and adds no entry.
+func documentEntries(document bson.Raw, spec indexspec.Spec, distinct map[string]struct{}) (int64, int64) {
+	parts := make([]keyPart, 0, len(spec.Keys))
+	isTextDone := false
+	for _, key := range spec.Keys {
+		if isTextKey(key) {
+			if !isTextDone {
+				parts = append(parts, textPart(document, spec))
+				isTextDone = true
+			}
+			continue
+		}
+		parts = append(parts, valuePart(lookup(document, strings.Split(key.Field, ".")), key.Value == "hashed"))
+	}
+	keys := []string{""}
+	size := 0
+	isMultikey := false
+	for _, part := range parts {
+		if len(part.values) == 0 {
+			return 0, 0
+		}
+		if len(part.values) > 1 {
+			if isMultikey {
+				return 0, 0
+			}
+			isMultikey = true
+		}
+		product := make([]string, 0, len(keys)*len(part.values))
+		for _, prefix := range keys {
+			for _, value := range part.values {
+				product = append(product, prefix+"\x00"+value)
+			}
+		}
+		keys = product
+		size += part.size / len(part.values)
+	}
+	for _, key := range keys {
+		distinct[key] = struct{}{}
+	}
+	return int64(len(keys)), int64(len(keys) * size)
+}
+
+func isTextKey(key indexspec.Key) bool {
+	return indexspec.IsText([]indexspec.Key{key})
+}
+
+// valuePart turns the values of a key into index keys, a missing field indexing null and each
+// element of an array giving its own key.
+func valuePart(values []bson.RawValue, isHashed bool) keyPart {
+	if len(values) == 0 {
+		values = []bson.RawValue{{Type: bsontype.Null}}
+	}
+	part := keyPart{values: make([]string, len(values))}
+	for i, value := range values {
+		part.values[i] = string(value.Type) + string(value.Value)
+		if isHashed {
+			part.size += hashedKeySize
+		} else {
+			part.size += 1 + len(value.Value)
+		}
+	}
+	return part
+}
+
+// textPart gives one key per distinct term of the text fields, each with its weight. Terms are
+// split on anything but letters and digits, without stemming nor stop words.
+func textPart(document bson.Raw, spec indexspec.Spec) keyPart {
+	terms := make(map[string]struct{})
+	for field := range spec.TextWeights() {
+		for _, value := range lookup(document, strings.Split(field, ".")) {
+			text, ok := value.StringValueOK()
+			if !ok {
+				continue
+			}
+			for _, term := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			}) {
+				terms[term] = struct{}{}
+			}
+		}
+	}
+	part := keyPart{values: make([]string, 0, len(terms))}
+	for term := range terms {
+		part.values = append(part.values, term)
+		part.size += len(term) + termOverhead
+	}
+	return part
+}
+
+// lookup returns the values of a dotted path, traversing arrays of documents and expanding a
+// final array into its elements as a multikey index does. Positional components are not
+// resolved.
+func lookup(document bson.Raw, path []string) []bson.RawValue {
+	value, err := document.LookupErr(path[0])
+	if err != nil {
+		return nil
+	}
+	if len(path) == 1 {
+		if value.Type != bsontype.Array {
+			return []bson.RawValue{value}
+		}
+		elements, err := value.Array().Values()
+		if err != nil || len(elements) == 0 {
+			return []bson.RawValue{{Type: bsontype.Undefined}}
+		}
+		return elements
+	}
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		return lookup(value.Document(), path[1:])
+	case bsontype.Array:
+		elements, err := value.Array().Values()
+		if err != nil {
+			return nil
+		}
+		values := make([]bson.RawValue, 0, len(elements))
+		for _, element := range elements {
+			if element.Type == bsontype.EmbeddedDocument {
+				values = append(values, lookup(element.Document(), path[1:])...)
+			}
+		}
+		return values
+	}
+	return nil
+}
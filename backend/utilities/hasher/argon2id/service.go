@@ -0,0 +1,100 @@
+package argon2id
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"doctor-manager-api/utilities/hasher"
+)
+
+const Prefix = "$argon2id$"
+
+var ErrInvalidHash = errors.New("argon2id: invalid encoded hash")
+
+// Option follows the OWASP recommendation for argon2id by default.
+type Option struct {
+	Memory      uint32
+	Iterations  uint32
+	SaltLength  uint32
+	KeyLength   uint32
+	Parallelism uint8
+}
+
+var DefaultOption = Option{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type service struct {
+	option Option
+}
+
+func New(opts ...Option) hasher.PasswordService {
+	option := DefaultOption
+	if len(opts) > 0 {
+		option = opts[0]
+	}
+	return &service{option: option}
+}
+
+// Hash encodes the result in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func (s *service) Hash(password string) (string, error) {
+	salt := make([]byte, s.option.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, s.option.Iterations, s.option.Memory, s.option.Parallelism, s.option.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", Prefix, argon2.Version,
+		s.option.Memory, s.option.Iterations, s.option.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (s *service) Verify(password, encodedHash string) (bool, bool, error) {
+	option, salt, key, err := decode(encodedHash)
+	if err != nil {
+		return false, false, err
+	}
+	otherKey := argon2.IDKey([]byte(password), salt, option.Iterations, option.Memory, option.Parallelism, option.KeyLength)
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return false, false, nil
+	}
+	isRehashNeeded := option.Memory != s.option.Memory || option.Iterations != s.option.Iterations ||
+		option.Parallelism != s.option.Parallelism || option.KeyLength != s.option.KeyLength
+	return true, isRehashNeeded, nil
+}
+
+func decode(encodedHash string) (Option, []byte, []byte, error) {
+	var option Option
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return option, nil, nil, ErrInvalidHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return option, nil, nil, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &option.Memory, &option.Iterations, &option.Parallelism); err != nil {
+		return option, nil, nil, ErrInvalidHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return option, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return option, nil, nil, ErrInvalidHash
+	}
+	option.SaltLength = uint32(len(salt)) //nolint:gosec
+	option.KeyLength = uint32(len(key))   //nolint:gosec
+	return option, salt, key, nil
+}
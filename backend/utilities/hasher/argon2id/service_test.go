@@ -0,0 +1,77 @@
+package argon2id
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testOption keeps the tests fast, the parameters being part of the encoded hash.
+var testOption = Option{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestHashVerify(t *testing.T) {
+	s := New(testOption)
+	encodedHash, err := s.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encodedHash, Prefix+"v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("Hash() = %q, want the PHC format with the parameters", encodedHash)
+	}
+	otherHash, err := s.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherHash == encodedHash {
+		t.Error("Hash() gave the same hash twice, want a random salt")
+	}
+	isMatch, isRehashNeeded, err := s.Verify("correct horse", encodedHash)
+	if err != nil || !isMatch || isRehashNeeded {
+		t.Errorf("Verify(right password) = %v, %v, %v, want true, false, nil", isMatch, isRehashNeeded, err)
+	}
+	isMatch, _, err = s.Verify("wrong horse", encodedHash)
+	if err != nil || isMatch {
+		t.Errorf("Verify(wrong password) = %v, %v, want false, nil", isMatch, err)
+	}
+}
+
+func TestVerifyRehash(t *testing.T) {
+	encodedHash, err := New(testOption).Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stronger := testOption
+	stronger.Iterations = 2
+	isMatch, isRehashNeeded, err := New(stronger).Verify("correct horse", encodedHash)
+	if err != nil || !isMatch || !isRehashNeeded {
+		t.Errorf("Verify() with other parameters = %v, %v, %v, want true, true, nil", isMatch, isRehashNeeded, err)
+	}
+}
+
+func TestVerifyInvalidHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		encodedHash string
+	}{
+		{name: "empty", encodedHash: ""},
+		{name: "other algorithm", encodedHash: "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5"},
+		{name: "other version", encodedHash: "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5"},
+		{name: "bad parameters", encodedHash: "$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5"},
+		{name: "bad salt", encodedHash: "$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5"},
+		{name: "no key", encodedHash: "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$"},
+	}
+	s := New(testOption)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := s.Verify("correct horse", test.encodedHash); !errors.Is(err, ErrInvalidHash) {
+				t.Errorf("Verify() error = %v, want ErrInvalidHash", err)
+			}
+		})
+	}
+}
@@ -45,3 +45,12 @@ func (s *service) EncodeToHexString(value string) string {
 func (s *service) Reset() {
 	s.hashFunction.Reset()
 }
+
+// PasswordService hashes passwords into a self-describing encoded string, so the
+// algorithm and its parameters can be read back from the stored value.
+type PasswordService interface {
+	Hash(password string) (encodedHash string, err error)
+	// Verify reports whether password matches encodedHash, and whether the hash
+	// should be replaced because it uses an outdated algorithm or parameters.
+	Verify(password, encodedHash string) (isMatch, isRehashNeeded bool, err error)
+}
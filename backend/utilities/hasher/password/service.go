@@ -0,0 +1,63 @@
+package password
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"doctor-manager-api/utilities/hasher"
+	"doctor-manager-api/utilities/hasher/argon2id"
+	"doctor-manager-api/utilities/hasher/sha256"
+)
+
+var ErrUnknownHash = errors.New("password: unknown hash format")
+
+// legacySha256Length is the length of an unsalted sha256 hex digest.
+const legacySha256Length = 64
+
+type service struct {
+	current hasher.PasswordService
+}
+
+// New hashes with argon2id and verifies argon2id, bcrypt and legacy unsalted sha256
+// hashes. Anything but the current argon2id parameters is reported for rehash.
+func New() hasher.PasswordService {
+	return &service{current: argon2id.New()}
+}
+
+func (s *service) Hash(password string) (string, error) {
+	return s.current.Hash(password)
+}
+
+func (s *service) Verify(password, encodedHash string) (bool, bool, error) {
+	switch {
+	case strings.HasPrefix(encodedHash, argon2id.Prefix):
+		return s.current.Verify(password, encodedHash)
+	case strings.HasPrefix(encodedHash, "$2a$"), strings.HasPrefix(encodedHash, "$2b$"), strings.HasPrefix(encodedHash, "$2y$"):
+		if err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, false, nil
+			}
+			return false, false, err
+		}
+		return true, true, nil
+	case isLegacySha256(encodedHash):
+		hash := sha256.New().EncodeToHexString(password)
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(strings.ToLower(encodedHash))) != 1 {
+			return false, false, nil
+		}
+		return true, true, nil
+	}
+	return false, false, ErrUnknownHash
+}
+
+func isLegacySha256(encodedHash string) bool {
+	if len(encodedHash) != legacySha256Length {
+		return false
+	}
+	_, err := hex.DecodeString(encodedHash)
+	return err == nil
+}
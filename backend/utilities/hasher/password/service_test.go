@@ -0,0 +1,68 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"doctor-manager-api/utilities/hasher/argon2id"
+	"doctor-manager-api/utilities/hasher/sha256"
+)
+
+func TestHashVerify(t *testing.T) {
+	s := New()
+	encodedHash, err := s.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encodedHash, argon2id.Prefix) {
+		t.Fatalf("Hash() = %q, want an argon2id hash", encodedHash)
+	}
+	isMatch, isRehashNeeded, err := s.Verify("correct horse", encodedHash)
+	if err != nil || !isMatch || isRehashNeeded {
+		t.Errorf("Verify(right password) = %v, %v, %v, want true, false, nil", isMatch, isRehashNeeded, err)
+	}
+	isMatch, _, err = s.Verify("wrong horse", encodedHash)
+	if err != nil || isMatch {
+		t.Errorf("Verify(wrong password) = %v, %v, want false, nil", isMatch, err)
+	}
+}
+
+func TestVerifyLegacy(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha256Hash := sha256.New().EncodeToHexString("correct horse")
+	tests := []struct {
+		name        string
+		encodedHash string
+	}{
+		{name: "bcrypt", encodedHash: string(bcryptHash)},
+		{name: "sha256", encodedHash: sha256Hash},
+		{name: "sha256 upper case", encodedHash: strings.ToUpper(sha256Hash)},
+	}
+	s := New()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isMatch, isRehashNeeded, err := s.Verify("correct horse", test.encodedHash)
+			if err != nil || !isMatch || !isRehashNeeded {
+				t.Errorf("Verify(right password) = %v, %v, %v, want true, true, nil", isMatch, isRehashNeeded, err)
+			}
+			isMatch, isRehashNeeded, err = s.Verify("wrong horse", test.encodedHash)
+			if err != nil || isMatch || isRehashNeeded {
+				t.Errorf("Verify(wrong password) = %v, %v, %v, want false, false, nil", isMatch, isRehashNeeded, err)
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownHash(t *testing.T) {
+	for _, encodedHash := range []string{"", "plain text", "$1$md5crypt$hash", strings.Repeat("z", legacySha256Length)} {
+		if _, _, err := New().Verify("correct horse", encodedHash); !errors.Is(err, ErrUnknownHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrUnknownHash", encodedHash, err)
+		}
+	}
+}
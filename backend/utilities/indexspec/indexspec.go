@@ -0,0 +1,272 @@
+// Package indexspec is the canonical definition of an index, shared by the declared indexes of
+// the manager and the live indexes of target databases.
+//
+// Two specs are equivalent when their signatures are equal. The signature keeps the order of
+// the keys, since {a: 1, b: 1} and {b: 1, a: 1} are different compound indexes, and compares
+// numbers by value whatever their BSON type, as the server does. A text index is identified by
+// the position of its text part among the other keys, its text fields and their weights, in
+// field order, and its default language, whether it is declared with "text" keys or listed with
+// _fts and _ftsx keys. Unique, the TTL and every collation option, with the server defaults
+// filled in, are part of the signature, the name of the index is not.
+package indexspec
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	textValue  = "text"
+	textMarker = "$text"
+)
+
+// Key is a field of the key pattern. Value is a direction or an index type such as "text",
+// "hashed" or "2dsphere".
+type Key struct {
+	Value interface{} `bson:"value"`
+	Field string      `bson:"field"`
+}
+
+type Collation struct {
+	Strength        *int   `bson:"strength,omitempty" json:"strength,omitempty"`
+	CaseLevel       *bool  `bson:"case_level,omitempty" json:"case_level,omitempty"`
+	NumericOrdering *bool  `bson:"numeric_ordering,omitempty" json:"numeric_ordering,omitempty"`
+	Backwards       *bool  `bson:"backwards,omitempty" json:"backwards,omitempty"`
+	Locale          string `bson:"locale" json:"locale"`
+	CaseFirst       string `bson:"case_first,omitempty" json:"case_first,omitempty"`
+	Alternate       string `bson:"alternate,omitempty" json:"alternate,omitempty"`
+	MaxVariable     string `bson:"max_variable,omitempty" json:"max_variable,omitempty"`
+}
+
+// signature lists every option of the collation with the defaults the server fills in, as
+// listIndexes returns them. It is empty for the simple collation, which the server does not
+// store on the index.
+func (c Collation) signature() string {
+	if c.Locale == "" || c.Locale == "simple" {
+		return ""
+	}
+	strength := 3
+	if c.Strength != nil {
+		strength = *c.Strength
+	}
+	caseFirst := c.CaseFirst
+	if caseFirst == "" {
+		caseFirst = "off"
+	}
+	alternate := c.Alternate
+	if alternate == "" {
+		alternate = "non-ignorable"
+	}
+	maxVariable := c.MaxVariable
+	if maxVariable == "" {
+		maxVariable = "punct"
+	}
+	// French Canadian is the only locale sorting accents backwards by default.
+	backwards := c.Locale == "fr_CA"
+	if c.Backwards != nil {
+		backwards = *c.Backwards
+	}
+	return "collation_locale_" + c.Locale +
+		"_strength_" + strconv.Itoa(strength) +
+		"_caseLevel_" + strconv.FormatBool(c.CaseLevel != nil && *c.CaseLevel) +
+		"_caseFirst_" + caseFirst +
+		"_numericOrdering_" + strconv.FormatBool(c.NumericOrdering != nil && *c.NumericOrdering) +
+		"_alternate_" + alternate +
+		"_maxVariable_" + maxVariable +
+		"_backwards_" + strconv.FormatBool(backwards) + "_"
+}
+
+type Option struct {
+	ExpireAfterSeconds *int32                 `bson:"expire_after_seconds"`
+	Collation          *Collation             `bson:"collation,omitempty"`
+	Weights            map[string]interface{} `bson:"weights,omitempty"`
+	DefaultLanguage    string                 `bson:"default_language,omitempty"`
+	IsUnique           bool                   `bson:"is_unique"`
+}
+
+type Spec struct {
+	Options Option
+	Keys    []Key
+}
+
+// NormalizeValue turns a number into an int32 when it is integral and fits, an int64 when it is
+// integral, a float64 otherwise. Other values are returned as is.
+func NormalizeValue(value interface{}) interface{} {
+	var number float64
+	switch v := value.(type) {
+	case int32:
+		return v
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case float32:
+		number = float64(v)
+	case float64:
+		number = v
+	default:
+		return value
+	}
+	switch {
+	case number != math.Trunc(number) || math.IsInf(number, 0):
+		return number
+	case number >= math.MinInt32 && number <= math.MaxInt32:
+		return int32(number)
+	case number >= math.MinInt64 && number < math.MaxInt64:
+		return int64(number)
+	default:
+		return number
+	}
+}
+
+// IsText tells whether the keys hold a text part, declared or as listed by the server.
+func IsText(keys []Key) bool {
+	for _, key := range keys {
+		if isTextKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTextKey(key Key) bool {
+	if key.Field == "_fts" || key.Field == "_ftsx" {
+		return true
+	}
+	value, ok := key.Value.(string)
+	return ok && value == textValue
+}
+
+// KeysFromDocument reads a key pattern as listed by the server, in order.
+func KeysFromDocument(document bson.D) []Key {
+	keys := make([]Key, len(document))
+	for i, element := range document {
+		keys[i] = Key{Field: element.Key, Value: NormalizeValue(element.Value)}
+	}
+	return keys
+}
+
+// WeightsFromDocument reads the weights of a text index as listed by the server.
+func WeightsFromDocument(document bson.D) map[string]interface{} {
+	if len(document) == 0 {
+		return nil
+	}
+	weights := make(map[string]interface{}, len(document))
+	for _, element := range document {
+		weights[element.Key] = NormalizeValue(element.Value)
+	}
+	return weights
+}
+
+// KeyDocument is the key pattern of the spec as sent to the server.
+func (s Spec) KeyDocument() bson.D {
+	document := make(bson.D, len(s.Keys))
+	for i, key := range s.Keys {
+		document[i] = bson.E{Key: key.Field, Value: NormalizeValue(key.Value)}
+	}
+	return document
+}
+
+// Normalize returns a copy of the spec with the numbers of its keys and weights normalized.
+func (s Spec) Normalize() Spec {
+	normalized := Spec{
+		Options: s.Options,
+		Keys:    make([]Key, len(s.Keys)),
+	}
+	for i, key := range s.Keys {
+		normalized.Keys[i] = Key{Field: key.Field, Value: NormalizeValue(key.Value)}
+	}
+	if s.Options.Weights != nil {
+		normalized.Options.Weights = make(map[string]interface{}, len(s.Options.Weights))
+		for field, weight := range s.Options.Weights {
+			normalized.Options.Weights[field] = NormalizeValue(weight)
+		}
+	}
+	return normalized
+}
+
+// TextWeights returns the weight of each text field, 1 unless the options give one. It is
+// empty when the index has no text part.
+func (s Spec) TextWeights() map[string]interface{} {
+	weights := make(map[string]interface{})
+	if !IsText(s.Keys) {
+		return weights
+	}
+	for _, key := range s.Keys {
+		if isTextKey(key) && key.Field != "_fts" && key.Field != "_ftsx" {
+			weights[key.Field] = int32(1)
+		}
+	}
+	for field, weight := range s.Options.Weights {
+		weights[field] = NormalizeValue(weight)
+	}
+	return weights
+}
+
+// Signature is the canonical string of the spec, empty without keys.
+func (s Spec) Signature() string {
+	if len(s.Keys) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	isText := false
+	for _, key := range s.Keys {
+		if isTextKey(key) {
+			if !isText {
+				builder.WriteString(textMarker + "_")
+				isText = true
+			}
+			continue
+		}
+		builder.WriteString(key.Field + "_" + formatValue(key.Value) + "_")
+	}
+	if isText {
+		if s.Options.DefaultLanguage != "" {
+			builder.WriteString("default_language_" + s.Options.DefaultLanguage + "_")
+		}
+		weights := s.TextWeights()
+		fields := make([]string, 0, len(weights))
+		for field := range weights {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			builder.WriteString("weights_" + field + "_" + formatValue(weights[field]) + "_")
+		}
+	}
+	if s.Options.Collation != nil {
+		builder.WriteString(s.Options.Collation.signature())
+	}
+	if s.Options.IsUnique {
+		builder.WriteString("unique_")
+	}
+	if s.Options.ExpireAfterSeconds != nil {
+		builder.WriteString("expireAfterSeconds_" + strconv.Itoa(int(*s.Options.ExpireAfterSeconds)))
+	}
+	return builder.String()
+}
+
+// Equivalent tells whether both specs define the same index.
+func (s Spec) Equivalent(other Spec) bool {
+	return s.Signature() == other.Signature()
+}
+
+func formatValue(value interface{}) string {
+	switch v := NormalizeValue(value).(type) {
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,336 @@
+package indexspec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  interface{}
+	}{
+		{value: 1, want: int32(1)},
+		{value: int32(-1), want: int32(-1)},
+		{value: int64(1), want: int32(1)},
+		{value: float64(-1), want: int32(-1)},
+		{value: float32(2), want: int32(2)},
+		{value: int64(1) << 40, want: int64(1) << 40},
+		{value: 1.5, want: 1.5},
+		{value: "hashed", want: "hashed"},
+		{value: true, want: true},
+	}
+	for _, test := range tests {
+		if got := NormalizeValue(test.value); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("NormalizeValue(%#v) = %#v, want %#v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestSignature(t *testing.T) {
+	expireAfterSeconds := int32(3600)
+	strength := 2
+	tests := []struct {
+		name string
+		spec Spec
+		want string
+	}{
+		{
+			name: "empty",
+			spec: Spec{},
+			want: "",
+		},
+		{
+			name: "compound keeps key order",
+			spec: Spec{Keys: []Key{{Field: "b", Value: 1}, {Field: "a", Value: -1}}},
+			want: "b_1_a_-1_",
+		},
+		{
+			name: "options",
+			spec: Spec{
+				Keys: []Key{{Field: "email", Value: "hashed"}},
+				Options: Option{
+					ExpireAfterSeconds: &expireAfterSeconds,
+					Collation:          &Collation{Locale: "fr", Strength: &strength},
+					IsUnique:           true,
+				},
+			},
+			want: "email_hashed_collation_locale_fr_strength_2_caseLevel_false_caseFirst_off_numericOrdering_false_alternate_non-ignorable_maxVariable_punct_backwards_false_unique_expireAfterSeconds_3600",
+		},
+		{
+			name: "compound text",
+			spec: Spec{
+				Keys: []Key{{Field: "category", Value: 1}, {Field: "title", Value: "text"}, {Field: "body", Value: "text"}},
+				Options: Option{
+					DefaultLanguage: "none",
+					Weights:         map[string]interface{}{"title": 10.0},
+				},
+			},
+			want: "category_1_$text_default_language_none_weights_body_1_weights_title_10_",
+		},
+		{
+			name: "text part position",
+			spec: Spec{
+				Keys: []Key{{Field: "_fts", Value: "text"}, {Field: "_ftsx", Value: 1}, {Field: "category", Value: 1}},
+				Options: Option{
+					Weights: map[string]interface{}{"title": int32(1)},
+				},
+			},
+			want: "$text_category_1_weights_title_1_",
+		},
+	}
+	for _, test := range tests {
+		if got := test.spec.Signature(); got != test.want {
+			t.Errorf("%s: Signature() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	tertiary, secondary := 3, 2
+	yes, no := true, false
+	tests := []struct {
+		name       string
+		spec       Spec
+		other      Spec
+		equivalent bool
+	}{
+		{
+			name:       "numeric types",
+			spec:       Spec{Keys: []Key{{Field: "a", Value: int32(1)}, {Field: "b", Value: int64(-1)}}},
+			other:      Spec{Keys: []Key{{Field: "a", Value: 1.0}, {Field: "b", Value: -1}}},
+			equivalent: true,
+		},
+		{
+			name:  "key order",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}, {Field: "b", Value: 1}}},
+			other: Spec{Keys: []Key{{Field: "b", Value: 1}, {Field: "a", Value: 1}}},
+		},
+		{
+			name:  "direction",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: -1}}},
+		},
+		{
+			name: "declared and listed text index",
+			spec: Spec{
+				Keys:    []Key{{Field: "title", Value: "text"}, {Field: "body", Value: "text"}},
+				Options: Option{DefaultLanguage: "english", Weights: map[string]interface{}{"title": 5}},
+			},
+			other: Spec{
+				Keys:    []Key{{Field: "_fts", Value: "text"}, {Field: "_ftsx", Value: int32(1)}},
+				Options: Option{DefaultLanguage: "english", Weights: map[string]interface{}{"body": int32(1), "title": int32(5)}},
+			},
+			equivalent: true,
+		},
+		{
+			name: "text weights",
+			spec: Spec{
+				Keys:    []Key{{Field: "_fts", Value: "text"}, {Field: "_ftsx", Value: 1}},
+				Options: Option{Weights: map[string]interface{}{"title": 1}},
+			},
+			other: Spec{
+				Keys:    []Key{{Field: "_fts", Value: "text"}, {Field: "_ftsx", Value: 1}},
+				Options: Option{Weights: map[string]interface{}{"title": 2}},
+			},
+		},
+		{
+			name:  "unique",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{IsUnique: true}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}},
+		},
+		{
+			name: "declared and listed collation",
+			spec: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{
+				Locale:          "fr",
+				Strength:        &tertiary,
+				CaseLevel:       &no,
+				CaseFirst:       "off",
+				NumericOrdering: &no,
+				Alternate:       "non-ignorable",
+				MaxVariable:     "punct",
+				Backwards:       &no,
+			}}},
+			equivalent: true,
+		},
+		{
+			name:       "locale default backwards",
+			spec:       Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr_CA"}}},
+			other:      Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr_CA", Backwards: &yes}}},
+			equivalent: true,
+		},
+		{
+			name:       "simple collation",
+			spec:       Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "simple"}}},
+			other:      Spec{Keys: []Key{{Field: "a", Value: 1}}},
+			equivalent: true,
+		},
+		{
+			name:  "collation locale",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "de"}}},
+		},
+		{
+			name:  "collation strength",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", Strength: &secondary}}},
+		},
+		{
+			name:  "collation case level",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", CaseLevel: &yes}}},
+		},
+		{
+			name:  "collation case first",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", CaseFirst: "upper"}}},
+		},
+		{
+			name:  "collation numeric ordering",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", NumericOrdering: &yes}}},
+		},
+		{
+			name:  "collation alternate",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", Alternate: "shifted"}}},
+		},
+		{
+			name:  "collation max variable",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", Alternate: "shifted"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", Alternate: "shifted", MaxVariable: "space"}}},
+		},
+		{
+			name:  "collation backwards",
+			spec:  Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr"}}},
+			other: Spec{Keys: []Key{{Field: "a", Value: 1}}, Options: Option{Collation: &Collation{Locale: "fr", Backwards: &yes}}},
+		},
+	}
+	for _, test := range tests {
+		if got := test.spec.Equivalent(test.other); got != test.equivalent {
+			t.Errorf("%s: Equivalent() = %v, want %v", test.name, got, test.equivalent)
+		}
+	}
+}
+
+// randomSpec is a spec with 1 to 4 distinct fields, numbers of random BSON types and, half of
+// the time, a weighted text part of contiguous fields as the server requires.
+type randomSpec Spec
+
+func (randomSpec) Generate(rand *rand.Rand, _ int) reflect.Value {
+	fields := rand.Perm(6)[:1+rand.Intn(4)]
+	spec := Spec{Keys: make([]Key, 0, len(fields))}
+	textStart, textEnd := 0, 0
+	if rand.Intn(2) == 0 {
+		textStart = rand.Intn(len(fields))
+		textEnd = textStart + 1 + rand.Intn(len(fields)-textStart)
+		spec.Options.Weights = make(map[string]interface{})
+	}
+	for i, field := range fields {
+		name := fmt.Sprintf("f%d", field)
+		if i >= textStart && i < textEnd {
+			spec.Keys = append(spec.Keys, Key{Field: name, Value: "text"})
+			spec.Options.Weights[name] = randomNumber(rand, 1+rand.Intn(10))
+			continue
+		}
+		spec.Keys = append(spec.Keys, Key{Field: name, Value: randomNumber(rand, 1-2*rand.Intn(2))})
+	}
+	spec.Options.IsUnique = rand.Intn(2) == 0
+	return reflect.ValueOf(randomSpec(spec))
+}
+
+func randomNumber(rand *rand.Rand, value int) interface{} {
+	switch rand.Intn(4) {
+	case 0:
+		return value
+	case 1:
+		return int32(value)
+	case 2:
+		return int64(value)
+	default:
+		return float64(value)
+	}
+}
+
+func TestSignatureProperties(t *testing.T) {
+	properties := map[string]interface{}{
+		"normalize keeps the signature": func(spec randomSpec) bool {
+			return Spec(spec).Signature() == Spec(spec).Normalize().Signature()
+		},
+		"normalize is idempotent": func(spec randomSpec) bool {
+			normalized := Spec(spec).Normalize()
+			return reflect.DeepEqual(normalized, normalized.Normalize())
+		},
+		"signature is deterministic": func(spec randomSpec) bool {
+			signature := Spec(spec).Signature()
+			for i := 0; i < 10; i++ {
+				if Spec(spec).Signature() != signature {
+					return false
+				}
+			}
+			return true
+		},
+		"swapping two adjacent fields changes the signature": func(spec randomSpec) bool {
+			keys := Spec(spec).Keys
+			for i := 1; i < len(keys); i++ {
+				if isTextKey(keys[i-1]) || isTextKey(keys[i]) {
+					continue
+				}
+				swapped := Spec{Options: spec.Options, Keys: append([]Key{}, keys...)}
+				swapped.Keys[i-1], swapped.Keys[i] = swapped.Keys[i], swapped.Keys[i-1]
+				if Spec(spec).Equivalent(swapped) {
+					return false
+				}
+			}
+			return true
+		},
+		"bson round trip keeps the signature": func(spec randomSpec) bool {
+			data, err := bson.Marshal(Spec(spec))
+			if err != nil {
+				return false
+			}
+			var decoded Spec
+			if err = bson.Unmarshal(data, &decoded); err != nil {
+				return false
+			}
+			return Spec(spec).Equivalent(decoded)
+		},
+		"key document round trip keeps the spec": func(spec randomSpec) bool {
+			data, err := bson.Marshal(bson.D{{Key: "key", Value: Spec(spec).KeyDocument()}})
+			if err != nil {
+				return false
+			}
+			var listed struct {
+				Key bson.D `bson:"key"`
+			}
+			if err = bson.Unmarshal(data, &listed); err != nil {
+				return false
+			}
+			decoded := Spec{Options: spec.Options, Keys: KeysFromDocument(listed.Key)}
+			return reflect.DeepEqual(Spec(spec).Normalize().Keys, decoded.Keys) && Spec(spec).Equivalent(decoded)
+		},
+		"json round trip keeps the signature": func(spec randomSpec) bool {
+			data, err := json.Marshal(Spec(spec))
+			if err != nil {
+				return false
+			}
+			var decoded Spec
+			if err = json.Unmarshal(data, &decoded); err != nil {
+				return false
+			}
+			return Spec(spec).Equivalent(decoded)
+		},
+	}
+	for name, property := range properties {
+		if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
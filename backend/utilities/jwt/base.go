@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"crypto"
+	"crypto/ed25519"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -24,18 +25,43 @@ type Service interface {
 	GenerateToken(tokenId string, isRefreshToken bool, duration time.Duration) (tokenStr string, err error)
 	GeneratePairToken(tokenId string, accessTokenDuration time.Duration, refreshTokenDuration time.Duration) (accessToken string, refreshToken string, err error)
 	ValidateToken(token string) (data *Payload, err error)
+	GetJWKS() JWKS
 }
 
 type service struct {
 	privateKey crypto.PrivateKey
 	publicKey  crypto.PublicKey
+	publicKeys map[string]crypto.PublicKey
+	keyId      string
+	jwks       JWKS
 }
 
-func New(privateKey, publicKey string) Service {
-	return &service{
+// New signs with privateKey and verifies with publicKey plus any verificationKeys,
+// e.g. the public keys of previous signing keys during a rotation overlap.
+func New(privateKey, publicKey string, verificationKeys ...string) Service {
+	s := &service{
 		privateKey: convertPrivateKey([]byte(privateKey)),
 		publicKey:  convertPublicKey([]byte(publicKey)),
+		publicKeys: make(map[string]crypto.PublicKey),
 	}
+	s.keyId = s.addPublicKey(s.publicKey)
+	for _, key := range verificationKeys {
+		s.addPublicKey(convertPublicKey([]byte(key)))
+	}
+	return s
+}
+
+func (s *service) addPublicKey(publicKey crypto.PublicKey) string {
+	edKey, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		logger.Fatal().Msg("Public key is not an Ed25519 key")
+	}
+	jwk := newJWK(edKey)
+	if _, exists := s.publicKeys[jwk.Kid]; !exists {
+		s.publicKeys[jwk.Kid] = edKey
+		s.jwks.Keys = append(s.jwks.Keys, jwk)
+	}
+	return jwk.Kid
 }
 
 func GetGlobal() Service {
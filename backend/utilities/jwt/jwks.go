@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// JWK is an Ed25519 public key as described by RFC 8037.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newJWK(publicKey ed25519.PublicKey) JWK {
+	x := base64.RawURLEncoding.EncodeToString(publicKey)
+	return JWK{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   x,
+		Kid: thumbprint(x),
+		Use: "sig",
+		Alg: "EdDSA",
+	}
+}
+
+// thumbprint is the RFC 7638 JWK thumbprint, used as key id so a key keeps the
+// same kid wherever it is loaded from.
+func thumbprint(x string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, `{"crv":"Ed25519","kty":"OKP","x":"%s"}`, x))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ReadVerificationKeys returns every *.pem file of dir, sorted by name. An empty dir
+// means no extra verification key.
+func ReadVerificationKeys(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		logger.Fatal().Err(err).Str("dir", dir).Msg("Verification keys dir error")
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".pem") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		key, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			logger.Fatal().Err(err).Str("file", name).Msg("Verification key read error")
+		}
+		keys = append(keys, string(key))
+	}
+	return keys
+}
@@ -28,6 +28,7 @@ func (s *service) GenerateToken(tokenId string, isRefreshToken bool, duration ti
 		TokenType: tokenType,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload)
+	token.Header["kid"] = s.keyId
 	return token.SignedString(s.privateKey)
 }
 
@@ -46,7 +47,16 @@ func (s *service) ValidateToken(token string) (data *Payload, err error) {
 		if !ok {
 			return nil, errors.New("token validate failed")
 		}
-		return s.publicKey, nil
+		// Tokens issued before key ids were introduced carry no kid.
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return s.publicKey, nil
+		}
+		publicKey, ok := s.publicKeys[kid]
+		if !ok {
+			return nil, errors.New("token key id unknown")
+		}
+		return publicKey, nil
 	}
 
 	payload := new(Payload)
@@ -55,3 +65,7 @@ func (s *service) ValidateToken(token string) (data *Payload, err error) {
 	}
 	return payload, nil
 }
+
+func (s *service) GetJWKS() JWKS {
+	return s.jwks
+}
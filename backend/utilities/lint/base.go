@@ -0,0 +1,108 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+
+	"doctor-manager-api/database/mongo/models"
+)
+
+const (
+	SeverityOff     = "off"
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+const (
+	RulePrefixRedundant = "prefix-redundant"
+	RuleDuplicate       = "duplicate"
+	RuleReversed        = "reversed-duplicate"
+	RuleUniqueDuplicate = "unique-duplicate"
+	RuleTooManyIndexes  = "too-many-indexes"
+	RuleTooManyKeys     = "too-many-keys"
+	RuleTtlCompound     = "ttl-compound"
+	RuleTtlOnId         = "ttl-on-id"
+	RuleTextCollision   = "text-collision"
+)
+
+// maxKeys is the server limit of fields in a compound index.
+const maxKeys = 32
+
+var defaultSeverities = map[string]string{
+	RulePrefixRedundant: SeverityWarning,
+	RuleDuplicate:       SeverityError,
+	RuleReversed:        SeverityWarning,
+	RuleUniqueDuplicate: SeverityWarning,
+	RuleTooManyIndexes:  SeverityWarning,
+	RuleTooManyKeys:     SeverityError,
+	RuleTtlCompound:     SeverityError,
+	RuleTtlOnId:         SeverityError,
+	RuleTextCollision:   SeverityError,
+}
+
+var global Service
+
+type Service interface {
+	InitGlobal()
+	// Lint checks the declared indexes of one collection.
+	Lint(indexes []models.Index) (issues []Issue)
+}
+
+type Option struct {
+	// Severities overrides the severity of rules by id, SeverityOff disables a rule.
+	Severities map[string]string
+	// MaxIndexes is the number of indexes per collection, _id included, above which
+	// RuleTooManyIndexes reports.
+	MaxIndexes int
+}
+
+// Issue is a rule violation. Index is the name of the offending index, Related the names
+// of the indexes it conflicts with.
+type Issue struct {
+	Related    []string
+	Rule       string
+	Severity   string
+	Collection string
+	Index      string
+	Message    string
+}
+
+// Involves tells whether the issue concerns the index, as offender or related.
+func (i Issue) Involves(name string) bool {
+	return i.Index == name || slices.Contains(i.Related, name)
+}
+
+type service struct {
+	severities map[string]string
+	maxIndexes int
+}
+
+func New(opt Option) (Service, error) {
+	severities := make(map[string]string, len(defaultSeverities))
+	for rule, severity := range defaultSeverities {
+		severities[rule] = severity
+	}
+	for rule, severity := range opt.Severities {
+		if _, exists := defaultSeverities[rule]; !exists {
+			return nil, fmt.Errorf("lint: unknown rule %q", rule)
+		}
+		switch severity {
+		case SeverityOff, SeverityInfo, SeverityWarning, SeverityError:
+		default:
+			return nil, fmt.Errorf("lint: unknown severity %q for rule %q", severity, rule)
+		}
+		severities[rule] = severity
+	}
+	if opt.MaxIndexes <= 0 {
+		opt.MaxIndexes = 10
+	}
+	return &service{
+		severities: severities,
+		maxIndexes: opt.MaxIndexes,
+	}, nil
+}
+
+func GetGlobal() Service {
+	return global
+}
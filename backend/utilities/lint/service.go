@@ -0,0 +1,159 @@
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"doctor-manager-api/database/mongo/models"
+)
+
+func (s *service) InitGlobal() {
+	global = s
+}
+
+func (s *service) Lint(indexes []models.Index) []Issue {
+	issues := make([]Issue, 0)
+	report := func(rule string, index models.Index, related []string, message string) {
+		severity := s.severities[rule]
+		if severity == SeverityOff {
+			return
+		}
+		issues = append(issues, Issue{
+			Related:    related,
+			Rule:       rule,
+			Severity:   severity,
+			Collection: index.Collection,
+			Index:      index.Name,
+			Message:    message,
+		})
+	}
+	if len(indexes) == 0 {
+		return issues
+	}
+
+	total := len(indexes)
+	if !slices.ContainsFunc(indexes, isIdIndex) {
+		total++
+	}
+	if total > s.maxIndexes {
+		// Concerns the collection rather than one index.
+		report(RuleTooManyIndexes, models.Index{Collection: indexes[0].Collection}, nil, fmt.Sprintf("collection has %d indexes, more than %d slow down every write", total, s.maxIndexes))
+	}
+
+	textIndexes := make([]string, 0)
+	for _, index := range indexes {
+		if len(index.Keys) > maxKeys {
+			report(RuleTooManyKeys, index, nil, fmt.Sprintf("index has %d fields, the server accepts at most %d", len(index.Keys), maxKeys))
+		}
+		if index.Options.ExpireAfterSeconds != nil {
+			switch {
+			case len(index.Keys) > 1:
+				report(RuleTtlCompound, index, nil, "TTL is ignored on compound indexes, documents never expire")
+			case len(index.Keys) == 1 && index.Keys[0].Field == "_id":
+				report(RuleTtlOnId, index, nil, "TTL is not supported on the _id field")
+			}
+		}
+		if index.IsText || models.IsTextIndex(index.Keys) {
+			textIndexes = append(textIndexes, index.Name)
+		}
+	}
+	if len(textIndexes) > 1 {
+		for i, name := range textIndexes {
+			related := append(append([]string{}, textIndexes[:i]...), textIndexes[i+1:]...)
+			report(RuleTextCollision, indexByName(indexes, name), related, "a collection can only have one text index")
+		}
+	}
+
+	for i, index := range indexes {
+		if index.IsText || isIdIndex(index) {
+			continue
+		}
+		for j, other := range indexes {
+			if i == j || other.IsText {
+				continue
+			}
+			isPrefixOf, isReversed := matchPrefix(index.Keys, other.Keys)
+			switch {
+			case len(index.Keys) == len(other.Keys) && isPrefixOf && sameOptions(index, other):
+				// Reported once per pair.
+				if i > j {
+					break
+				}
+				if isReversed {
+					report(RuleReversed, index, []string{other.Name}, fmt.Sprintf("keys are those of %q reversed, which serves the same queries", other.Name))
+				} else {
+					report(RuleDuplicate, index, []string{other.Name}, fmt.Sprintf("same definition as %q under another name", other.Name))
+				}
+			case len(index.Keys) == len(other.Keys) && isPrefixOf && !index.Options.IsUnique && other.Options.IsUnique &&
+				index.Options.ExpireAfterSeconds == nil && reflect.DeepEqual(index.Options.Collation, other.Options.Collation):
+				report(RuleUniqueDuplicate, index, []string{other.Name}, fmt.Sprintf("unique index %q on the same keys already serves these queries", other.Name))
+			case len(index.Keys) < len(other.Keys) && isPrefixOf && !index.Options.IsUnique &&
+				index.Options.ExpireAfterSeconds == nil && reflect.DeepEqual(index.Options.Collation, other.Options.Collation):
+				report(RulePrefixRedundant, index, []string{other.Name}, fmt.Sprintf("keys are a prefix of %q, which serves the same queries", other.Name))
+			}
+		}
+	}
+	return issues
+}
+
+func isIdIndex(index models.Index) bool {
+	return index.IsDefault || index.Name == models.IndexDefaultName
+}
+
+func indexByName(indexes []models.Index, name string) models.Index {
+	for _, index := range indexes {
+		if index.Name == name {
+			return index
+		}
+	}
+	return models.Index{}
+}
+
+func sameOptions(index, other models.Index) bool {
+	return index.Options.IsUnique == other.Options.IsUnique &&
+		reflect.DeepEqual(index.Options.ExpireAfterSeconds, other.Options.ExpireAfterSeconds) &&
+		reflect.DeepEqual(index.Options.Collation, other.Options.Collation)
+}
+
+// matchPrefix tells whether keys start other with the same fields, in the same directions or
+// all reversed since an index can be walked both ways, and whether only reversed. Special types
+// must match exactly.
+func matchPrefix(keys, other []models.IndexKey) (bool, bool) {
+	if len(keys) > len(other) {
+		return false, false
+	}
+	isSame, isReversed := true, true
+	for i, key := range keys {
+		if key.Field != other[i].Field {
+			return false, false
+		}
+		direction, isNumber := toInt64(key.Value)
+		otherDirection, isOtherNumber := toInt64(other[i].Value)
+		if !isNumber || !isOtherNumber {
+			if !reflect.DeepEqual(key.Value, other[i].Value) {
+				return false, false
+			}
+			isReversed = false
+			continue
+		}
+		isSame = isSame && direction == otherDirection
+		isReversed = isReversed && direction == -otherDirection
+	}
+	return isSame || isReversed, !isSame && isReversed
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
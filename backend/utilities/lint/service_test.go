@@ -0,0 +1,216 @@
+package lint
+
+import (
+	"slices"
+	"testing"
+
+	"doctor-manager-api/database/mongo/models"
+)
+
+func index(name string, keys []models.IndexKey, options models.IndexOption) models.Index {
+	return models.Index{Collection: "orders", Name: name, Keys: keys, Options: options}
+}
+
+func keys(fieldValues ...interface{}) []models.IndexKey {
+	result := make([]models.IndexKey, 0, len(fieldValues)/2)
+	for i := 0; i+1 < len(fieldValues); i += 2 {
+		result = append(result, models.IndexKey{Field: fieldValues[i].(string), Value: fieldValues[i+1]})
+	}
+	return result
+}
+
+func TestLint(t *testing.T) {
+	expireAfterSeconds := int32(3600)
+	manyKeys := make([]interface{}, 0, 2*(maxKeys+1))
+	for i := 0; i <= maxKeys; i++ {
+		manyKeys = append(manyKeys, string(rune('a'+i%26))+string(rune('a'+i/26)), 1)
+	}
+	tests := []struct {
+		name    string
+		indexes []models.Index
+		want    []string
+	}{
+		{
+			name: "distinct indexes",
+			indexes: []models.Index{
+				index("a_1", keys("a", 1), models.IndexOption{}),
+				index("b_1", keys("b", 1), models.IndexOption{}),
+			},
+		},
+		{
+			name: "prefix",
+			indexes: []models.Index{
+				index("a_1", keys("a", 1), models.IndexOption{}),
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+			},
+			want: []string{RulePrefixRedundant + " a_1"},
+		},
+		{
+			name: "reversed prefix",
+			indexes: []models.Index{
+				index("a_-1", keys("a", -1), models.IndexOption{}),
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+			},
+			want: []string{RulePrefixRedundant + " a_-1"},
+		},
+		{
+			name: "unique prefix kept",
+			indexes: []models.Index{
+				index("a_1", keys("a", 1), models.IndexOption{IsUnique: true}),
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+			},
+		},
+		{
+			name: "prefix under another collation kept",
+			indexes: []models.Index{
+				index("a_1", keys("a", 1), models.IndexOption{Collation: &models.Collation{Locale: "fr"}}),
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+			},
+		},
+		{
+			name: "duplicate",
+			indexes: []models.Index{
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+				index("ab", keys("a", 1, "b", 1), models.IndexOption{}),
+			},
+			want: []string{RuleDuplicate + " a_1_b_1"},
+		},
+		{
+			name: "duplicate with numbers of other types",
+			indexes: []models.Index{
+				index("a_1", keys("a", int32(1)), models.IndexOption{}),
+				index("a", keys("a", float64(1)), models.IndexOption{}),
+			},
+			want: []string{RuleDuplicate + " a_1"},
+		},
+		{
+			name: "reversed keys",
+			indexes: []models.Index{
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+				index("a_-1_b_-1", keys("a", -1, "b", -1), models.IndexOption{}),
+			},
+			want: []string{RuleReversed + " a_1_b_1"},
+		},
+		{
+			name: "mixed directions",
+			indexes: []models.Index{
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+				index("a_1_b_-1", keys("a", 1, "b", -1), models.IndexOption{}),
+			},
+		},
+		{
+			name: "same keys, other options",
+			indexes: []models.Index{
+				index("a_1", keys("a", 1), models.IndexOption{}),
+				index("a_1_ttl", keys("a", 1), models.IndexOption{ExpireAfterSeconds: &expireAfterSeconds}),
+			},
+		},
+		{
+			name: "unique duplicate",
+			indexes: []models.Index{
+				index("a_1", keys("a", 1), models.IndexOption{}),
+				index("a_1_unique", keys("a", 1), models.IndexOption{IsUnique: true}),
+			},
+			want: []string{RuleUniqueDuplicate + " a_1"},
+		},
+		{
+			name: "special types must match",
+			indexes: []models.Index{
+				index("a_hashed", keys("a", "hashed"), models.IndexOption{}),
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+			},
+		},
+		{
+			name: "ttl on a compound index",
+			indexes: []models.Index{
+				index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{ExpireAfterSeconds: &expireAfterSeconds}),
+			},
+			want: []string{RuleTtlCompound + " a_1_b_1"},
+		},
+		{
+			name: "ttl on _id",
+			indexes: []models.Index{
+				index("_id_ttl", keys("_id", 1), models.IndexOption{ExpireAfterSeconds: &expireAfterSeconds}),
+			},
+			want: []string{RuleTtlOnId + " _id_ttl"},
+		},
+		{
+			name: "two text indexes",
+			indexes: []models.Index{
+				index("title_text", keys("title", "text"), models.IndexOption{}),
+				index("body_text", keys("body", "text"), models.IndexOption{}),
+			},
+			want: []string{RuleTextCollision + " title_text", RuleTextCollision + " body_text"},
+		},
+		{
+			name:    "too many keys",
+			indexes: []models.Index{index("many", keys(manyKeys...), models.IndexOption{})},
+			want:    []string{RuleTooManyKeys + " many"},
+		},
+		{
+			name: "too many indexes",
+			indexes: []models.Index{
+				index("a_1", keys("a", 1), models.IndexOption{}),
+				index("b_1", keys("b", 1), models.IndexOption{}),
+				index("c_1", keys("c", 1), models.IndexOption{}),
+			},
+			want: []string{RuleTooManyIndexes + " "},
+		},
+	}
+	s, err := New(Option{MaxIndexes: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, issue := range s.Lint(test.indexes) {
+				got = append(got, issue.Rule+" "+issue.Index)
+			}
+			if !slices.Equal(got, test.want) && (len(got) != 0 || len(test.want) != 0) {
+				t.Errorf("Lint() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLintSeverities(t *testing.T) {
+	indexes := []models.Index{
+		index("a_1", keys("a", 1), models.IndexOption{}),
+		index("a_1_b_1", keys("a", 1, "b", 1), models.IndexOption{}),
+		index("a_-1_b_-1", keys("a", -1, "b", -1), models.IndexOption{}),
+	}
+	s, err := New(Option{Severities: map[string]string{RulePrefixRedundant: SeverityError, RuleReversed: SeverityOff}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := s.Lint(indexes)
+	if len(issues) != 2 {
+		t.Fatalf("Lint() = %+v, want the prefix of both compound indexes only", issues)
+	}
+	for _, issue := range issues {
+		if issue.Rule != RulePrefixRedundant || issue.Severity != SeverityError {
+			t.Errorf("issue %+v, want %s at %s", issue, RulePrefixRedundant, SeverityError)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		severities map[string]string
+		isErr      bool
+	}{
+		{name: "defaults"},
+		{name: "override", severities: map[string]string{RuleDuplicate: SeverityWarning}},
+		{name: "unknown rule", severities: map[string]string{"unknown": SeverityWarning}, isErr: true},
+		{name: "unknown severity", severities: map[string]string{RuleDuplicate: "fatal"}, isErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(Option{Severities: test.severities}); (err != nil) != test.isErr {
+				t.Errorf("New() error = %v, want error %v", err, test.isErr)
+			}
+		})
+	}
+}
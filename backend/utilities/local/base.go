@@ -16,13 +16,19 @@ type Service interface {
 	SetStatusCode(value int)
 	SetTokenId(value primitive.ObjectID)
 	GetTokenId() primitive.ObjectID
+	SetServiceAccount(serviceAccount models.ServiceAccount)
+	GetServiceAccount() (models.ServiceAccount, bool)
+	SetScopes(scopes []string)
+	GetScopes() []string
 }
 
 const (
-	KeyTokenId    = "tokenId"
-	KeyUser       = "user"
-	KeyExtraBody  = "extraBody"
-	KeyStatusCode = "statusCode"
+	KeyTokenId        = "tokenId"
+	KeyUser           = "user"
+	KeyExtraBody      = "extraBody"
+	KeyStatusCode     = "statusCode"
+	KeyServiceAccount = "serviceAccount"
+	KeyScopes         = "scopes"
 )
 
 type service struct {
@@ -50,3 +50,23 @@ func (s service) GetTokenId() primitive.ObjectID {
 	}
 	return primitive.NilObjectID
 }
+
+func (s service) SetServiceAccount(value models.ServiceAccount) {
+	s.context.Locals(KeyServiceAccount, value)
+}
+
+func (s service) GetServiceAccount() (models.ServiceAccount, bool) {
+	value, ok := s.context.Locals(KeyServiceAccount).(models.ServiceAccount)
+	return value, ok
+}
+
+func (s service) SetScopes(value []string) {
+	s.context.Locals(KeyScopes, value)
+}
+
+func (s service) GetScopes() []string {
+	if value, ok := s.context.Locals(KeyScopes).([]string); ok {
+		return value
+	}
+	return nil
+}
@@ -2,16 +2,17 @@ package mongodb
 
 import (
 	"context"
-	"fmt"
-	"slices"
-	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"doctor-manager-api/common/logging"
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/utilities/indexspec"
 )
 
 const defaultContextTimeout = 20 * time.Second
@@ -20,13 +21,32 @@ var logger = logging.GetLogger()
 
 type Service interface {
 	TestConnection(uri string) error
+	ListDatabases() (databases []DatabaseInfo, err error)
 	GetIndexesByDbNameAndCollections(dbName string, collections []string) (indexes []Index, err error)
 	GetIndexesByDbName(dbName string) (indexes []Index, err error)
 	RemoveIndexes(dbName string, indexes []Index) error
 	CreateIndexes(dbName string, indexes []Index) error
+	GetIndexStats(dbName string, collections []string) (stats []IndexStat, unavailable []UnavailableMember, err error)
+	GetCollectionStats(dbName string, collections []string) (stats []CollectionStat, err error)
+	GetStorageStat(dbName string) (stat StorageStat, err error)
+	GetCollectionInfos(dbName string, collections []string) (infos []CollectionInfo, err error)
+	GetProfileEntries(dbName string, opt ProfileOption) (entries []bson.D, err error)
+	GetIndexBuilds(dbName string, collections []string) (builds []IndexBuild, err error)
+	FindUniqueViolations(dbName string, indexes []Index, limit int64) (violations []UniqueViolation, err error)
+	GetShardKeys(dbName string, collections []string) (shardKeys []ShardKey, err error)
+	GetShardIndexes(dbName string, collections []string) (shardIndexes []ShardIndexes, err error)
+	SampleDocuments(dbName, collection string, size int64) (documents []bson.Raw, err error)
+	Explain(dbName, collection string, query ExplainQuery) (result ExplainResult, err error)
+	SetIndexesHidden(dbName string, indexes []Index, isHidden bool) error
+	Ping() error
+	Disconnect() error
 }
 type service struct {
-	client *mongo.Client
+	client        *mongo.Client
+	memberClients map[string]*mongo.Client
+	connectOption ConnectOption
+	uri           string
+	memberMutex   sync.Mutex
 }
 
 type Index struct {
@@ -38,84 +58,52 @@ type Index struct {
 	KeySignature string      `bson:"key_signature"`
 	Keys         []IndexKey  `bson:"keys"`
 	IsText       bool        `bson:"is_text"`
+	IsHidden     bool        `bson:"is_hidden"`
 }
 
-type Collation struct {
-	Strength        *int   `bson:"strength,omitempty" json:"strength,omitempty"`
-	CaseLevel       *bool  `bson:"case_level,omitempty" json:"case_level,omitempty"`
-	NumericOrdering *bool  `bson:"numeric_ordering,omitempty" json:"numeric_ordering,omitempty"`
-	Locale          string `bson:"locale" json:"locale"`
-	CaseFirst       string `bson:"case_first,omitempty" json:"case_first,omitempty"`
+// DatabaseInfo is a database of the cluster as reported by listDatabases.
+type DatabaseInfo struct {
+	Name       string
+	SizeOnDisk int64
+	IsEmpty    bool
 }
 
-type IndexOption struct {
-	ExpireAfterSeconds *int32                 `bson:"expire_after_seconds"`
-	Collation          *Collation             `bson:"collation,omitempty"`
-	Weights            map[string]interface{} `bson:"weights,omitempty"`
-	DefaultLanguage    string                 `bson:"default_language,omitempty"`
-	IsUnique           bool                   `bson:"is_unique"`
-}
+type (
+	Collation   = indexspec.Collation
+	IndexOption = indexspec.Option
+	IndexKey    = indexspec.Key
+)
 
-type IndexKey struct {
-	Value interface{} `bson:"value"`
-	Field string      `bson:"field"`
+func (m *Index) Spec() indexspec.Spec {
+	return indexspec.Spec{Options: m.Options, Keys: m.Keys}
 }
 
 func (m *Index) GetKeySignature() string {
-	if len(m.Keys) == 0 {
-		return ""
-	}
-	var keyString string
-	keys := m.Keys
-	slices.SortFunc(m.Keys, func(keyFirst, keySecond IndexKey) int {
-		return strings.Compare(keyFirst.Field, keySecond.Field)
-	})
-	for _, key := range keys {
-		if m.IsText && (key.Field == "_fts" || key.Field == "_ftsx") {
-			continue
-		}
-		switch v := key.Value.(type) {
-		case int32:
-			keyString += fmt.Sprintf("%s_%d_", key.Field, v)
-		case string:
-			keyString += fmt.Sprintf("%s_%s_", key.Field, v)
-		default:
-			keyString += fmt.Sprintf("%s_%v_", key.Field, v)
-		}
-	}
-	if m.IsText {
-		keyString += "text_"
-		if m.Options.DefaultLanguage != "" {
-			keyString += fmt.Sprintf("default_language_%s_", m.Options.DefaultLanguage)
-		}
-		if len(m.Options.Weights) > 0 {
-			for k, v := range m.Options.Weights {
-				keyString += fmt.Sprintf("weights_%s_%v_", k, v)
-			}
-		}
-	}
-	if m.Options.Collation != nil && m.Options.Collation.Locale != "" {
-		keyString += fmt.Sprintf("collation_locale_%s_", m.Options.Collation.Locale)
-		if m.Options.Collation.Strength != nil {
-			keyString += fmt.Sprintf("strength_%d_", *m.Options.Collation.Strength)
-		}
+	return m.Spec().Signature()
+}
+
+// ConflictsWith tells whether the server would refuse to build other next to the index: in the
+// same collection, with the same name, the same key pattern and collation, or a second text part.
+func (m *Index) ConflictsWith(other Index) bool {
+	if m.Collection != other.Collection {
+		return false
 	}
-	if m.Options.IsUnique {
-		keyString += "unique_"
+	if m.Name == other.Name || (indexspec.IsText(m.Keys) && indexspec.IsText(other.Keys)) {
+		return true
 	}
-	if m.Options.ExpireAfterSeconds != nil {
-		keyString += fmt.Sprintf("expireAfterSeconds_%d", *m.Options.ExpireAfterSeconds)
-	}
-	return keyString
+	return m.keyPatternSignature() == other.keyPatternSignature()
+}
+
+// keyPatternSignature is the signature of the keys and collation of the index, options the
+// server allows only once per collection.
+func (m *Index) keyPatternSignature() string {
+	spec := indexspec.Spec{Keys: m.Keys, Options: indexspec.Option{Collation: m.Options.Collation}}
+	return spec.Signature()
 }
 
 func (m *Index) toIndexModel() mongo.IndexModel {
-	keys := bson.D{}
-	for _, key := range m.Keys {
-		keys = append(keys, bson.E{Key: key.Field, Value: key.Value})
-	}
 	result := mongo.IndexModel{
-		Keys:    keys,
+		Keys:    m.Spec().KeyDocument(),
 		Options: options.Index(),
 	}
 	if m.Options.ExpireAfterSeconds != nil {
@@ -140,6 +128,15 @@ func (m *Index) toIndexModel() mongo.IndexModel {
 		if m.Options.Collation.NumericOrdering != nil {
 			collation.NumericOrdering = *m.Options.Collation.NumericOrdering
 		}
+		if m.Options.Collation.Alternate != "" {
+			collation.Alternate = m.Options.Collation.Alternate
+		}
+		if m.Options.Collation.MaxVariable != "" {
+			collation.MaxVariable = m.Options.Collation.MaxVariable
+		}
+		if m.Options.Collation.Backwards != nil {
+			collation.Backwards = *m.Options.Collation.Backwards
+		}
 		result.Options.SetCollation(&collation)
 	}
 	if m.Options.DefaultLanguage != "" {
@@ -151,23 +148,89 @@ func (m *Index) toIndexModel() mongo.IndexModel {
 	return result
 }
 
-func New(uri string) (Service, error) {
+// ToModel turns a live index into a declared one of the database. The key signature is that of
+// the declared index, an unnamed index takes it as its name.
+func (m *Index) ToModel(databaseId primitive.ObjectID) models.Index {
+	normalized := m.Normalize()
+	index := models.Index{
+		Options:    normalized.Options,
+		Collection: m.Collection,
+		Name:       m.Name,
+		Keys:       normalized.Keys,
+		IsText:     m.IsText,
+		DatabaseId: databaseId,
+	}
+	if index.IsText && index.Options.DefaultLanguage == "" {
+		index.Options.DefaultLanguage = "none"
+	}
+	index.KeySignature = index.GetKeySignature()
+	if index.Name == "" {
+		index.Name = index.KeySignature
+	}
+	return index
+}
+
+// NewIndexFromModel turns a declared index into the live index it builds.
+func NewIndexFromModel(index models.Index) Index {
+	return Index{
+		Options:      index.Options,
+		Collection:   index.Collection,
+		Name:         index.Name,
+		KeySignature: index.KeySignature,
+		Keys:         index.Keys,
+		IsText:       index.IsText,
+	}.Normalize()
+}
+
+// Normalize returns a copy of the index with the numbers of its keys and weights normalized, as
+// needed after a JSON round trip, and its own collation.
+func (m Index) Normalize() Index {
+	spec := m.Spec().Normalize()
+	if spec.Options.Collation != nil {
+		collation := *spec.Options.Collation
+		spec.Options.Collation = &collation
+	}
+	m.Keys, m.Options = spec.Keys, spec.Options
+	return m
+}
+
+// New opens a dedicated client, which the caller must Disconnect. Clients of registered
+// databases should come from the Manager instead.
+func New(uri string, opts ...ConnectOption) (Service, error) {
+	opt := ConnectOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return connect(uri, 0, opt)
+}
+
+func connect(uri string, maxPoolSize uint64, connectOption ConnectOption) (*service, error) {
 	opts := options.Client()
 	opts.ApplyURI(uri)
+	if maxPoolSize > 0 {
+		opts.SetMaxPoolSize(maxPoolSize)
+	}
+	if err := connectOption.apply(opts); err != nil {
+		logger.Error().Err(err).Str("function", "connect").Str("functionInline", "connectOption.apply").Msg("mongodb")
+		return nil, err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
 	defer cancel()
 	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
-		logger.Error().Err(err).Str("function", "New").Str("functionInline", "mongo.Connect").Msg("mongodb")
+		logger.Error().Err(err).Str("function", "connect").Str("functionInline", "mongo.Connect").Msg("mongodb")
 		return nil, err
 	}
 	ctxPing, cancelPing := context.WithTimeout(context.Background(), defaultContextTimeout)
 	defer cancelPing()
 	if err = client.Ping(ctxPing, nil); err != nil {
-		logger.Error().Err(err).Str("function", "New").Str("functionInline", "client.Ping").Msg("mongodb")
+		logger.Error().Err(err).Str("function", "connect").Str("functionInline", "client.Ping").Msg("mongodb")
+		_ = client.Disconnect(context.Background())
 		return nil, err
 	}
 	return &service{
-		client: client,
+		client:        client,
+		connectOption: connectOption,
+		uri:           uri,
 	}, nil
 }
@@ -0,0 +1,69 @@
+package mongodb
+
+import "testing"
+
+// TestConflictsWith covers the redefinitions a sync in the hide removal mode must drop the
+// hidden index for, the server refusing to build them next to it.
+func TestConflictsWith(t *testing.T) {
+	strength := 2
+	live := func(name string, keys ...IndexKey) Index {
+		return Index{Collection: "orders", Name: name, Keys: keys, IsHidden: true}
+	}
+	tests := []struct {
+		name        string
+		index       Index
+		other       Index
+		isConflicts bool
+	}{
+		{
+			name:        "redefined under the same name",
+			index:       live("status", IndexKey{Field: "status", Value: int32(1)}),
+			other:       live("status", IndexKey{Field: "status", Value: 1.0}, IndexKey{Field: "created_at", Value: -1}),
+			isConflicts: true,
+		},
+		{
+			name:  "same keys, new options",
+			index: live("status_1", IndexKey{Field: "status", Value: int32(1)}),
+			other: Index{
+				Collection: "orders",
+				Name:       "status_unique",
+				Keys:       []IndexKey{{Field: "status", Value: 1.0}},
+				Options:    IndexOption{IsUnique: true},
+			},
+			isConflicts: true,
+		},
+		{
+			name:  "same keys, other collation",
+			index: live("status_1", IndexKey{Field: "status", Value: int32(1)}),
+			other: Index{
+				Collection: "orders",
+				Name:       "status_fr",
+				Keys:       []IndexKey{{Field: "status", Value: 1}},
+				Options:    IndexOption{Collation: &Collation{Locale: "fr", Strength: &strength}},
+			},
+		},
+		{
+			name:        "second text index",
+			index:       live("title_text", IndexKey{Field: "_fts", Value: "text"}, IndexKey{Field: "_ftsx", Value: int32(1)}),
+			other:       live("body_text", IndexKey{Field: "body", Value: "text"}),
+			isConflicts: true,
+		},
+		{
+			name:  "other keys",
+			index: live("status_1", IndexKey{Field: "status", Value: int32(1)}),
+			other: live("status_-1", IndexKey{Field: "status", Value: -1}),
+		},
+		{
+			name:  "other collection",
+			index: live("status_1", IndexKey{Field: "status", Value: int32(1)}),
+			other: Index{Collection: "users", Name: "status_1", Keys: []IndexKey{{Field: "status", Value: 1}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.index.ConflictsWith(test.other); got != test.isConflicts {
+				t.Errorf("ConflictsWith() = %v, want %v", got, test.isConflicts)
+			}
+		})
+	}
+}
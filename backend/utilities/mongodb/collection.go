@@ -0,0 +1,154 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"doctor-manager-api/utilities/indexspec"
+)
+
+const (
+	CollectionTypeCollection = "collection"
+	CollectionTypeView       = "view"
+	CollectionTypeTimeseries = "timeseries"
+)
+
+const commandNotSupportedOnViewCode = 166
+
+// CollectionInfo is the type and options of a collection as reported by listCollections.
+// ExpireAfterSeconds is the collection level expiry of time-series and clustered collections.
+type CollectionInfo struct {
+	Timeseries         *TimeseriesOption
+	ExpireAfterSeconds *int64
+	Name               string
+	Type               string
+	IsCapped           bool
+	IsClustered        bool
+}
+
+type TimeseriesOption struct {
+	TimeField   string
+	MetaField   string
+	Granularity string
+}
+
+type collectionInfoDocument struct {
+	Name    string `bson:"name"`
+	Type    string `bson:"type"`
+	Options struct {
+		ClusteredIndex     interface{} `bson:"clusteredIndex"`
+		ExpireAfterSeconds *int64      `bson:"expireAfterSeconds"`
+		Timeseries         *struct {
+			TimeField   string `bson:"timeField"`
+			MetaField   string `bson:"metaField"`
+			Granularity string `bson:"granularity"`
+		} `bson:"timeseries"`
+		Capped bool `bson:"capped"`
+	} `bson:"options"`
+}
+
+// GetCollectionInfos lists the collections among collections, every collection of the database
+// when empty, sorted by name. System namespaces are left out, views are kept.
+func (s *service) GetCollectionInfos(dbName string, collections []string) ([]CollectionInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	return s.listCollectionInfos(ctx, dbName, collections)
+}
+
+func (s *service) listCollectionInfos(ctx context.Context, dbName string, collections []string) ([]CollectionInfo, error) {
+	filter := bson.M{}
+	if len(collections) > 0 {
+		filter["name"] = bson.M{"$in": collections}
+	}
+	cursor, err := s.client.Database(dbName).ListCollections(ctx, filter)
+	if err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "listCollectionInfos").Str("functionInline", "db.ListCollections").Msg("mongodb")
+		return nil, err
+	}
+	var documents []collectionInfoDocument
+	if err = cursor.All(ctx, &documents); err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "listCollectionInfos").Str("functionInline", "cursor.All").Msg("mongodb")
+		return nil, err
+	}
+	infos := make([]CollectionInfo, 0, len(documents))
+	for _, document := range documents {
+		if isSystemCollection(document.Name) {
+			continue
+		}
+		info := CollectionInfo{
+			ExpireAfterSeconds: document.Options.ExpireAfterSeconds,
+			Name:               document.Name,
+			Type:               document.Type,
+			IsCapped:           document.Options.Capped,
+		}
+		if info.Type == "" {
+			info.Type = CollectionTypeCollection
+		}
+		// clusteredIndex is a document on clustered collections and true on the buckets of
+		// time-series collections.
+		if clusteredIndex, isBool := document.Options.ClusteredIndex.(bool); document.Options.ClusteredIndex != nil && (!isBool || clusteredIndex) {
+			info.IsClustered = info.Type == CollectionTypeCollection
+		}
+		if document.Options.Timeseries != nil {
+			info.Timeseries = &TimeseriesOption{
+				TimeField:   document.Options.Timeseries.TimeField,
+				MetaField:   document.Options.Timeseries.MetaField,
+				Granularity: document.Options.Timeseries.Granularity,
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// CheckIndex returns why the index cannot be built on the collection, empty when it can.
+func (c CollectionInfo) CheckIndex(index Index) string {
+	switch {
+	case c.Type == CollectionTypeView:
+		return "views cannot have indexes"
+	case c.Type == CollectionTypeTimeseries && index.Options.IsUnique:
+		return "time-series collections do not support unique indexes"
+	case c.Type == CollectionTypeTimeseries && (index.IsText || indexspec.IsText(index.Keys)):
+		return "time-series collections do not support text indexes"
+	case c.Type == CollectionTypeTimeseries && index.Options.ExpireAfterSeconds != nil:
+		return "time-series collections expire documents with their expireAfterSeconds option, not a TTL index"
+	case c.IsCapped && index.Options.ExpireAfterSeconds != nil:
+		return "capped collections do not support TTL indexes"
+	}
+	return ""
+}
+
+// IsBuiltinIndex tells whether the server created the index along with the collection, as the
+// metaField and timeField index of a time-series collection. Such an index is never dropped.
+func (c CollectionInfo) IsBuiltinIndex(index Index) bool {
+	if c.Timeseries == nil || c.Timeseries.MetaField == "" || len(index.Keys) != 2 || index.Options.IsUnique {
+		return false
+	}
+	fields := make(map[string]struct{}, 2)
+	for _, key := range index.Keys {
+		if value, ok := toFloat64(key.Value); !ok || value != 1 {
+			return false
+		}
+		fields[key.Field] = struct{}{}
+	}
+	_, hasMetaField := fields[c.Timeseries.MetaField]
+	_, hasTimeField := fields[c.Timeseries.TimeField]
+	return hasMetaField && hasTimeField
+}
+
+func isSystemCollection(name string) bool {
+	return strings.HasPrefix(name, "system.")
+}
+
+func isCommandNotSupportedOnView(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(commandNotSupportedOnViewCode)
+}
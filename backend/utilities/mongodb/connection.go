@@ -0,0 +1,154 @@
+package mongodb
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"doctor-manager-api/database/mongo/models"
+	"doctor-manager-api/utilities/encryption"
+)
+
+const awsSessionTokenProperty = "AWS_SESSION_TOKEN"
+
+// ConnectOption carries the connection settings that cannot be expressed in the uri.
+// Secrets are in clear, see ConnectOptionFromDatabase.
+type ConnectOption struct {
+	Tls  *TlsOption
+	Auth *AuthOption
+}
+
+type TlsOption struct {
+	CaCertificate        string
+	ClientCertificate    string
+	ClientKey            string
+	IsEnabled            bool
+	IsInsecureSkipVerify bool
+}
+
+type AuthOption struct {
+	Mechanism       string
+	Source          string
+	Username        string
+	Password        string
+	AwsSessionToken string
+}
+
+// Validate checks the PEM material without connecting.
+func (o ConnectOption) Validate() error {
+	if o.Tls != nil && o.Tls.IsEnabled {
+		if _, err := o.Tls.config(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o ConnectOption) apply(opts *options.ClientOptions) error {
+	if o.Tls != nil && o.Tls.IsEnabled {
+		tlsConfig, err := o.Tls.config()
+		if err != nil {
+			return err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+	if o.Auth != nil {
+		credential := options.Credential{
+			AuthMechanism: o.Auth.Mechanism,
+			AuthSource:    o.Auth.Source,
+			Username:      o.Auth.Username,
+			Password:      o.Auth.Password,
+			PasswordSet:   o.Auth.Password != "",
+		}
+		if o.Auth.AwsSessionToken != "" {
+			credential.AuthMechanismProperties = map[string]string{awsSessionTokenProperty: o.Auth.AwsSessionToken}
+		}
+		opts.SetAuth(credential)
+	}
+	return nil
+}
+
+// fingerprint identifies a uri and its settings, so a cached client is replaced when
+// any of them changes.
+func (o ConnectOption) fingerprint(uri string) string {
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%q", uri)
+	if o.Tls != nil {
+		_, _ = fmt.Fprintf(hash, "|tls:%t:%t:%q:%q:%q", o.Tls.IsEnabled, o.Tls.IsInsecureSkipVerify, o.Tls.CaCertificate, o.Tls.ClientCertificate, o.Tls.ClientKey)
+	}
+	if o.Auth != nil {
+		_, _ = fmt.Fprintf(hash, "|auth:%q:%q:%q:%q:%q", o.Auth.Mechanism, o.Auth.Source, o.Auth.Username, o.Auth.Password, o.Auth.AwsSessionToken)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (o *TlsOption) config() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Lab clusters often run with self-signed certificates, this is an explicit opt-in.
+		InsecureSkipVerify: o.IsInsecureSkipVerify, //nolint:gosec
+	}
+	if o.CaCertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(o.CaCertificate)) {
+			return nil, errors.New("ca certificate is not a valid PEM bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if o.ClientCertificate != "" || o.ClientKey != "" {
+		certificate, err := tls.X509KeyPair([]byte(o.ClientCertificate), []byte(o.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("client certificate or key is invalid: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+// ConnectOptionFromDatabase decrypts the TLS and auth settings of a database record.
+func ConnectOptionFromDatabase(database *models.Database) (ConnectOption, error) {
+	var (
+		opt     ConnectOption
+		secrets []*string
+	)
+	if database.Tls != nil {
+		opt.Tls = &TlsOption{
+			CaCertificate:        database.Tls.CaCertificate,
+			ClientCertificate:    database.Tls.ClientCertificate,
+			ClientKey:            database.Tls.ClientKey,
+			IsEnabled:            database.Tls.IsEnabled,
+			IsInsecureSkipVerify: database.Tls.IsInsecureSkipVerify,
+		}
+		secrets = append(secrets, &opt.Tls.CaCertificate, &opt.Tls.ClientCertificate, &opt.Tls.ClientKey)
+	}
+	if database.Auth != nil {
+		opt.Auth = &AuthOption{
+			Mechanism:       database.Auth.Mechanism,
+			Source:          database.Auth.Source,
+			Username:        database.Auth.Username,
+			Password:        database.Auth.Password,
+			AwsSessionToken: database.Auth.AwsSessionToken,
+		}
+		secrets = append(secrets, &opt.Auth.Password, &opt.Auth.AwsSessionToken)
+	}
+	for _, secret := range secrets {
+		if *secret == "" {
+			continue
+		}
+		encryptionService := encryption.GetGlobal()
+		if encryptionService == nil {
+			return opt, errors.New("DATA_ENCRYPTION_KEY is not configured")
+		}
+		plaintext, err := encryptionService.Decrypt(*secret)
+		if err != nil {
+			return opt, err
+		}
+		*secret = plaintext
+	}
+	return opt, nil
+}
@@ -0,0 +1,131 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// duplicateCheckTimeout bounds the scan of one collection, which reads every document.
+const duplicateCheckTimeout = 10 * time.Minute
+
+// DuplicateKey is a key value shared by Count documents. Values follow the index keys order,
+// a missing field is null as in the index.
+type DuplicateKey struct {
+	Values bson.D
+	Count  int64
+}
+
+// UniqueViolation lists the duplicate keys that make a unique index fail to build.
+type UniqueViolation struct {
+	Collection string
+	Name       string
+	Duplicates []DuplicateKey
+}
+
+func (v UniqueViolation) String() string {
+	parts := make([]string, len(v.Duplicates))
+	for i, duplicate := range v.Duplicates {
+		values := make([]string, len(duplicate.Values))
+		for j, value := range duplicate.Values {
+			values[j] = fmt.Sprintf("%s: %v", value.Key, value.Value)
+		}
+		parts[i] = fmt.Sprintf("{%s} x%d", strings.Join(values, ", "), duplicate.Count)
+	}
+	return fmt.Sprintf("%s.%s: %s", v.Collection, v.Name, strings.Join(parts, ", "))
+}
+
+// FindUniqueViolations groups the documents of each unique index by key, with the index
+// collation, and returns up to limit duplicate keys per index, the most repeated first.
+// Non-unique indexes are skipped. Array values are unwound into one key per element as a
+// multikey index would, a key repeated within a document counting once.
+func (s *service) FindUniqueViolations(dbName string, indexes []Index, limit int64) ([]UniqueViolation, error) {
+	violations := make([]UniqueViolation, 0)
+	for _, index := range indexes {
+		if !index.Options.IsUnique || len(index.Keys) == 0 {
+			continue
+		}
+		project := bson.D{}
+		group := bson.D{}
+		for i, key := range index.Keys {
+			name := fmt.Sprintf("k%d", i)
+			project = append(project, bson.E{Key: name, Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + key.Field, nil}}}})
+			group = append(group, bson.E{Key: name, Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + name, nil}}}})
+		}
+		pipeline := mongo.Pipeline{{{Key: "$project", Value: project}}}
+		for i := range index.Keys {
+			pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: bson.D{
+				{Key: "path", Value: fmt.Sprintf("$k%d", i)},
+				{Key: "preserveNullAndEmptyArrays", Value: true},
+			}}})
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: bson.D{{Key: "key", Value: group}, {Key: "document", Value: "$_id"}}},
+			}}},
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: "$_id.key"},
+				{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			}}},
+			bson.D{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+		)
+		if limit > 0 {
+			pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+		}
+		optAggregate := options.Aggregate().SetAllowDiskUse(true)
+		if collation := index.toIndexModel().Options.Collation; collation != nil {
+			optAggregate.SetCollation(collation)
+		}
+		duplicates, err := s.aggregateDuplicates(dbName, index, pipeline, optAggregate)
+		if err != nil {
+			return nil, err
+		}
+		if len(duplicates) > 0 {
+			violations = append(violations, UniqueViolation{
+				Collection: index.Collection,
+				Name:       index.Name,
+				Duplicates: duplicates,
+			})
+		}
+	}
+	return violations, nil
+}
+
+func (s *service) aggregateDuplicates(dbName string, index Index, pipeline mongo.Pipeline, optAggregate *options.AggregateOptions) ([]DuplicateKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), duplicateCheckTimeout)
+	defer cancel()
+	cursor, err := s.client.Database(dbName).Collection(index.Collection).Aggregate(ctx, pipeline, optAggregate)
+	if err != nil {
+		logger.Error().Err(err).Str("collection", index.Collection).Str("function", "aggregateDuplicates").Str("functionInline", "coll.Aggregate").Msg("mongodb")
+		return nil, err
+	}
+	var results []struct {
+		Id    bson.D `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		logger.Error().Err(err).Str("collection", index.Collection).Str("function", "aggregateDuplicates").Str("functionInline", "cursor.All").Msg("mongodb")
+		return nil, err
+	}
+	duplicates := make([]DuplicateKey, len(results))
+	for i, result := range results {
+		values := make(bson.D, len(index.Keys))
+		for j, key := range index.Keys {
+			values[j] = bson.E{Key: key.Field}
+			if j < len(result.Id) {
+				values[j].Value = result.Id[j].Value
+			}
+		}
+		duplicates[i] = DuplicateKey{
+			Values: values,
+			Count:  result.Count,
+		}
+	}
+	return duplicates, nil
+}
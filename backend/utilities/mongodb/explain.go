@@ -0,0 +1,152 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	stageCollScan = "COLLSCAN"
+	stageSort     = "SORT"
+)
+
+const (
+	VerbosityExecutionStats = "executionStats"
+	VerbosityQueryPlanner   = "queryPlanner"
+)
+
+// ExplainQuery is a find of the collection, Sort and Projection being left out when empty.
+// IsPlanOnly explains with the queryPlanner verbosity, the query is planned but not run and the
+// execution stats of the result stay zero.
+type ExplainQuery struct {
+	Filter     bson.D
+	Sort       bson.D
+	Projection bson.D
+	IsPlanOnly bool
+}
+
+// ExplainResult is the winning plan of a query and what running it cost. Stages lists the
+// stages of the plan from the root, IndexNames the indexes it reads, in the same order.
+type ExplainResult struct {
+	Stages          []string
+	IndexNames      []string
+	KeysExamined    int64
+	DocsExamined    int64
+	Returned        int64
+	Millis          int64
+	IsCollScan      bool
+	HasBlockingSort bool
+}
+
+// explainStage is a stage of a winning plan. The slot based engine nests the classic plan
+// under queryPlan, and through mongos each shard reports its own winning plan.
+type explainStage struct {
+	QueryPlan   *explainStage  `bson:"queryPlan"`
+	InputStage  *explainStage  `bson:"inputStage"`
+	Stage       string         `bson:"stage"`
+	IndexName   string         `bson:"indexName"`
+	InputStages []explainStage `bson:"inputStages"`
+	Shards      []struct {
+		WinningPlan explainStage `bson:"winningPlan"`
+	} `bson:"shards"`
+}
+
+type explainDocument struct {
+	QueryPlanner struct {
+		WinningPlan explainStage `bson:"winningPlan"`
+	} `bson:"queryPlanner"`
+	ExecutionStats struct {
+		Returned     int64 `bson:"nReturned"`
+		Millis       int64 `bson:"executionTimeMillis"`
+		KeysExamined int64 `bson:"totalKeysExamined"`
+		DocsExamined int64 `bson:"totalDocsExamined"`
+	} `bson:"executionStats"`
+}
+
+// Explain runs the query with the executionStats verbosity, the server executes it to the end
+// and the plan reflects the indexes visible at that time. A plan only query is not executed.
+func (s *service) Explain(dbName, collection string, query ExplainQuery) (ExplainResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	find := bson.D{
+		{Key: "find", Value: collection},
+		{Key: "filter", Value: query.Filter},
+	}
+	if len(query.Sort) > 0 {
+		find = append(find, bson.E{Key: "sort", Value: query.Sort})
+	}
+	if len(query.Projection) > 0 {
+		find = append(find, bson.E{Key: "projection", Value: query.Projection})
+	}
+	verbosity := VerbosityExecutionStats
+	if query.IsPlanOnly {
+		verbosity = VerbosityQueryPlanner
+	}
+	var document explainDocument
+	if err := s.client.Database(dbName).RunCommand(ctx, bson.D{
+		{Key: "explain", Value: find},
+		{Key: "verbosity", Value: verbosity},
+	}).Decode(&document); err != nil {
+		logger.Error().Err(err).Str("collection", collection).Str("function", "Explain").Str("functionInline", "db.RunCommand").Msg("mongodb")
+		return ExplainResult{}, err
+	}
+	result := ExplainResult{
+		Stages:       make([]string, 0),
+		IndexNames:   make([]string, 0),
+		KeysExamined: document.ExecutionStats.KeysExamined,
+		DocsExamined: document.ExecutionStats.DocsExamined,
+		Returned:     document.ExecutionStats.Returned,
+		Millis:       document.ExecutionStats.Millis,
+	}
+	result.walk(&document.QueryPlanner.WinningPlan)
+	return result, nil
+}
+
+func (r *ExplainResult) walk(stage *explainStage) {
+	if stage == nil {
+		return
+	}
+	if stage.Stage != "" {
+		r.Stages = append(r.Stages, stage.Stage)
+	}
+	switch stage.Stage {
+	case stageCollScan:
+		r.IsCollScan = true
+	case stageSort:
+		r.HasBlockingSort = true
+	}
+	if stage.IndexName != "" {
+		r.IndexNames = append(r.IndexNames, stage.IndexName)
+	}
+	r.walk(stage.QueryPlan)
+	r.walk(stage.InputStage)
+	for i := range stage.InputStages {
+		r.walk(&stage.InputStages[i])
+	}
+	for i := range stage.Shards {
+		r.walk(&stage.Shards[i].WinningPlan)
+	}
+}
+
+// SetIndexesHidden hides the indexes from the query planner, or unhides them, with collMod.
+// A hidden index is still maintained on writes and enforces its unique constraint, so the
+// change is instant both ways. Hidden indexes need MongoDB 4.4.
+func (s *service) SetIndexesHidden(dbName string, indexes []Index, isHidden bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	db := s.client.Database(dbName)
+	for _, index := range indexes {
+		if err := db.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: index.Collection},
+			{Key: "index", Value: bson.D{
+				{Key: "name", Value: index.Name},
+				{Key: "hidden", Value: isHidden},
+			}},
+		}).Err(); err != nil {
+			logger.Error().Err(err).Str("collection", index.Collection).Str("index", index.Name).Str("function", "SetIndexesHidden").Str("functionInline", "db.RunCommand").Msg("mongodb")
+			return err
+		}
+	}
+	return nil
+}
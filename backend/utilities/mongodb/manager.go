@@ -0,0 +1,241 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"doctor-manager-api/database/mongo/models"
+)
+
+var globalManager Manager
+
+// Manager keeps one client per registered database, or per cluster for the databases of a
+// cluster, so compare, sync and collection calls reuse connections instead of dialing the
+// target cluster every time.
+type Manager interface {
+	InitGlobal()
+	// Get returns the cached client of databaseId, connecting when there is none or
+	// the uri or its settings changed. release must be called once the client is no
+	// longer used.
+	Get(databaseId, uri string, opts ...ConnectOption) (client Service, release func(), err error)
+	GetByDatabase(database *models.Database) (client Service, release func(), err error)
+	Invalidate(databaseId string)
+	// LockIndexVisibility serializes the changes hiding indexes of databaseId for a while,
+	// so that one does not unhide the indexes another hid. unlock must be called once the
+	// indexes are restored.
+	LockIndexVisibility(databaseId string) (unlock func())
+	Start()
+	Close()
+}
+
+type ManagerOption struct {
+	IdleTimeout         time.Duration
+	HealthCheckInterval time.Duration
+	MaxPoolSize         uint64
+}
+
+type pooledClient struct {
+	lastUsedAt  time.Time
+	service     *service
+	fingerprint string
+	refs        int
+	isEvicted   bool
+}
+
+type manager struct {
+	ctx             context.Context
+	cancel          context.CancelFunc
+	clients         map[string]*pooledClient
+	visibilityLocks map[string]*sync.Mutex
+	option          ManagerOption
+	wg              sync.WaitGroup
+	mutex           sync.Mutex
+}
+
+func NewManager(opt ManagerOption) Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &manager{
+		ctx:             ctx,
+		cancel:          cancel,
+		clients:         make(map[string]*pooledClient),
+		visibilityLocks: make(map[string]*sync.Mutex),
+		option:          opt,
+	}
+}
+
+func GetManager() Manager {
+	return globalManager
+}
+
+func (m *manager) InitGlobal() {
+	globalManager = m
+}
+
+// GetByDatabase shares one client between the databases of a cluster, keyed by the cluster id.
+func (m *manager) GetByDatabase(database *models.Database) (Service, func(), error) {
+	opt, err := ConnectOptionFromDatabase(database)
+	if err != nil {
+		return nil, nil, err
+	}
+	if database.ClusterId != nil {
+		return m.Get(database.ClusterId.Hex(), database.Uri, opt)
+	}
+	return m.Get(database.Id.Hex(), database.Uri, opt)
+}
+
+func (m *manager) Get(databaseId, uri string, opts ...ConnectOption) (Service, func(), error) {
+	opt := ConnectOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	fingerprint := opt.fingerprint(uri)
+	m.mutex.Lock()
+	if pooled, ok := m.clients[databaseId]; ok && pooled.fingerprint == fingerprint {
+		pooled.refs++
+		pooled.lastUsedAt = time.Now()
+		m.mutex.Unlock()
+		return pooled.service, m.releaseFunc(pooled), nil
+	}
+	m.mutex.Unlock()
+
+	// Dial without holding the lock, a slow cluster must not block the others.
+	newService, err := connect(uri, m.option.MaxPoolSize, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mutex.Lock()
+	if pooled, ok := m.clients[databaseId]; ok {
+		if pooled.fingerprint == fingerprint {
+			pooled.refs++
+			pooled.lastUsedAt = time.Now()
+			m.mutex.Unlock()
+			disconnect(newService)
+			return pooled.service, m.releaseFunc(pooled), nil
+		}
+		m.evictLocked(databaseId, pooled)
+	}
+	pooled := &pooledClient{
+		lastUsedAt:  time.Now(),
+		service:     newService,
+		fingerprint: fingerprint,
+		refs:        1,
+	}
+	m.clients[databaseId] = pooled
+	m.mutex.Unlock()
+	return pooled.service, m.releaseFunc(pooled), nil
+}
+
+func (m *manager) releaseFunc(pooled *pooledClient) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mutex.Lock()
+			pooled.refs--
+			pooled.lastUsedAt = time.Now()
+			isClosable := pooled.isEvicted && pooled.refs == 0
+			m.mutex.Unlock()
+			if isClosable {
+				disconnect(pooled.service)
+			}
+		})
+	}
+}
+
+func (m *manager) LockIndexVisibility(databaseId string) func() {
+	m.mutex.Lock()
+	lock, ok := m.visibilityLocks[databaseId]
+	if !ok {
+		lock = new(sync.Mutex)
+		m.visibilityLocks[databaseId] = lock
+	}
+	m.mutex.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+func (m *manager) Invalidate(databaseId string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if pooled, ok := m.clients[databaseId]; ok {
+		m.evictLocked(databaseId, pooled)
+	}
+}
+
+// evictLocked drops the client from the cache. A client still in use is disconnected
+// by its last release.
+func (m *manager) evictLocked(databaseId string, pooled *pooledClient) {
+	delete(m.clients, databaseId)
+	pooled.isEvicted = true
+	if pooled.refs == 0 {
+		go disconnect(pooled.service)
+	}
+}
+
+func (m *manager) Start() {
+	if m.option.HealthCheckInterval <= 0 {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.option.HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				m.check()
+			}
+		}
+	}()
+	logger.Info().Dur("interval", m.option.HealthCheckInterval).Dur("idle_timeout", m.option.IdleTimeout).Msg("mongodb: connection manager started")
+}
+
+// check evicts idle clients and pings the others, evicting those that fail so the
+// next Get reconnects.
+func (m *manager) check() {
+	m.mutex.Lock()
+	pings := make(map[string]*pooledClient, len(m.clients))
+	for databaseId, pooled := range m.clients {
+		if m.option.IdleTimeout > 0 && pooled.refs == 0 && time.Since(pooled.lastUsedAt) > m.option.IdleTimeout {
+			m.evictLocked(databaseId, pooled)
+			continue
+		}
+		pings[databaseId] = pooled
+	}
+	m.mutex.Unlock()
+
+	for databaseId, pooled := range pings {
+		if err := pooled.service.Ping(); err != nil {
+			logger.Warn().Err(err).Str("database_id", databaseId).Msg("mongodb: evicting unhealthy client")
+			m.mutex.Lock()
+			if current, ok := m.clients[databaseId]; ok && current == pooled {
+				m.evictLocked(databaseId, pooled)
+			}
+			m.mutex.Unlock()
+		}
+	}
+}
+
+// Close stops the health checks and disconnects every client, in use or not. It runs
+// on shutdown, after the HTTP server and the job workers have stopped.
+func (m *manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+	m.mutex.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*pooledClient)
+	m.mutex.Unlock()
+	for _, pooled := range clients {
+		disconnect(pooled.service)
+	}
+}
+
+func disconnect(s *service) {
+	if err := s.Disconnect(); err != nil {
+		logger.Error().Err(err).Str("function", "disconnect").Str("functionInline", "s.Disconnect").Msg("mongodb")
+	}
+}
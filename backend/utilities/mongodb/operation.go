@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const indexBuildMsgPrefix = "Index Build: "
+
+// IndexBuild is an in-progress createIndexes on one host. Done and Total count the documents
+// of the current phase and are zero while the build waits, e.g. for a lock or commit quorum.
+type IndexBuild struct {
+	Collection  string
+	Host        string
+	Shard       string
+	Phase       string
+	Indexes     []string
+	Done        int64
+	Total       int64
+	SecsRunning int64
+}
+
+type currentOpIndexBuild struct {
+	Ns       string `bson:"ns"`
+	Host     string `bson:"host"`
+	Shard    string `bson:"shard"`
+	Msg      string `bson:"msg"`
+	Progress struct {
+		Done  int64 `bson:"done"`
+		Total int64 `bson:"total"`
+	} `bson:"progress"`
+	Command struct {
+		Indexes []struct {
+			Name string `bson:"name"`
+		} `bson:"indexes"`
+	} `bson:"command"`
+	SecsRunning int64 `bson:"secs_running"`
+}
+
+// GetIndexBuilds reads the createIndexes operations running on the collections from
+// $currentOp, which needs the inprog privilege. The client command and the build thread of a
+// host are merged into one build.
+func (s *service) GetIndexBuilds(dbName string, collections []string) ([]IndexBuild, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	namespaces := make(bson.A, len(collections))
+	for i, collection := range collections {
+		namespaces[i] = dbName + "." + collection
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$currentOp", Value: bson.D{{Key: "allUsers", Value: true}}}},
+		{{Key: "$match", Value: bson.D{
+			{Key: "ns", Value: bson.D{{Key: "$in", Value: namespaces}}},
+			{Key: "command.createIndexes", Value: bson.D{{Key: "$exists", Value: true}}},
+		}}},
+	}
+	cursor, err := s.client.Database("admin").Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetIndexBuilds").Str("functionInline", "db.Aggregate").Msg("mongodb")
+		return nil, err
+	}
+	var operations []currentOpIndexBuild
+	if err = cursor.All(ctx, &operations); err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetIndexBuilds").Str("functionInline", "cursor.All").Msg("mongodb")
+		return nil, err
+	}
+	builds := make([]IndexBuild, 0, len(operations))
+	mapBuild := make(map[string]int)
+	for _, operation := range operations {
+		indexes := make([]string, len(operation.Command.Indexes))
+		for i, index := range operation.Command.Indexes {
+			indexes[i] = index.Name
+		}
+		sort.Strings(indexes)
+		build := IndexBuild{
+			Collection:  strings.TrimPrefix(operation.Ns, dbName+"."),
+			Host:        operation.Host,
+			Shard:       operation.Shard,
+			Phase:       indexBuildPhase(operation.Msg),
+			Indexes:     indexes,
+			Done:        operation.Progress.Done,
+			Total:       operation.Progress.Total,
+			SecsRunning: operation.SecsRunning,
+		}
+		key := build.Shard + "/" + build.Host + "/" + operation.Ns + "/" + strings.Join(indexes, ",")
+		i, exists := mapBuild[key]
+		if !exists {
+			mapBuild[key] = len(builds)
+			builds = append(builds, build)
+			continue
+		}
+		if builds[i].Phase == "" {
+			builds[i].Phase = build.Phase
+		}
+		if build.Total > 0 {
+			builds[i].Done, builds[i].Total = build.Done, build.Total
+		}
+		builds[i].SecsRunning = max(builds[i].SecsRunning, build.SecsRunning)
+	}
+	for i := range builds {
+		if builds[i].Phase == "" {
+			builds[i].Phase = "waiting"
+		}
+	}
+	return builds, nil
+}
+
+// indexBuildPhase turns "Index Build: scanning collection Index Build: scanning collection:
+// 16448/100000 16%" into "scanning collection".
+func indexBuildPhase(msg string) string {
+	phase := strings.TrimPrefix(msg, indexBuildMsgPrefix)
+	phase, _, _ = strings.Cut(phase, " "+indexBuildMsgPrefix)
+	phase, _, _ = strings.Cut(phase, ":")
+	return strings.TrimSpace(phase)
+}
@@ -0,0 +1,59 @@
+package mongodb
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const profileCollection = "system.profile"
+
+type ProfileOption struct {
+	Since       time.Time
+	Collections []string
+	MinMillis   int64
+	Limit       int64
+}
+
+// GetProfileEntries reads the most recent reads, updates and deletes recorded by the
+// database profiler, which must be enabled on the target (db.setProfilingLevel). Entries
+// keep their field order so sort specifications stay meaningful.
+func (s *service) GetProfileEntries(dbName string, opt ProfileOption) ([]bson.D, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	filter := bson.M{
+		"op":     bson.M{"$in": bson.A{"query", "getmore", "command", "update", "remove"}},
+		"millis": bson.M{"$gte": opt.MinMillis},
+	}
+	if len(opt.Collections) > 0 {
+		namespaces := make(bson.A, len(opt.Collections))
+		for i, collection := range opt.Collections {
+			namespaces[i] = dbName + "." + collection
+		}
+		filter["ns"] = bson.M{"$in": namespaces}
+	} else {
+		filter["ns"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(dbName+".") + "(?!system\\.)"}
+	}
+	if !opt.Since.IsZero() {
+		filter["ts"] = bson.M{"$gte": opt.Since}
+	}
+	optFind := options.Find().SetSort(bson.D{{Key: "ts", Value: -1}})
+	if opt.Limit > 0 {
+		optFind.SetLimit(opt.Limit)
+	}
+	cursor, err := s.client.Database(dbName).Collection(profileCollection).Find(ctx, filter, optFind)
+	if err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetProfileEntries").Str("functionInline", "coll.Find").Msg("mongodb")
+		return nil, err
+	}
+	entries := make([]bson.D, 0)
+	if err = cursor.All(ctx, &entries); err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetProfileEntries").Str("functionInline", "cursor.All").Msg("mongodb")
+		return nil, err
+	}
+	return entries, nil
+}
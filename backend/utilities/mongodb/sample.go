@@ -0,0 +1,33 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SampleDocuments reads up to size random documents of the collection with $sample, which
+// the server serves without a collection scan as long as size is under 5% of the documents.
+func (s *service) SampleDocuments(dbName, collection string, size int64) ([]bson.Raw, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	pipeline := mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: size}}}},
+	}
+	cursor, err := s.client.Database(dbName).Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error().Err(err).Str("collection", collection).Str("function", "SampleDocuments").Str("functionInline", "coll.Aggregate").Msg("mongodb")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	documents := make([]bson.Raw, 0, size)
+	for cursor.Next(ctx) {
+		documents = append(documents, append(bson.Raw{}, cursor.Current...))
+	}
+	if err = cursor.Err(); err != nil {
+		logger.Error().Err(err).Str("collection", collection).Str("function", "SampleDocuments").Str("functionInline", "cursor.Err").Msg("mongodb")
+		return nil, err
+	}
+	return documents, nil
+}
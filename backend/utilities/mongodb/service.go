@@ -2,23 +2,15 @@ package mongodb
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-)
 
-func isTextIndex(keys []IndexKey) bool {
-	for _, key := range keys {
-		if key.Field == "_fts" || key.Field == "_ftsx" {
-			return true
-		}
-		if v, ok := key.Value.(string); ok && v == "text" {
-			return true
-		}
-	}
-	return false
-}
+	"doctor-manager-api/utilities/indexspec"
+)
 
 func (s *service) TestConnection(uri string) error {
 	opts := options.Client()
@@ -38,6 +30,26 @@ func (s *service) TestConnection(uri string) error {
 	return nil
 }
 
+func (s *service) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	return s.client.Ping(ctx, nil)
+}
+
+func (s *service) Disconnect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	s.memberMutex.Lock()
+	for host, client := range s.memberClients {
+		if err := client.Disconnect(ctx); err != nil {
+			logger.Error().Err(err).Str("host", host).Str("function", "Disconnect").Str("functionInline", "client.Disconnect").Msg("mongodb")
+		}
+	}
+	s.memberClients = nil
+	s.memberMutex.Unlock()
+	return s.client.Disconnect(ctx)
+}
+
 func (s *service) GetIndexesByDbNameAndCollections(dbName string, collections []string) ([]Index, error) {
 	if len(collections) == 0 {
 		return nil, nil
@@ -49,94 +61,17 @@ func (s *service) GetIndexesByDbNameAndCollections(dbName string, collections []
 		coll := s.client.Database(dbName).Collection(collName)
 		cursor, err := coll.Indexes().List(ctx)
 		if err != nil {
+			if isCommandNotSupportedOnView(err) {
+				continue
+			}
 			logger.Error().Err(err).Str("collection", collName).Str("function", "GetIndexesByDbNameAndCollections").Str("functionInline", "coll.Indexes.List").Msg("mongodb")
 			return nil, err
 		}
 		for cursor.Next(ctx) {
-			var indexDoc bson.M
-			if err = cursor.Decode(&indexDoc); err != nil {
-				logger.Error().Err(err).Str("collection", collName).Str("function", "GetIndexesByDbNameAndCollections").Str("functionInline", "cursor.Decode").Msg("mongodb")
-				return nil, err
-			}
-			keys, ok := indexDoc["key"].(bson.M)
+			index, ok := newIndexFromDocument(collName, cursor.Current)
 			if !ok {
 				continue
 			}
-			index := Index{
-				Keys: make([]IndexKey, 0, len(keys)),
-				Options: IndexOption{
-					ExpireAfterSeconds: nil,
-					IsUnique:           false,
-				},
-				Collection: collName,
-			}
-			isDefaultIndex := false
-			for k, v := range keys {
-				if k == "_id" {
-					isDefaultIndex = true
-					break
-				}
-				if k == "_fts" || k == "_ftsx" {
-					index.IsText = true
-				}
-				key := IndexKey{
-					Field: k,
-				}
-				if value, ok := v.(int32); ok {
-					key.Value = value
-				} else if value, ok := v.(string); ok {
-					key.Value = value
-				} else {
-					key.Value = v
-				}
-				index.Keys = append(index.Keys, key)
-			}
-			if isDefaultIndex {
-				continue
-			}
-			if isUnique, ok := indexDoc["unique"].(bool); ok {
-				index.Options.IsUnique = isUnique
-			}
-			if expires, ok := indexDoc["expireAfterSeconds"].(int32); ok {
-				index.Options.ExpireAfterSeconds = &expires
-			}
-			if collationDoc, ok := indexDoc["collation"].(bson.M); ok {
-				collation := &Collation{}
-				if locale, ok := collationDoc["locale"].(string); ok {
-					collation.Locale = locale
-				}
-				if strength, ok := collationDoc["strength"].(int32); ok {
-					strengthInt := int(strength)
-					collation.Strength = &strengthInt
-				} else if strength, ok := collationDoc["strength"].(int); ok {
-					collation.Strength = &strength
-				}
-				if caseLevel, ok := collationDoc["caseLevel"].(bool); ok {
-					collation.CaseLevel = &caseLevel
-				}
-				if caseFirst, ok := collationDoc["caseFirst"].(string); ok {
-					collation.CaseFirst = caseFirst
-				}
-				if numericOrdering, ok := collationDoc["numericOrdering"].(bool); ok {
-					collation.NumericOrdering = &numericOrdering
-				}
-				if collation.Locale != "" {
-					index.Options.Collation = collation
-				}
-			}
-			if defaultLanguage, ok := indexDoc["default_language"].(string); ok {
-				index.Options.DefaultLanguage = defaultLanguage
-			}
-			if weights, ok := indexDoc["weights"].(bson.M); ok {
-				index.Options.Weights = make(map[string]interface{})
-				for k, v := range weights {
-					index.Options.Weights[k] = v
-				}
-			}
-			if name, ok := indexDoc["name"].(string); ok {
-				index.Name = name
-			}
-			index.KeySignature = index.GetKeySignature()
 			indexes = append(indexes, index)
 		}
 		if err = cursor.Err(); err != nil {
@@ -180,6 +115,10 @@ func (s *service) RemoveIndexes(dbName string, indexes []Index) error {
 	return nil
 }
 
+// indexBuildTimeout bounds the build of the indexes of one collection, which scans every
+// document.
+const indexBuildTimeout = time.Hour
+
 func (s *service) CreateIndexes(dbName string, indexes []Index) error {
 	if len(indexes) == 0 {
 		return nil
@@ -194,13 +133,9 @@ func (s *service) CreateIndexes(dbName string, indexes []Index) error {
 		}
 		mapIndexByCollection[index.Collection] = append(mapIndexByCollection[index.Collection], index.toIndexModel())
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
-	defer cancel()
 	for _, collName := range collections {
 		if len(mapIndexByCollection[collName]) > 0 {
-			coll := s.client.Database(dbName).Collection(collName)
-			if _, err := coll.Indexes().CreateMany(ctx, mapIndexByCollection[collName]); err != nil {
-				logger.Error().Err(err).Str("collection", collName).Str("function", "CreateIndexes").Str("functionInline", " coll.Indexes().CreateMany").Msg("mongodb")
+			if err := s.createCollectionIndexes(dbName, collName, mapIndexByCollection[collName]); err != nil {
 				return err
 			}
 		}
@@ -208,17 +143,60 @@ func (s *service) CreateIndexes(dbName string, indexes []Index) error {
 	return nil
 }
 
+func (s *service) createCollectionIndexes(dbName, collName string, indexModels []mongo.IndexModel) error {
+	ctx, cancel := context.WithTimeout(context.Background(), indexBuildTimeout)
+	defer cancel()
+	coll := s.client.Database(dbName).Collection(collName)
+	if _, err := coll.Indexes().CreateMany(ctx, indexModels); err != nil {
+		logger.Error().Err(err).Str("collection", collName).Str("function", "CreateIndexes").Str("functionInline", " coll.Indexes().CreateMany").Msg("mongodb")
+		return err
+	}
+	return nil
+}
+
+// systemDatabases hold the state of the cluster itself and are never listed.
+var systemDatabases = []string{"admin", "config", "local"}
+
+// ListDatabases returns the databases of the cluster sorted by name. A user without the
+// listDatabases privilege only sees the databases it has privileges on.
+func (s *service) ListDatabases() ([]DatabaseInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	result, err := s.client.ListDatabases(ctx, bson.D{{Key: "name", Value: bson.D{{Key: "$nin", Value: systemDatabases}}}})
+	if err != nil {
+		logger.Error().Err(err).Str("function", "ListDatabases").Str("functionInline", "client.ListDatabases").Msg("mongodb")
+		return nil, err
+	}
+	databases := make([]DatabaseInfo, len(result.Databases))
+	for i, database := range result.Databases {
+		databases[i] = DatabaseInfo{
+			Name:       database.Name,
+			SizeOnDisk: database.SizeOnDisk,
+			IsEmpty:    database.Empty,
+		}
+	}
+	sort.Slice(databases, func(i, j int) bool {
+		return databases[i].Name < databases[j].Name
+	})
+	return databases, nil
+}
+
+// GetIndexesByDbName lists the indexes of every collection of the database, views and system
+// namespaces aside.
 func (s *service) GetIndexesByDbName(dbName string) ([]Index, error) {
 	var indexes []Index
 	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
 	defer cancel()
 	db := s.client.Database(dbName)
-	collections, err := db.ListCollectionNames(ctx, bson.M{})
+	infos, err := s.listCollectionInfos(ctx, dbName, nil)
 	if err != nil {
-		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetIndexesByDbName").Str("functionInline", "db.ListCollectionNames").Msg("mongodb")
 		return indexes, err
 	}
-	for _, collName := range collections {
+	for _, info := range infos {
+		if info.Type == CollectionTypeView {
+			continue
+		}
+		collName := info.Name
 		coll := db.Collection(collName)
 		cursor, err := coll.Indexes().List(ctx)
 		if err != nil {
@@ -226,88 +204,10 @@ func (s *service) GetIndexesByDbName(dbName string) ([]Index, error) {
 			return nil, err
 		}
 		for cursor.Next(ctx) {
-			var indexDoc bson.M
-			if err = cursor.Decode(&indexDoc); err != nil {
-				logger.Error().Err(err).Str("collection", collName).Str("function", "GetIndexesByDbName").Str("functionInline", "cursor.Decode").Msg("mongodb")
-				return nil, err
-			}
-			keys, ok := indexDoc["key"].(bson.M)
+			index, ok := newIndexFromDocument(collName, cursor.Current)
 			if !ok {
 				continue
 			}
-			index := Index{
-				Keys: make([]IndexKey, 0, len(keys)),
-				Options: IndexOption{
-					ExpireAfterSeconds: nil,
-					IsUnique:           false,
-				},
-				Collection: collName,
-			}
-			isDefaultIndex := false
-			for k, v := range keys {
-				if k == "_id" {
-					isDefaultIndex = true
-					break
-				}
-				key := IndexKey{
-					Field: k,
-				}
-				if value, ok := v.(int32); ok {
-					key.Value = value
-				} else if value, ok := v.(string); ok {
-					key.Value = value
-				} else {
-					key.Value = v
-				}
-				index.Keys = append(index.Keys, key)
-			}
-			if isDefaultIndex {
-				continue
-			}
-			if isUnique, ok := indexDoc["unique"].(bool); ok {
-				index.Options.IsUnique = isUnique
-			}
-			if expires, ok := indexDoc["expireAfterSeconds"].(int32); ok {
-				index.Options.ExpireAfterSeconds = &expires
-			}
-			if collationDoc, ok := indexDoc["collation"].(bson.M); ok {
-				collation := &Collation{}
-				if locale, ok := collationDoc["locale"].(string); ok {
-					collation.Locale = locale
-				}
-				if strength, ok := collationDoc["strength"].(int32); ok {
-					strengthInt := int(strength)
-					collation.Strength = &strengthInt
-				} else if strength, ok := collationDoc["strength"].(int); ok {
-					collation.Strength = &strength
-				}
-				if caseLevel, ok := collationDoc["caseLevel"].(bool); ok {
-					collation.CaseLevel = &caseLevel
-				}
-				if caseFirst, ok := collationDoc["caseFirst"].(string); ok {
-					collation.CaseFirst = caseFirst
-				}
-				if numericOrdering, ok := collationDoc["numericOrdering"].(bool); ok {
-					collation.NumericOrdering = &numericOrdering
-				}
-				if collation.Locale != "" {
-					index.Options.Collation = collation
-				}
-			}
-			if defaultLanguage, ok := indexDoc["default_language"].(string); ok {
-				index.Options.DefaultLanguage = defaultLanguage
-			}
-			if weights, ok := indexDoc["weights"].(bson.M); ok {
-				index.Options.Weights = make(map[string]interface{})
-				for k, v := range weights {
-					index.Options.Weights[k] = v
-				}
-			}
-			if name, ok := indexDoc["name"].(string); ok {
-				index.Name = name
-			}
-			index.IsText = isTextIndex(index.Keys)
-			index.KeySignature = index.GetKeySignature()
 			indexes = append(indexes, index)
 		}
 		if err = cursor.Err(); err != nil {
@@ -320,3 +220,64 @@ func (s *service) GetIndexesByDbName(dbName string) ([]Index, error) {
 	}
 	return indexes, nil
 }
+
+// indexDocument is a listIndexes document. Key and weights are read in order.
+type indexDocument struct {
+	ExpireAfterSeconds interface{} `bson:"expireAfterSeconds"`
+	Collation          *struct {
+		Strength        *int   `bson:"strength"`
+		CaseLevel       *bool  `bson:"caseLevel"`
+		NumericOrdering *bool  `bson:"numericOrdering"`
+		Backwards       *bool  `bson:"backwards"`
+		Locale          string `bson:"locale"`
+		CaseFirst       string `bson:"caseFirst"`
+		Alternate       string `bson:"alternate"`
+		MaxVariable     string `bson:"maxVariable"`
+	} `bson:"collation"`
+	Name            string `bson:"name"`
+	DefaultLanguage string `bson:"default_language"`
+	Key             bson.D `bson:"key"`
+	Weights         bson.D `bson:"weights"`
+	Unique          bool   `bson:"unique"`
+	Hidden          bool   `bson:"hidden"`
+}
+
+// newIndexFromDocument reads a listIndexes document, ok is false for the _id index and for a
+// document that is not an index.
+func newIndexFromDocument(collName string, document bson.Raw) (Index, bool) {
+	var indexDoc indexDocument
+	if err := bson.Unmarshal(document, &indexDoc); err != nil {
+		logger.Error().Err(err).Str("collection", collName).Str("function", "newIndexFromDocument").Str("functionInline", "bson.Unmarshal").Msg("mongodb")
+		return Index{}, false
+	}
+	if len(indexDoc.Key) == 0 || (len(indexDoc.Key) == 1 && indexDoc.Key[0].Key == "_id") {
+		return Index{}, false
+	}
+	index := Index{
+		Keys:       indexspec.KeysFromDocument(indexDoc.Key),
+		Collection: collName,
+		Name:       indexDoc.Name,
+		IsHidden:   indexDoc.Hidden,
+	}
+	index.Options.IsUnique = indexDoc.Unique
+	if expires, ok := indexspec.NormalizeValue(indexDoc.ExpireAfterSeconds).(int32); ok {
+		index.Options.ExpireAfterSeconds = &expires
+	}
+	if indexDoc.Collation != nil && indexDoc.Collation.Locale != "" {
+		index.Options.Collation = &Collation{
+			Strength:        indexDoc.Collation.Strength,
+			CaseLevel:       indexDoc.Collation.CaseLevel,
+			NumericOrdering: indexDoc.Collation.NumericOrdering,
+			Backwards:       indexDoc.Collation.Backwards,
+			Locale:          indexDoc.Collation.Locale,
+			CaseFirst:       indexDoc.Collation.CaseFirst,
+			Alternate:       indexDoc.Collation.Alternate,
+			MaxVariable:     indexDoc.Collation.MaxVariable,
+		}
+	}
+	index.Options.DefaultLanguage = indexDoc.DefaultLanguage
+	index.Options.Weights = indexspec.WeightsFromDocument(indexDoc.Weights)
+	index.IsText = indexspec.IsText(index.Keys)
+	index.KeySignature = index.GetKeySignature()
+	return index, true
+}
@@ -0,0 +1,211 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrNotSharded = errors.New("not connected through mongos")
+
+// ShardKey is the shard key of a sharded collection. IndexNames are the indexes that can
+// support it, whose keys start with the shard key, which a sync must never drop.
+type ShardKey struct {
+	Collection string
+	Keys       []IndexKey
+	IndexNames []string
+	IsUnique   bool
+}
+
+// ShardIndexes are the indexes of a collection as reported by each shard owning data of it.
+type ShardIndexes struct {
+	Collection string
+	Shard      string
+	Indexes    []Index
+}
+
+type configCollectionDocument struct {
+	Id     string `bson:"_id"`
+	Key    bson.D `bson:"key"`
+	Unique bool   `bson:"unique"`
+}
+
+type shardIndexStatDocument struct {
+	Shard string   `bson:"shard"`
+	Spec  bson.Raw `bson:"spec"`
+}
+
+func (s *service) isMongos(ctx context.Context) (bool, error) {
+	var hello helloDocument
+	if err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		logger.Error().Err(err).Str("function", "isMongos").Str("functionInline", "client.RunCommand").Msg("mongodb")
+		return false, err
+	}
+	return hello.Msg == "isdbgrid", nil
+}
+
+// GetShardKeys reads the shard keys of the sharded collections among collections, every
+// collection of the database when empty, from config.collections. Outside of mongos there is
+// no shard key and the result is empty.
+func (s *service) GetShardKeys(dbName string, collections []string) ([]ShardKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	isMongos, err := s.isMongos(ctx)
+	if err != nil || !isMongos {
+		return []ShardKey{}, err
+	}
+	filter := bson.M{"dropped": bson.M{"$ne": true}}
+	if len(collections) > 0 {
+		namespaces := make(bson.A, len(collections))
+		for i, collection := range collections {
+			namespaces[i] = dbName + "." + collection
+		}
+		filter["_id"] = bson.M{"$in": namespaces}
+	} else {
+		filter["_id"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(dbName+".")}
+	}
+	cursor, err := s.client.Database("config").Collection("collections").Find(ctx, filter)
+	if err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetShardKeys").Str("functionInline", "coll.Find").Msg("mongodb")
+		return nil, err
+	}
+	var documents []configCollectionDocument
+	if err = cursor.All(ctx, &documents); err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetShardKeys").Str("functionInline", "cursor.All").Msg("mongodb")
+		return nil, err
+	}
+	shardKeys := make([]ShardKey, 0, len(documents))
+	for _, document := range documents {
+		shardKey := ShardKey{
+			Collection: document.Id[len(dbName)+1:],
+			Keys:       make([]IndexKey, len(document.Key)),
+			IndexNames: make([]string, 0),
+			IsUnique:   document.Unique,
+		}
+		for i, element := range document.Key {
+			shardKey.Keys[i] = IndexKey{Field: element.Key, Value: element.Value}
+		}
+		// listIndexes is read again as bson.D, the shard key is an ordered prefix.
+		cursor, err = s.client.Database(dbName).Collection(shardKey.Collection).Indexes().List(ctx)
+		if err != nil {
+			logger.Error().Err(err).Str("collection", shardKey.Collection).Str("function", "GetShardKeys").Str("functionInline", "coll.Indexes.List").Msg("mongodb")
+			return nil, err
+		}
+		var indexDocs []struct {
+			Name string `bson:"name"`
+			Key  bson.D `bson:"key"`
+		}
+		if err = cursor.All(ctx, &indexDocs); err != nil {
+			logger.Error().Err(err).Str("collection", shardKey.Collection).Str("function", "GetShardKeys").Str("functionInline", "cursor.All").Msg("mongodb")
+			return nil, err
+		}
+		for _, indexDoc := range indexDocs {
+			if isShardKeyPrefix(document.Key, indexDoc.Key) {
+				shardKey.IndexNames = append(shardKey.IndexNames, indexDoc.Name)
+			}
+		}
+		shardKeys = append(shardKeys, shardKey)
+	}
+	sort.Slice(shardKeys, func(i, j int) bool {
+		return shardKeys[i].Collection < shardKeys[j].Collection
+	})
+	return shardKeys, nil
+}
+
+func isShardKeyPrefix(shardKey, key bson.D) bool {
+	if len(shardKey) > len(key) {
+		return false
+	}
+	for i, element := range shardKey {
+		if element.Key != key[i].Key {
+			return false
+		}
+		// 1, 1.0 and 1L all stand for an ascending field.
+		value, isNumber := toFloat64(element.Value)
+		keyValue, isKeyNumber := toFloat64(key[i].Value)
+		if isNumber && isKeyNumber {
+			if value != keyValue {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(element.Value, key[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// GetShardIndexes runs $indexStats through mongos, where each shard holding data of the
+// collection reports its own indexes, the _id index included.
+func (s *service) GetShardIndexes(dbName string, collections []string) ([]ShardIndexes, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	isMongos, err := s.isMongos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isMongos {
+		return nil, ErrNotSharded
+	}
+	if len(collections) == 0 {
+		collections, err = s.client.Database(dbName).ListCollectionNames(ctx, bson.M{"type": "collection"})
+		if err != nil {
+			logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetShardIndexes").Str("functionInline", "db.ListCollectionNames").Msg("mongodb")
+			return nil, err
+		}
+	}
+	results := make([]ShardIndexes, 0)
+	pipeline := mongo.Pipeline{{{Key: "$indexStats", Value: bson.D{}}}}
+	for _, collName := range collections {
+		cursor, err := s.client.Database(dbName).Collection(collName).Aggregate(ctx, pipeline)
+		if err != nil {
+			if isNamespaceNotFound(err) {
+				continue
+			}
+			logger.Error().Err(err).Str("collection", collName).Str("function", "GetShardIndexes").Str("functionInline", "coll.Aggregate").Msg("mongodb")
+			return nil, err
+		}
+		var documents []shardIndexStatDocument
+		if err = cursor.All(ctx, &documents); err != nil {
+			logger.Error().Err(err).Str("collection", collName).Str("function", "GetShardIndexes").Str("functionInline", "cursor.All").Msg("mongodb")
+			return nil, err
+		}
+		mapShard := make(map[string]int)
+		for _, document := range documents {
+			i, exists := mapShard[document.Shard]
+			if !exists {
+				i = len(results)
+				mapShard[document.Shard] = i
+				results = append(results, ShardIndexes{
+					Collection: collName,
+					Shard:      document.Shard,
+					Indexes:    make([]Index, 0),
+				})
+			}
+			if index, ok := newIndexFromDocument(collName, document.Spec); ok {
+				results[i].Indexes = append(results[i].Indexes, index)
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,338 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	namespaceNotFoundCode = 26
+	// memberStatsTimeout bounds $indexStats on one member, a member down for maintenance must
+	// not hold the others up to the overall timeout.
+	memberStatsTimeout = 5 * time.Second
+)
+
+// IndexStat is the usage of an index merged across the members that reported it. Ops is
+// the sum of their counters and Since the most recent start among them, so the index has
+// been counted on every member from Since on.
+type IndexStat struct {
+	Since      time.Time
+	Collection string
+	Name       string
+	Members    []IndexStatMember
+	Ops        int64
+}
+
+type IndexStatMember struct {
+	Since time.Time
+	Host  string
+	Ops   int64
+}
+
+// UnavailableMember is a member $indexStats could not run on, unreachable or recovering. Its
+// ops are missing from the stats.
+type UnavailableMember struct {
+	Host  string
+	Error string
+}
+
+type indexStatDocument struct {
+	Accesses struct {
+		Since time.Time `bson:"since"`
+		Ops   int64     `bson:"ops"`
+	} `bson:"accesses"`
+	Collection string `bson:"-"`
+	Name       string `bson:"name"`
+	Host       string `bson:"host"`
+}
+
+type helloDocument struct {
+	Msg      string   `bson:"msg"`
+	Hosts    []string `bson:"hosts"`
+	Passives []string `bson:"passives"`
+}
+
+// GetIndexStats runs $indexStats on the collections, every collection when empty. $indexStats
+// only reports the member that runs it, so on a replica set each data bearing member is
+// queried in parallel through a direct connection, and the members that fail are returned as
+// unavailable rather than failing the others. Through mongos every shard is already reported.
+func (s *service) GetIndexStats(dbName string, collections []string) ([]IndexStat, []UnavailableMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	if len(collections) == 0 {
+		var err error
+		collections, err = s.client.Database(dbName).ListCollectionNames(ctx, bson.M{"type": "collection"})
+		if err != nil {
+			logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetIndexStats").Str("functionInline", "db.ListCollectionNames").Msg("mongodb")
+			return nil, nil, err
+		}
+	}
+	var hello helloDocument
+	if err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		logger.Error().Err(err).Str("function", "GetIndexStats").Str("functionInline", "client.RunCommand").Msg("mongodb")
+		return nil, nil, err
+	}
+	members := append(hello.Hosts, hello.Passives...)
+	unavailable := make([]UnavailableMember, 0)
+	if hello.Msg == "isdbgrid" || len(members) == 0 {
+		documents, err := aggregateIndexStats(ctx, s.client.Database(dbName), collections)
+		if err != nil {
+			return nil, nil, err
+		}
+		return mergeIndexStats(documents), unavailable, nil
+	}
+	var (
+		documents []indexStatDocument
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+	)
+	for _, host := range members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			memberCtx, memberCancel := context.WithTimeout(ctx, memberStatsTimeout)
+			defer memberCancel()
+			memberDocuments, err := s.getMemberIndexStats(memberCtx, host, dbName, collections)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				logger.Warn().Err(err).Str("host", host).Str("function", "GetIndexStats").Str("functionInline", "s.getMemberIndexStats").Msg("mongodb")
+				unavailable = append(unavailable, UnavailableMember{Host: host, Error: err.Error()})
+				return
+			}
+			documents = append(documents, memberDocuments...)
+		}()
+	}
+	wg.Wait()
+	if len(unavailable) == len(members) {
+		return nil, nil, fmt.Errorf("no member available, %s: %s", unavailable[0].Host, unavailable[0].Error)
+	}
+	sort.Slice(unavailable, func(a, b int) bool {
+		return unavailable[a].Host < unavailable[b].Host
+	})
+	return mergeIndexStats(documents), unavailable, nil
+}
+
+func (s *service) getMemberIndexStats(ctx context.Context, host, dbName string, collections []string) ([]indexStatDocument, error) {
+	client, err := s.getMemberClient(host)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateIndexStats(ctx, client.Database(dbName), collections)
+}
+
+// getMemberClient returns the direct client of a member, connected on first use and kept for
+// the life of the service, so a pooled service reuses it and disconnects it with its own.
+func (s *service) getMemberClient(host string) (*mongo.Client, error) {
+	s.memberMutex.Lock()
+	defer s.memberMutex.Unlock()
+	if client, ok := s.memberClients[host]; ok {
+		return client, nil
+	}
+	opts := options.Client().ApplyURI(s.uri)
+	// Re-applying a plain uri drops the SRV scheme, which forbids direct connections, and
+	// keeps the other settings resolved from the original uri.
+	opts.ApplyURI("mongodb://" + host + "/?directConnection=true")
+	opts.SetSRVMaxHosts(0)
+	opts.SetServerSelectionTimeout(memberStatsTimeout)
+	opts.SetConnectTimeout(memberStatsTimeout)
+	opts.SetMaxPoolSize(1)
+	if err := s.connectOption.apply(opts); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), memberStatsTimeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		logger.Error().Err(err).Str("host", host).Str("function", "getMemberClient").Str("functionInline", "mongo.Connect").Msg("mongodb")
+		return nil, err
+	}
+	if s.memberClients == nil {
+		s.memberClients = make(map[string]*mongo.Client)
+	}
+	s.memberClients[host] = client
+	return client, nil
+}
+
+func aggregateIndexStats(ctx context.Context, db *mongo.Database, collections []string) ([]indexStatDocument, error) {
+	var documents []indexStatDocument
+	pipeline := mongo.Pipeline{{{Key: "$indexStats", Value: bson.D{}}}}
+	for _, collName := range collections {
+		cursor, err := db.Collection(collName).Aggregate(ctx, pipeline)
+		if err != nil {
+			if isCommandNotSupportedOnView(err) {
+				continue
+			}
+			logger.Error().Err(err).Str("collection", collName).Str("function", "aggregateIndexStats").Str("functionInline", "coll.Aggregate").Msg("mongodb")
+			return nil, err
+		}
+		var collDocuments []indexStatDocument
+		if err = cursor.All(ctx, &collDocuments); err != nil {
+			logger.Error().Err(err).Str("collection", collName).Str("function", "aggregateIndexStats").Str("functionInline", "cursor.All").Msg("mongodb")
+			return nil, err
+		}
+		for i := range collDocuments {
+			collDocuments[i].Collection = collName
+		}
+		documents = append(documents, collDocuments...)
+	}
+	return documents, nil
+}
+
+func mergeIndexStats(documents []indexStatDocument) []IndexStat {
+	stats := make([]IndexStat, 0)
+	mapStat := make(map[[2]string]int)
+	for _, document := range documents {
+		key := [2]string{document.Collection, document.Name}
+		i, exists := mapStat[key]
+		if !exists {
+			i = len(stats)
+			mapStat[key] = i
+			stats = append(stats, IndexStat{
+				Collection: document.Collection,
+				Name:       document.Name,
+				Members:    make([]IndexStatMember, 0, 1),
+			})
+		}
+		stats[i].Ops += document.Accesses.Ops
+		if document.Accesses.Since.After(stats[i].Since) {
+			stats[i].Since = document.Accesses.Since
+		}
+		stats[i].Members = append(stats[i].Members, IndexStatMember{
+			Since: document.Accesses.Since,
+			Host:  document.Host,
+			Ops:   document.Accesses.Ops,
+		})
+	}
+	for i := range stats {
+		sort.Slice(stats[i].Members, func(a, b int) bool {
+			return stats[i].Members[a].Host < stats[i].Members[b].Host
+		})
+	}
+	return stats
+}
+
+// CollectionStat holds the storage statistics of a collection in bytes, summed across
+// shards. IndexSizes is keyed by index name.
+type CollectionStat struct {
+	IndexSizes     map[string]int64
+	Collection     string
+	Count          int64
+	Size           int64
+	StorageSize    int64
+	TotalIndexSize int64
+}
+
+type collStatsDocument struct {
+	StorageStats struct {
+		IndexSizes     map[string]int64 `bson:"indexSizes"`
+		Count          int64            `bson:"count"`
+		Size           int64            `bson:"size"`
+		StorageSize    int64            `bson:"storageSize"`
+		TotalIndexSize int64            `bson:"totalIndexSize"`
+	} `bson:"storageStats"`
+}
+
+// GetCollectionStats runs $collStats on the collections. Collections that do not exist on
+// the cluster and views are left out of the result.
+func (s *service) GetCollectionStats(dbName string, collections []string) ([]CollectionStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	stats := make([]CollectionStat, 0, len(collections))
+	pipeline := mongo.Pipeline{{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}}}
+	for _, collName := range collections {
+		cursor, err := s.client.Database(dbName).Collection(collName).Aggregate(ctx, pipeline)
+		if err != nil {
+			if isNamespaceNotFound(err) || isCommandNotSupportedOnView(err) {
+				continue
+			}
+			logger.Error().Err(err).Str("collection", collName).Str("function", "GetCollectionStats").Str("functionInline", "coll.Aggregate").Msg("mongodb")
+			return nil, err
+		}
+		var documents []collStatsDocument
+		if err = cursor.All(ctx, &documents); err != nil {
+			if isNamespaceNotFound(err) || isCommandNotSupportedOnView(err) {
+				continue
+			}
+			logger.Error().Err(err).Str("collection", collName).Str("function", "GetCollectionStats").Str("functionInline", "cursor.All").Msg("mongodb")
+			return nil, err
+		}
+		if len(documents) == 0 {
+			continue
+		}
+		stat := CollectionStat{
+			IndexSizes: make(map[string]int64),
+			Collection: collName,
+		}
+		for _, document := range documents {
+			stat.Count += document.StorageStats.Count
+			stat.Size += document.StorageStats.Size
+			stat.StorageSize += document.StorageStats.StorageSize
+			stat.TotalIndexSize += document.StorageStats.TotalIndexSize
+			for name, size := range document.StorageStats.IndexSizes {
+				stat.IndexSizes[name] += size
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func isNamespaceNotFound(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(namespaceNotFoundCode)
+}
+
+// StorageStat tells how much of the WiredTiger cache of the server the indexes of a database
+// compete for, in bytes. CacheMaxBytes is 0 when unknown, such as through mongos, which has no
+// storage engine.
+type StorageStat struct {
+	CacheMaxBytes  int64
+	CacheUsedBytes int64
+	IndexSize      int64
+}
+
+type serverStatusDocument struct {
+	WiredTiger *struct {
+		Cache struct {
+			MaximumBytes float64 `bson:"maximum bytes configured"`
+			CurrentBytes float64 `bson:"bytes currently in the cache"`
+		} `bson:"cache"`
+	} `bson:"wiredTiger"`
+}
+
+type dbStatsDocument struct {
+	IndexSize float64 `bson:"indexSize"`
+}
+
+// GetStorageStat reads the cache of the server from serverStatus and the size of the indexes
+// of the database from dbStats. Without the serverStatus privilege only IndexSize is set.
+func (s *service) GetStorageStat(dbName string) (StorageStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultContextTimeout)
+	defer cancel()
+	var stat StorageStat
+	var dbStats dbStatsDocument
+	if err := s.client.Database(dbName).RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&dbStats); err != nil {
+		logger.Error().Err(err).Str("dbName", dbName).Str("function", "GetStorageStat").Str("functionInline", "db.RunCommand(dbStats)").Msg("mongodb")
+		return stat, err
+	}
+	stat.IndexSize = int64(dbStats.IndexSize)
+	var serverStatus serverStatusDocument
+	if err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&serverStatus); err != nil {
+		logger.Warn().Err(err).Str("function", "GetStorageStat").Str("functionInline", "client.RunCommand(serverStatus)").Msg("mongodb")
+		return stat, nil
+	}
+	if serverStatus.WiredTiger != nil {
+		stat.CacheMaxBytes = int64(serverStatus.WiredTiger.Cache.MaximumBytes)
+		stat.CacheUsedBytes = int64(serverStatus.WiredTiger.Cache.CurrentBytes)
+	}
+	return stat, nil
+}
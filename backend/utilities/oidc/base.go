@@ -0,0 +1,106 @@
+package oidc
+
+import (
+	"crypto"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"doctor-manager-api/common/constants"
+	"doctor-manager-api/common/logging"
+)
+
+const (
+	defaultHttpTimeout    = 10 * time.Second
+	discoveryPath         = "/.well-known/openid-configuration"
+	keysRefreshMinimumGap = time.Minute
+)
+
+var logger = logging.GetLogger()
+
+var global Service
+
+type Service interface {
+	InitGlobal()
+	AuthCodeURL(state, nonce, codeVerifier string) (authUrl string, err error)
+	Exchange(code, codeVerifier string) (token *Token, err error)
+	VerifyIdToken(rawIdToken, nonce string) (claims *Claims, err error)
+}
+
+type Option struct {
+	HttpClient   *http.Client
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	GroupsClaim  string
+	Scopes       []string
+}
+
+// provider holds the subset of the discovery document this package relies on.
+type provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type service struct {
+	keysFetchedAt time.Time
+	httpClient    *http.Client
+	provider      *provider
+	keys          map[string]crypto.PublicKey
+	option        Option
+	mutex         sync.Mutex
+}
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IdToken     string `json:"id_token"`
+}
+
+// Claims are the identity claims of a verified ID token.
+type Claims struct {
+	Subject           string
+	Issuer            string
+	Email             string
+	PreferredUsername string
+	GivenName         string
+	FamilyName        string
+	Picture           string
+	Groups            []string
+	IsEmailVerified   bool
+}
+
+// New does not contact the provider, the discovery document is fetched on first use
+// so the API can start while the identity provider is unreachable.
+func New(opt Option) Service {
+	if opt.HttpClient == nil {
+		opt.HttpClient = &http.Client{Timeout: defaultHttpTimeout}
+	}
+	return &service{
+		httpClient: opt.HttpClient,
+		keys:       make(map[string]crypto.PublicKey),
+		option:     opt,
+	}
+}
+
+func GetGlobal() Service {
+	return global
+}
+
+// Role maps the groups of an identity to an account role, admin when one of them is among
+// adminGroups. It is empty without adminGroups, roles being then managed in the API only.
+func Role(groups, adminGroups []string) string {
+	if len(adminGroups) == 0 {
+		return ""
+	}
+	if slices.ContainsFunc(groups, func(group string) bool {
+		return slices.Contains(adminGroups, group)
+	}) {
+		return constants.AccountRoleAdmin
+	}
+	return constants.AccountRoleMember
+}
@@ -0,0 +1,255 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	idTokenLeeway        = time.Minute
+	errorBodyReadLimit   = 1024
+	responseBodyMaxBytes = 1 << 20
+)
+
+var idTokenSigningMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+func (s *service) InitGlobal() {
+	global = s
+}
+
+// AuthCodeURL builds the authorization request, with PKCE derived from codeVerifier.
+func (s *service) AuthCodeURL(state, nonce, codeVerifier string) (string, error) {
+	p, err := s.getProvider()
+	if err != nil {
+		return "", err
+	}
+	authUrl, err := url.Parse(p.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidc: invalid authorization endpoint: %w", err)
+	}
+	scopes := s.option.Scopes
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	query := authUrl.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", s.option.ClientId)
+	query.Set("redirect_uri", s.option.RedirectUrl)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authUrl.RawQuery = query.Encode()
+	return authUrl.String(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint.
+func (s *service) Exchange(code, codeVerifier string) (*Token, error) {
+	p, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.option.RedirectUrl)
+	form.Set("client_id", s.option.ClientId)
+	form.Set("code_verifier", codeVerifier)
+	req, err := http.NewRequest(http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.option.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.option.ClientId), url.QueryEscape(s.option.ClientSecret))
+	}
+	token := new(Token)
+	if err = s.doJSON(req, token); err != nil {
+		return nil, err
+	}
+	if token.IdToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+	return token, nil
+}
+
+// VerifyIdToken checks the signature against the provider keys, then the issuer,
+// audience, expiry and nonce of an ID token.
+func (s *service) VerifyIdToken(rawIdToken, nonce string) (*Claims, error) {
+	p, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+	mapClaims := jwt.MapClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(idTokenSigningMethods),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(s.option.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+	)
+	if _, err = parser.ParseWithClaims(rawIdToken, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.getKey(kid)
+	}); err != nil {
+		return nil, fmt.Errorf("oidc: id token invalid: %w", err)
+	}
+	if claimString(mapClaims, "nonce") != nonce {
+		return nil, errors.New("oidc: id token nonce mismatch")
+	}
+	if audiences, _ := mapClaims.GetAudience(); len(audiences) > 1 {
+		if azp := claimString(mapClaims, "azp"); azp != "" && azp != s.option.ClientId {
+			return nil, errors.New("oidc: id token authorized party mismatch")
+		}
+	}
+	claims := &Claims{
+		Subject:           claimString(mapClaims, "sub"),
+		Issuer:            claimString(mapClaims, "iss"),
+		Email:             claimString(mapClaims, "email"),
+		PreferredUsername: claimString(mapClaims, "preferred_username"),
+		GivenName:         claimString(mapClaims, "given_name"),
+		FamilyName:        claimString(mapClaims, "family_name"),
+		Picture:           claimString(mapClaims, "picture"),
+		IsEmailVerified:   claimBool(mapClaims, "email_verified"),
+	}
+	if s.option.GroupsClaim != "" {
+		claims.Groups = claimStrings(mapClaims, s.option.GroupsClaim)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("oidc: id token has no subject")
+	}
+	return claims, nil
+}
+
+func (s *service) getProvider() (*provider, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.provider != nil {
+		return s.provider, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(s.option.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	p := new(provider)
+	if err = s.doJSON(req, p); err != nil {
+		logger.Error().Err(err).Str("function", "getProvider").Str("functionInline", "s.doJSON").Msg("oidc")
+		return nil, err
+	}
+	if p.Issuer != s.option.Issuer {
+		return nil, fmt.Errorf("oidc: issuer mismatch, configured %q but provider announces %q", s.option.Issuer, p.Issuer)
+	}
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JwksUri == "" {
+		return nil, errors.New("oidc: discovery document is missing an endpoint")
+	}
+	s.provider = p
+	return p, nil
+}
+
+// getKey refreshes the key set when kid is unknown, at most once per
+// keysRefreshMinimumGap so forged key ids cannot hammer the provider.
+func (s *service) getKey(kid string) (crypto.PublicKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if key, ok := s.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(s.keysFetchedAt) < keysRefreshMinimumGap {
+		return nil, errors.New("oidc: signing key unknown")
+	}
+	req, err := http.NewRequest(http.MethodGet, s.provider.JwksUri, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set jwkSet
+	if err = s.doJSON(req, &set); err != nil {
+		logger.Error().Err(err).Str("function", "getKey").Str("functionInline", "s.doJSON").Msg("oidc")
+		return nil, err
+	}
+	s.keys = set.publicKeys()
+	s.keysFetchedAt = time.Now()
+	if key, ok := s.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.New("oidc: signing key unknown")
+}
+
+// lookupKey accepts a token without kid only when the provider publishes a single key.
+func (s *service) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *service) doJSON(req *http.Request, result interface{}) error {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyReadLimit))
+		return fmt.Errorf("oidc: %s %s answered %d: %s", req.Method, req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, responseBodyMaxBytes)).Decode(result); err != nil {
+		return fmt.Errorf("oidc: decode %s: %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimBool also accepts "true", which some providers send for email_verified.
+func claimBool(claims jwt.MapClaims, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(value, "true")
+	}
+	return false
+}
+
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+	return nil
+}
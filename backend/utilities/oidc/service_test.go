@@ -0,0 +1,273 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"doctor-manager-api/common/constants"
+)
+
+const (
+	testClientId     = "manager"
+	testClientSecret = "secret"
+	testRedirectUrl  = "https://manager.example.com/v1/auth/oidc/callback"
+	testKid          = "key-1"
+	testCode         = "code-1"
+	testNonce        = "nonce-1"
+)
+
+// mockProvider is a local OIDC provider serving discovery, the key set and the token
+// endpoint. The token endpoint only redeems testCode, with the verifier of the challenge the
+// authorization request carried.
+type mockProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	idToken   string
+	mutex     sync.Mutex
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, provider{
+			Issuer:                m.server.URL,
+			AuthorizationEndpoint: m.server.URL + "/authorize",
+			TokenEndpoint:         m.server.URL + "/token",
+			JwksUri:               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, jwkSet{Keys: []jwk{{
+			Kty: "RSA",
+			Kid: testKid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	clientId, clientSecret, _ := r.BasicAuth()
+	if r.Method != http.MethodPost || clientId != testClientId || clientSecret != testClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != testCode ||
+		r.PostForm.Get("redirect_uri") != testRedirectUrl ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != m.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, Token{AccessToken: "access", TokenType: "Bearer", IdToken: m.idToken})
+}
+
+// authorize plays the authorization endpoint, recording the PKCE challenge of the request.
+func (m *mockProvider) authorize(t *testing.T, authUrl string) {
+	t.Helper()
+	parsed, err := url.Parse(authUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.challenge = query.Get("code_challenge")
+}
+
+func (m *mockProvider) claims() jwt.MapClaims {
+	currentTime := time.Now()
+	return jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            testClientId,
+		"sub":            "subject-1",
+		"nonce":          testNonce,
+		"email":          "jane@example.com",
+		"email_verified": "true",
+		"groups":         []string{"dba", "dev"},
+		"iat":            currentTime.Unix(),
+		"exp":            currentTime.Add(time.Hour).Unix(),
+	}
+}
+
+func (m *mockProvider) sign(t *testing.T, claims jwt.MapClaims, key *rsa.PrivateKey) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (m *mockProvider) service() Service {
+	return New(Option{
+		Issuer:       m.server.URL,
+		ClientId:     testClientId,
+		ClientSecret: testClientSecret,
+		RedirectUrl:  testRedirectUrl,
+		GroupsClaim:  "groups",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func TestExchange(t *testing.T) {
+	m := newMockProvider(t)
+	m.idToken = m.sign(t, m.claims(), m.key)
+	s := m.service()
+	const verifier = "verifier-with-enough-entropy-0123456789"
+	authUrl, err := s.AuthCodeURL("state-1", testNonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authUrl, m.server.URL+"/authorize?") {
+		t.Fatalf("AuthCodeURL() = %q, want the authorization endpoint", authUrl)
+	}
+	m.authorize(t, authUrl)
+
+	if _, err = s.Exchange(testCode, "another-verifier"); err == nil {
+		t.Fatal("Exchange() with the wrong code verifier succeeded")
+	}
+	token, err := s.Exchange(testCode, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := s.VerifyIdToken(token.IdToken, testNonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "subject-1" || claims.Email != "jane@example.com" || !claims.IsEmailVerified {
+		t.Errorf("VerifyIdToken() = %+v, want the identity of the token", claims)
+	}
+	if strings.Join(claims.Groups, ",") != "dba,dev" {
+		t.Errorf("Groups = %v, want [dba dev]", claims.Groups)
+	}
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	m := newMockProvider(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		modify func(claims jwt.MapClaims)
+		key    *rsa.PrivateKey
+		nonce  string
+		isErr  bool
+	}{
+		{name: "valid"},
+		{name: "signed with another key", key: otherKey, isErr: true},
+		{name: "other issuer", modify: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }, isErr: true},
+		{name: "other audience", modify: func(claims jwt.MapClaims) { claims["aud"] = "another-client" }, isErr: true},
+		{name: "nonce mismatch", nonce: "nonce-2", isErr: true},
+		{
+			name:   "expired",
+			modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-2 * idTokenLeeway).Unix() },
+			isErr:  true,
+		},
+		{name: "no expiry", modify: func(claims jwt.MapClaims) { delete(claims, "exp") }, isErr: true},
+		{
+			name:   "expired within the leeway",
+			modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-idTokenLeeway / 2).Unix() },
+		},
+		{
+			name: "several audiences, authorized party of another client",
+			modify: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{testClientId, "another-client"}
+				claims["azp"] = "another-client"
+			},
+			isErr: true,
+		},
+	}
+	s := m.service()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := m.claims()
+			if test.modify != nil {
+				test.modify(claims)
+			}
+			key := m.key
+			if test.key != nil {
+				key = test.key
+			}
+			nonce := testNonce
+			if test.nonce != "" {
+				nonce = test.nonce
+			}
+			_, err := s.VerifyIdToken(m.sign(t, claims, key), nonce)
+			if (err != nil) != test.isErr {
+				t.Errorf("VerifyIdToken() error = %v, want error %v", err, test.isErr)
+			}
+		})
+	}
+}
+
+func TestIssuerMismatch(t *testing.T) {
+	m := newMockProvider(t)
+	s := New(Option{Issuer: m.server.URL + "/", ClientId: testClientId, RedirectUrl: testRedirectUrl})
+	if _, err := s.AuthCodeURL("state-1", testNonce, "verifier"); err == nil {
+		t.Fatal("AuthCodeURL() succeeded with a provider announcing another issuer")
+	}
+}
+
+func TestRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		groups      []string
+		adminGroups []string
+		want        string
+	}{
+		{name: "no admin groups", groups: []string{"dba"}, want: ""},
+		{name: "admin group", groups: []string{"dev", "dba"}, adminGroups: []string{"dba"}, want: constants.AccountRoleAdmin},
+		{name: "other groups", groups: []string{"dev"}, adminGroups: []string{"dba"}, want: constants.AccountRoleMember},
+		{name: "no groups", adminGroups: []string{"dba"}, want: constants.AccountRoleMember},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Role(test.groups, test.adminGroups); got != test.want {
+				t.Errorf("Role(%v, %v) = %q, want %q", test.groups, test.adminGroups, got, test.want)
+			}
+		})
+	}
+}
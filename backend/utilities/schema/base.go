@@ -0,0 +1,57 @@
+// Package schema infers the field paths of a collection from sampled documents and checks
+// index keys against them. A MongoDB index on a missing field builds fine and stays empty, so a
+// misspelled field is only noticed through the sample.
+package schema
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// RuleUnknownField reports a key no sampled document holds.
+	RuleUnknownField = "unknown-field"
+	// RuleNestedArray reports a key under an array of arrays, whose inner elements a dotted
+	// path never reaches.
+	RuleNestedArray = "nested-array"
+)
+
+// Sampler reads up to size random documents of a collection, as mongodb.Service does.
+type Sampler interface {
+	SampleDocuments(dbName, collection string, size int64) (documents []bson.Raw, err error)
+}
+
+// TypeCount is the number of values of a BSON type, named as $type does.
+type TypeCount struct {
+	Type  string
+	Count int64
+}
+
+// Field is a path found in the sample. Elements of an array of documents share the path of
+// the array, as multikey indexes see them.
+type Field struct {
+	// Types is sorted by count, the most frequent first.
+	Types []TypeCount
+	Path  string
+	// Count is the number of documents holding the path at least once.
+	Count        int64
+	PresenceRate float64
+	IsArray      bool
+	// HasNestedArray tells that an array of the path directly holds arrays.
+	HasNestedArray bool
+}
+
+// Schema is the inferred shape of a collection. Fields are sorted by path.
+type Schema struct {
+	Fields     []Field
+	Collection string
+	SampleSize int64
+}
+
+// Warning is a key of an index the sampled documents cannot serve. Suggestion is the closest
+// known path when the field looks misspelled.
+type Warning struct {
+	Rule       string
+	Field      string
+	Suggestion string
+	Message    string
+}
@@ -0,0 +1,257 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+
+	"doctor-manager-api/utilities/indexspec"
+)
+
+// maxSuggestionDistance is the edit distance under which a known path is suggested for an
+// unknown field.
+const maxSuggestionDistance = 2
+
+var typeNames = map[bsontype.Type]string{
+	bsontype.Double:           "double",
+	bsontype.String:           "string",
+	bsontype.EmbeddedDocument: "object",
+	bsontype.Array:            "array",
+	bsontype.Binary:           "binData",
+	bsontype.Undefined:        "undefined",
+	bsontype.ObjectID:         "objectId",
+	bsontype.Boolean:          "bool",
+	bsontype.DateTime:         "date",
+	bsontype.Null:             "null",
+	bsontype.Regex:            "regex",
+	bsontype.DBPointer:        "dbPointer",
+	bsontype.JavaScript:       "javascript",
+	bsontype.Symbol:           "symbol",
+	bsontype.CodeWithScope:    "javascriptWithScope",
+	bsontype.Int32:            "int",
+	bsontype.Timestamp:        "timestamp",
+	bsontype.Int64:            "long",
+	bsontype.Decimal128:       "decimal",
+	bsontype.MinKey:           "minKey",
+	bsontype.MaxKey:           "maxKey",
+}
+
+type fieldState struct {
+	field Field
+	types map[string]int64
+}
+
+type builder struct {
+	fields map[string]*fieldState
+	seen   map[string]struct{}
+}
+
+// Sample reads size random documents of the collection and infers its schema.
+func Sample(sampler Sampler, dbName, collection string, size int64) (Schema, error) {
+	documents, err := sampler.SampleDocuments(dbName, collection, size)
+	if err != nil {
+		return Schema{Collection: collection}, err
+	}
+	return Infer(collection, documents), nil
+}
+
+// Infer builds the schema of a collection from its sampled documents.
+func Infer(collection string, documents []bson.Raw) Schema {
+	b := &builder{fields: make(map[string]*fieldState)}
+	for _, document := range documents {
+		b.seen = make(map[string]struct{})
+		b.walkDocument("", document)
+	}
+	schema := Schema{
+		Fields:     make([]Field, 0, len(b.fields)),
+		Collection: collection,
+		SampleSize: int64(len(documents)),
+	}
+	for _, state := range b.fields {
+		field := state.field
+		field.Types = make([]TypeCount, 0, len(state.types))
+		for name, count := range state.types {
+			field.Types = append(field.Types, TypeCount{Type: name, Count: count})
+		}
+		sort.Slice(field.Types, func(i, j int) bool {
+			if field.Types[i].Count != field.Types[j].Count {
+				return field.Types[i].Count > field.Types[j].Count
+			}
+			return field.Types[i].Type < field.Types[j].Type
+		})
+		field.PresenceRate = float64(field.Count) / float64(schema.SampleSize)
+		schema.Fields = append(schema.Fields, field)
+	}
+	sort.Slice(schema.Fields, func(i, j int) bool {
+		return schema.Fields[i].Path < schema.Fields[j].Path
+	})
+	return schema
+}
+
+func (b *builder) walkDocument(prefix string, document bson.Raw) {
+	elements, err := document.Elements()
+	if err != nil {
+		return
+	}
+	for _, element := range elements {
+		path := element.Key()
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		b.walkValue(path, element.Value())
+	}
+}
+
+func (b *builder) walkValue(path string, value bson.RawValue) {
+	state, exists := b.fields[path]
+	if !exists {
+		state = &fieldState{field: Field{Path: path}, types: make(map[string]int64)}
+		b.fields[path] = state
+	}
+	if _, exists = b.seen[path]; !exists {
+		state.field.Count++
+		b.seen[path] = struct{}{}
+	}
+	state.types[typeName(value.Type)]++
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		b.walkDocument(path, value.Document())
+	case bsontype.Array:
+		state.field.IsArray = true
+		values, err := value.Array().Values()
+		if err != nil {
+			return
+		}
+		for _, element := range values {
+			switch element.Type {
+			case bsontype.EmbeddedDocument:
+				b.walkDocument(path, element.Document())
+			case bsontype.Array:
+				state.field.HasNestedArray = true
+			}
+		}
+	}
+}
+
+func typeName(t bsontype.Type) string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return t.String()
+}
+
+// Field returns the field of the path. Positional components such as the 0 of "items.0.sku"
+// are skipped after an array, as the sample records elements under the path of the array.
+func (s Schema) Field(path string) (Field, bool) {
+	fields := s.fieldsByPath()
+	field, ok := fields[s.resolve(path, fields)]
+	return field, ok
+}
+
+func (s Schema) fieldsByPath() map[string]Field {
+	fields := make(map[string]Field, len(s.Fields))
+	for _, field := range s.Fields {
+		fields[field.Path] = field
+	}
+	return fields
+}
+
+func (s Schema) resolve(path string, fields map[string]Field) string {
+	resolved := ""
+	for _, component := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(component); err == nil && fields[resolved].IsArray {
+			continue
+		}
+		if resolved != "" {
+			resolved += "."
+		}
+		resolved += component
+	}
+	return resolved
+}
+
+// CheckKeys reports the keys the sampled documents never hold and those under an array of
+// arrays. _id, the _fts and _ftsx keys of a listed text index and wildcard keys are not
+// checked, nor is anything when the sample is empty.
+func (s Schema) CheckKeys(keys []indexspec.Key) []Warning {
+	warnings := make([]Warning, 0)
+	if s.SampleSize == 0 {
+		return warnings
+	}
+	fields := s.fieldsByPath()
+	for _, key := range keys {
+		if key.Field == "_id" || key.Field == "_fts" || key.Field == "_ftsx" ||
+			key.Field == "$**" || strings.HasSuffix(key.Field, ".$**") {
+			continue
+		}
+		path := s.resolve(key.Field, fields)
+		if nestedArray := nestedArrayPrefix(path, fields); nestedArray != "" {
+			warnings = append(warnings, Warning{
+				Rule:    RuleNestedArray,
+				Field:   key.Field,
+				Message: fmt.Sprintf("field %q is under %q, which holds arrays of arrays whose elements are not indexed", key.Field, nestedArray),
+			})
+			continue
+		}
+		if _, exists := fields[path]; exists {
+			continue
+		}
+		warning := Warning{
+			Rule:    RuleUnknownField,
+			Field:   key.Field,
+			Message: fmt.Sprintf("field %q is in none of the %d sampled documents", key.Field, s.SampleSize),
+		}
+		if suggestion := s.suggest(path); suggestion != "" {
+			warning.Suggestion = suggestion
+			warning.Message += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// nestedArrayPrefix returns the first parent path of the path holding arrays of arrays.
+func nestedArrayPrefix(path string, fields map[string]Field) string {
+	for i := range len(path) {
+		if path[i] == '.' && fields[path[:i]].HasNestedArray {
+			return path[:i]
+		}
+	}
+	return ""
+}
+
+// suggest returns the known path closest to the path, empty when none is close enough.
+func (s Schema) suggest(path string) string {
+	suggestion, best := "", maxSuggestionDistance+1
+	for _, field := range s.Fields {
+		if distance := editDistance(path, field.Path); distance < best {
+			suggestion, best = field.Path, distance
+		}
+	}
+	return suggestion
+}
+
+// editDistance is the Levenshtein distance between both strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}